@@ -0,0 +1,309 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unicode/utf8"
+
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/drive"
+	"github.com/tmc/nlm/internal/split"
+	"github.com/tmc/nlm/internal/youtube"
+)
+
+// AddOptions contains the options for add.
+type AddOptions struct {
+	Convert  string
+	Split    string
+	MaxWords int
+	MaxMB    int
+	Limit    int
+}
+
+// limits returns the per-source limits the options set.
+func (o *AddOptions) limits() split.Limits {
+	return split.Limits{MaxWords: o.MaxWords, MaxBytes: int64(o.MaxMB) << 20}
+}
+
+// parseAddFlags parses
+// `add <notebook-id> <input> [--convert auto|none] [--split auto|off]
+// [--max-words N] [--max-mb N] [--limit N]`.
+func parseAddFlags(args []string) (*AddOptions, []string, error) {
+	opts := &AddOptions{}
+	fs := newCommandFlags("add")
+	fs.StringVar(&opts.Convert, "convert", "auto", "convert HTML, DOCX, EPUB, notebooks and code to Markdown before adding: auto or none")
+	fs.StringVar(&opts.Split, "split", "off", "split input over the per-source limits into several sources: auto or off")
+	fs.IntVar(&opts.MaxWords, "max-words", split.DefaultLimits.MaxWords, "most words in one source")
+	fs.IntVar(&opts.MaxMB, "max-mb", int(split.DefaultLimits.MaxBytes>>20), "largest source in megabytes")
+	fs.IntVar(&opts.Limit, "limit", defaultSourceLimit, "the notebook's source limit")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Convert != "auto" && opts.Convert != "none" {
+		return nil, nil, fmt.Errorf("--convert must be auto or none, not %q", opts.Convert)
+	}
+	if opts.Split != "auto" && opts.Split != "off" {
+		return nil, nil, fmt.Errorf("--split must be auto or off, not %q", opts.Split)
+	}
+	if opts.MaxWords < 1 || opts.MaxMB < 1 || opts.Limit < 1 {
+		return nil, nil, fmt.Errorf("--max-words, --max-mb and --limit must be positive")
+	}
+	return opts, pos, nil
+}
+
+func validateAddArgs(args []string) error {
+	_, pos, err := parseAddFlags(args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm add <notebook-id> <file> [--convert none] [--split auto] [--max-words N] [--max-mb N] [--limit N]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// localInput is an input to add whose content nlm reads itself: a file,
+// a directory of source code, stdin or text given on the command line.
+type localInput struct {
+	Title string
+	Path  string // empty unless the input is a file or directory
+	Dir   bool
+	Typed bool   // text given on the command line
+	Data  []byte // nil for a directory until it is converted
+
+	// Converted is set once Data holds Markdown made from the input.
+	Converted bool
+}
+
+// readLocalInput reads input if it is local. It returns nil for URLs and
+// Drive files, which NotebookLM fetches itself.
+func readLocalInput(input string, stdin io.Reader) (*localInput, error) {
+	switch {
+	case input == "":
+		return nil, nil
+	case input == "-":
+		fmt.Fprintln(os.Stderr, "Reading from stdin...")
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("read stdin: %w", err)
+		}
+		return &localInput{Title: "Pasted Text", Data: data}, nil
+	}
+	if fi, err := os.Stat(input); err == nil && fi.IsDir() {
+		return &localInput{Title: filepath.Base(filepath.Clean(input)), Path: input, Dir: true}, nil
+	} else if err == nil {
+		//nolint:gosec // user-provided file path
+		data, err := os.ReadFile(input)
+		if err != nil {
+			return nil, fmt.Errorf("read file: %w", err)
+		}
+		return &localInput{Title: filepath.Base(input), Path: input, Data: data}, nil
+	}
+	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
+		return nil, nil
+	}
+	if _, ok := drive.ParseRef(input); ok {
+		return nil, nil
+	}
+	return &localInput{Title: "Text Source", Typed: true, Data: []byte(input)}, nil
+}
+
+// convert replaces the input's content with Markdown if a converter
+// handles it. A directory becomes one document of its source files. Text
+// typed on the command line is never converted.
+func (in *localInput) convert() error {
+	switch {
+	case in.Dir:
+		md, err := api.ConvertTree(in.Path)
+		if err != nil {
+			return fmt.Errorf("convert %s: %w", in.Path, err)
+		}
+		in.Data, in.Dir, in.Converted = []byte(md), false, true
+	case !in.Typed:
+		name := in.Path
+		if name == "" {
+			name = "stdin"
+		}
+		md, ok, err := api.DefaultConverters.Convert(name, in.Data)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		in.Data, in.Converted = []byte(md), true
+	default:
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Converted %s to Markdown (%d words).\n", in.Title, split.Words(string(in.Data)))
+	return nil
+}
+
+// describe says how the input compares to l, or returns "" if it fits.
+func (in *localInput) describe(l split.Limits) string {
+	size := int64(len(in.Data))
+	if split.IsPDF(in.Data) || !utf8.Valid(in.Data) {
+		if size <= l.MaxBytes {
+			return ""
+		}
+		return fmt.Sprintf("%d MB (limit %d MB)", size>>20, l.MaxBytes>>20)
+	}
+	if l.FitsText(string(in.Data)) {
+		return ""
+	}
+	return l.Describe(string(in.Data))
+}
+
+// addInput implements add. Local input over the per-source limits is
+// rejected, or with --split auto added as several sources.
+func addInput(c *api.Client, args []string) error {
+	opts, pos, err := parseAddFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID, input := pos[0], pos[1]
+	if youtube.IsCollection(input) {
+		return addYouTubeCollection(c, notebookID, input)
+	}
+
+	in, err := readLocalInput(input, os.Stdin)
+	if err != nil {
+		return err
+	}
+	if in != nil && opts.Convert == "auto" {
+		if err := in.convert(); err != nil {
+			return err
+		}
+	}
+	if in != nil && in.Dir {
+		return fmt.Errorf("%s is a directory; add it with --convert auto", input)
+	}
+	var stdin io.Reader = os.Stdin
+	if in != nil {
+		stdin = bytes.NewReader(in.Data)
+		if over := in.describe(opts.limits()); over != "" {
+			if opts.Split != "auto" {
+				return fmt.Errorf("%s is %s, over the per-source limit; use --split auto to add it as several sources", in.Title, over)
+			}
+			fmt.Fprintf(os.Stderr, "%s is %s; splitting it.\n", in.Title, over)
+			return addSplit(c, notebookID, in, opts)
+		}
+	}
+	var id string
+	if in != nil && in.Converted {
+		id, err = c.AddSourceFromText(notebookID, string(in.Data), in.Title)
+	} else {
+		id, err = addSource(c, notebookID, input, stdin)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println(id)
+	return nil
+}
+
+// addSplit adds an oversized input as "Title (part i/N)" sources.
+func addSplit(c *api.Client, notebookID string, in *localInput, opts *AddOptions) error {
+	if split.IsPDF(in.Data) {
+		return addSplitPDF(c, notebookID, in, opts)
+	}
+	if !utf8.Valid(in.Data) {
+		return fmt.Errorf("split %s: %w: only text and PDF files can be split", in.Title, split.ErrCannotSplit)
+	}
+	parts, err := split.Text(string(in.Data), opts.limits())
+	if err != nil {
+		return fmt.Errorf("split %s: %w", in.Title, err)
+	}
+	if err := checkSourceRoom(c, notebookID, len(parts), opts.Limit); err != nil {
+		return err
+	}
+	titles := split.Titles(in.Title, len(parts))
+	for i, part := range parts {
+		fmt.Fprintf(os.Stderr, "Adding %s...\n", titles[i])
+		id, err := c.AddSourceFromText(notebookID, part, titles[i])
+		if err != nil {
+			return fmt.Errorf("add %s (added %d of %d): %w", titles[i], i, len(parts), err)
+		}
+		fmt.Println(id)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Added %s as %d sources\n", in.Title, len(parts))
+	return nil
+}
+
+// addSplitPDF adds an oversized PDF as sources of consecutive page ranges.
+func addSplitPDF(c *api.Client, notebookID string, in *localInput, opts *AddOptions) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	dir, err := os.MkdirTemp("", "nlm-split-")
+	if err != nil {
+		return fmt.Errorf("split %s: %w", in.Title, err)
+	}
+	defer os.RemoveAll(dir)
+	path := in.Path
+	if path == "" {
+		path = filepath.Join(dir, "input.pdf")
+		if err := os.WriteFile(path, in.Data, 0o600); err != nil {
+			return fmt.Errorf("split %s: %w", in.Title, err)
+		}
+	}
+
+	pages, err := split.PDFPages(ctx, path)
+	if err != nil {
+		return fmt.Errorf("split %s: %w", in.Title, err)
+	}
+	ranges, err := split.PageRanges(pages, int64(len(in.Data)), opts.limits().MaxBytes)
+	if err != nil {
+		return fmt.Errorf("split %s: %w", in.Title, err)
+	}
+	if err := checkSourceRoom(c, notebookID, len(ranges), opts.Limit); err != nil {
+		return err
+	}
+	files, err := split.PDF(ctx, path, dir, ranges)
+	if err != nil {
+		return err
+	}
+	titles := split.Titles(in.Title, len(files))
+	for i, file := range files {
+		fmt.Fprintf(os.Stderr, "Adding %s (pages %s)...\n", titles[i], ranges[i])
+		id, err := addPDFPart(c, notebookID, file, titles[i])
+		if err != nil {
+			return fmt.Errorf("add %s (added %d of %d): %w", titles[i], i, len(files), err)
+		}
+		fmt.Println(id)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Added %s as %d sources\n", in.Title, len(files))
+	return nil
+}
+
+func addPDFPart(c *api.Client, notebookID, path, title string) (string, error) {
+	//nolint:gosec // a part written by split.PDF
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return c.AddSourceFromReader(notebookID, f, title, "application/pdf")
+}
+
+// checkSourceRoom fails if adding n sources would take the notebook past
+// its source limit.
+func checkSourceRoom(c *api.Client, notebookID string, n, limit int) error {
+	p, err := c.GetProject(notebookID)
+	if err != nil {
+		return fmt.Errorf("get notebook: %w", err)
+	}
+	if have := len(p.GetSources()); have+n > limit {
+		return fmt.Errorf("notebook has %d of %d sources; adding %d parts would exceed it", have, limit, n)
+	}
+	return nil
+}
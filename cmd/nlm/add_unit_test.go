@@ -0,0 +1,124 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/nlm/internal/split"
+)
+
+func TestParseAddFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    AddOptions
+		wantErr bool
+	}{
+		{[]string{"nb", "file.txt"}, AddOptions{Convert: "auto", Split: "off", MaxWords: 500_000, MaxMB: 200, Limit: defaultSourceLimit}, false},
+		{[]string{"nb", "--split", "auto", "book.txt", "--max-words", "1000", "--limit", "300"}, AddOptions{Convert: "auto", Split: "auto", MaxWords: 1000, MaxMB: 200, Limit: 300}, false},
+		{[]string{"nb", "-", "--max-mb", "10"}, AddOptions{Convert: "auto", Split: "off", MaxWords: 500_000, MaxMB: 10, Limit: defaultSourceLimit}, false},
+		{[]string{"nb", "f", "--convert", "none"}, AddOptions{Convert: "none", Split: "off", MaxWords: 500_000, MaxMB: 200, Limit: defaultSourceLimit}, false},
+		{[]string{"nb", "f", "--convert", "pdf"}, AddOptions{}, true},
+		{[]string{"nb", "f", "--split", "pages"}, AddOptions{}, true},
+		{[]string{"nb", "f", "--max-words", "0"}, AddOptions{}, true},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseAddFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAddFlags(%v) error = %v", tt.args, err)
+			continue
+		}
+		if err == nil && *opts != tt.want {
+			t.Errorf("parseAddFlags(%v) = %+v, want %+v", tt.args, *opts, tt.want)
+		}
+	}
+}
+
+func TestReadLocalInput(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "notes.md")
+	if err := os.WriteFile(path, []byte("# Notes\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		input string
+		title string // "" for inputs NotebookLM fetches itself
+	}{
+		{path, "notes.md"},
+		{"-", "Pasted Text"},
+		{"some words to add", "Text Source"},
+		{"https://go.dev/doc", ""},
+		{"https://docs.google.com/document/d/1AbCdEfGhIjKlMnOpQrStUvWxYz012/edit", ""},
+		{"slides:1AbCdEfGhIjKlMnOpQrStUvWxYz012", ""},
+		{"123e4567-e89b-12d3-a456-426614174000", "Text Source"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		in, err := readLocalInput(tt.input, strings.NewReader("from stdin"))
+		if err != nil {
+			t.Errorf("readLocalInput(%q): %v", tt.input, err)
+			continue
+		}
+		if got := ""; in != nil {
+			got = in.Title
+			if got != tt.title {
+				t.Errorf("readLocalInput(%q) title = %q, want %q", tt.input, got, tt.title)
+			}
+		} else if tt.title != "" {
+			t.Errorf("readLocalInput(%q) = nil, want %q", tt.input, tt.title)
+		}
+	}
+}
+
+func TestLocalInputConvert(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(page, []byte("<html><title>T</title><body><p>Hello, world.</p></body></html>"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	in, err := readLocalInput(page, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := in.convert(); err != nil || !in.Converted || !strings.Contains(string(in.Data), "# T") {
+		t.Errorf("convert(page.html) = %q, converted %v, %v", in.Data, in.Converted, err)
+	}
+
+	in, err = readLocalInput(dir, nil)
+	if err != nil || !in.Dir {
+		t.Fatalf("readLocalInput(dir) = %+v, %v", in, err)
+	}
+	if err := in.convert(); err != nil || in.Dir || !strings.Contains(string(in.Data), "```go\npackage main\n```") {
+		t.Errorf("convert(dir) = %q, %v", in.Data, err)
+	}
+
+	in = &localInput{Title: "Text Source", Typed: true, Data: []byte("<p>typed</p>")}
+	if err := in.convert(); err != nil || in.Converted {
+		t.Errorf("convert(typed text) converted it: %q, %v", in.Data, err)
+	}
+}
+
+func TestLocalInputDescribe(t *testing.T) {
+	l := split.Limits{MaxWords: 3, MaxBytes: 1 << 20}
+	tests := []struct {
+		data []byte
+		want string
+	}{
+		{[]byte("one two three"), ""},
+		{[]byte("one two three four"), "4 words (limit 3)"},
+		// Word counts are not checked for PDFs, only their size.
+		{[]byte("%PDF-1.7 one two three four"), ""},
+		{append([]byte("%PDF-1.7"), make([]byte, 2<<20)...), "2 MB (limit 1 MB)"},
+	}
+	for _, tt := range tests {
+		in := &localInput{Title: "t", Data: tt.data}
+		if got := in.describe(l); got != tt.want {
+			t.Errorf("describe(%.20q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+// ArtifactExportOptions contains the options for artifact-export.
+type ArtifactExportOptions struct {
+	Format string
+	Output string
+}
+
+// parseArtifactExportFlags parses `artifact-export <artifact-id> [--format md|html|json] [-o file]`.
+// Without --format the format follows the output file extension.
+func parseArtifactExportFlags(args []string) (*ArtifactExportOptions, []string, error) {
+	opts := &ArtifactExportOptions{}
+	fs := newCommandFlags("artifact-export")
+	fs.StringVar(&opts.Format, "format", "", "output format: md, html or json")
+	fs.StringVar(&opts.Output, "o", "", "write to this file instead of stdout")
+	fs.StringVar(&opts.Output, "output", "", "write to this file instead of stdout")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Format == "" {
+		opts.Format = formatForPath(opts.Output)
+	}
+	switch opts.Format = strings.ToLower(opts.Format); opts.Format {
+	case "md", "markdown":
+		opts.Format = "md"
+	case "html", "json":
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q (want md, html or json)", opts.Format)
+	}
+	return opts, pos, nil
+}
+
+// formatForPath guesses the export format from a file name.
+func formatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html"
+	case ".json":
+		return "json"
+	}
+	return "md"
+}
+
+func validateArtifactExportArgs(args []string) error {
+	_, pos, err := parseArtifactExportFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm artifact-export <artifact-id> [--format md|html|json] [-o file]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// ArtifactDocument is the text of a report or note artifact, with the
+// artifact's source fragments resolved to numbered citations.
+type ArtifactDocument struct {
+	ID        string            `json:"id"`
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Content   string            `json:"content"`
+	Sections  []DocumentSection `json:"sections,omitempty"`
+	Citations []Citation        `json:"citations,omitempty"`
+}
+
+// DocumentSection is one section of a tailored report.
+type DocumentSection struct {
+	Title   string `json:"title"`
+	Content string `json:"content"`
+}
+
+// Citation is one source cited by an artifact. Fragments are the passages
+// the artifact attributes to that source.
+type Citation struct {
+	Number      int        `json:"number"`
+	SourceID    string     `json:"source_id"`
+	SourceTitle string     `json:"source_title"`
+	Fragments   []Fragment `json:"fragments,omitempty"`
+}
+
+// Fragment is a cited passage. Start and End are rune offsets into the
+// document content, or into the content of report section Section when it
+// is not zero; End is -1 when the passage could not be located.
+type Fragment struct {
+	Text    string `json:"text"`
+	Section int    `json:"section,omitempty"` // 1-based
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+}
+
+// newArtifactDocument extracts the exportable content of a. sourceTitles
+// maps source IDs to titles; sources missing from it are cited by ID.
+func newArtifactDocument(a *pb.Artifact, sourceTitles map[string]string) (*ArtifactDocument, error) {
+	doc := &ArtifactDocument{
+		ID:   a.GetArtifactId(),
+		Type: strings.TrimPrefix(a.GetType().String(), "ARTIFACT_TYPE_"),
+	}
+	switch {
+	case a.GetTailoredReport() != nil:
+		r := a.GetTailoredReport()
+		doc.Title, doc.Content = r.GetTitle(), r.GetContent()
+		for _, s := range r.GetSections() {
+			doc.Sections = append(doc.Sections, DocumentSection{Title: s.GetTitle(), Content: s.GetContent()})
+		}
+	case a.GetNote() != nil:
+		doc.Title, doc.Content = a.GetNote().GetTitle(), a.GetNote().Content
+	default:
+		return nil, fmt.Errorf("%s artifacts have no text to export", strings.ToLower(doc.Type))
+	}
+	if doc.Title == "" {
+		doc.Title = untitledTitle
+	}
+
+	content := []rune(doc.Content)
+	for _, src := range a.GetSources() {
+		id := src.GetSourceId().GetSourceId()
+		c := Citation{Number: len(doc.Citations) + 1, SourceID: id, SourceTitle: sourceTitles[id]}
+		if c.SourceTitle == "" {
+			c.SourceTitle = id
+		}
+		for _, tf := range src.GetTextFragments() {
+			f := Fragment{Text: tf.GetText()}
+			f.Start, f.End = locateFragment(content, tf)
+			// The server's offsets are into the main content; a passage
+			// not found there may be in one of the sections.
+			for i := 0; f.End < 0 && i < len(doc.Sections); i++ {
+				if start, end := findText([]rune(doc.Sections[i].Content), f.Text); end >= 0 {
+					f.Section, f.Start, f.End = i+1, start, end
+				}
+			}
+			c.Fragments = append(c.Fragments, f)
+		}
+		doc.Citations = append(doc.Citations, c)
+	}
+	return doc, nil
+}
+
+// locateFragment returns the rune range of tf in content. The offsets
+// reported by the server are used when they are in range and agree with
+// the fragment text; otherwise the text is searched for. It returns -1, -1
+// if the fragment cannot be placed.
+func locateFragment(content []rune, tf *pb.TextFragment) (int, int) {
+	start, end := int(tf.GetStartOffset()), int(tf.GetEndOffset())
+	text := tf.GetText()
+	if start >= 0 && end > start && end <= len(content) {
+		if text == "" || string(content[start:end]) == text {
+			return start, end
+		}
+	}
+	return findText(content, text)
+}
+
+// findText returns the rune range of the first occurrence of text in
+// content, or -1, -1 if there is none.
+func findText(content []rune, text string) (int, int) {
+	if text == "" {
+		return -1, -1
+	}
+	s := string(content)
+	i := strings.Index(s, text)
+	if i < 0 {
+		return -1, -1
+	}
+	start := len([]rune(s[:i]))
+	return start, start + len([]rune(text))
+}
+
+// markedContent returns the document content, or the content of the
+// 1-based section if it is not zero, with marker(n) inserted at the end of
+// every fragment of citation n located there.
+func (d *ArtifactDocument) markedContent(section int, marker func(n int) string) string {
+	type mark struct{ pos, n int }
+	var marks []mark
+	for _, c := range d.Citations {
+		seen := map[int]bool{}
+		for _, f := range c.Fragments {
+			if f.Section == section && f.End >= 0 && !seen[f.End] {
+				seen[f.End] = true
+				marks = append(marks, mark{f.End, c.Number})
+			}
+		}
+	}
+	sort.SliceStable(marks, func(i, j int) bool {
+		if marks[i].pos != marks[j].pos {
+			return marks[i].pos < marks[j].pos
+		}
+		return marks[i].n < marks[j].n
+	})
+
+	content := []rune(d.Content)
+	if section > 0 {
+		content = []rune(d.Sections[section-1].Content)
+	}
+	var b strings.Builder
+	last := 0
+	for _, m := range marks {
+		b.WriteString(string(content[last:m.pos]))
+		b.WriteString(marker(m.n))
+		last = m.pos
+	}
+	b.WriteString(string(content[last:]))
+	return b.String()
+}
+
+// renderMarkdown writes d as Markdown with footnote-style citations.
+func renderMarkdown(w io.Writer, d *ArtifactDocument) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", d.Title)
+	footnote := func(n int) string { return fmt.Sprintf("[^%d]", n) }
+	if body := strings.TrimSpace(d.markedContent(0, footnote)); body != "" {
+		b.WriteString(body + "\n\n")
+	}
+	for i, s := range d.Sections {
+		fmt.Fprintf(&b, "## %s\n\n", s.Title)
+		if body := strings.TrimSpace(d.markedContent(i+1, footnote)); body != "" {
+			b.WriteString(body + "\n\n")
+		}
+	}
+	for _, c := range d.Citations {
+		fmt.Fprintf(&b, "[^%d]: %s", c.Number, c.SourceTitle)
+		for _, f := range c.Fragments {
+			if f.Text != "" {
+				fmt.Fprintf(&b, " — “%s”", collapseSpace(f.Text))
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, strings.TrimRight(b.String(), "\n")+"\n")
+	return err
+}
+
+// citeToken stands in for a citation marker while the content is converted
+// to HTML, so the marker survives escaping.
+const citeToken = "\x00cite:%d\x00"
+
+// renderHTML writes d as a standalone HTML document styled for printing,
+// so it can be saved as PDF from a browser.
+func renderHTML(w io.Writer, d *ArtifactDocument) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", html.EscapeString(d.Title))
+	b.WriteString(exportCSS)
+	b.WriteString("</head>\n<body>\n<article>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", html.EscapeString(d.Title))
+
+	// body renders a part of the document with its citation markers.
+	body := func(section int) string {
+		h := markdownToHTML(d.markedContent(section, func(n int) string { return fmt.Sprintf(citeToken, n) }))
+		for _, c := range d.Citations {
+			h = strings.ReplaceAll(h, fmt.Sprintf(citeToken, c.Number),
+				fmt.Sprintf(`<sup class="cite"><a href="#cite-%d">[%d]</a></sup>`, c.Number, c.Number))
+		}
+		return h
+	}
+	b.WriteString(body(0))
+	for i, s := range d.Sections {
+		fmt.Fprintf(&b, "<section>\n<h2>%s</h2>\n%s</section>\n", html.EscapeString(s.Title), body(i+1))
+	}
+
+	if len(d.Citations) > 0 {
+		b.WriteString("<footer>\n<h2>Sources</h2>\n<ol>\n")
+		for _, c := range d.Citations {
+			fmt.Fprintf(&b, "<li id=\"cite-%d\">%s", c.Number, html.EscapeString(c.SourceTitle))
+			for _, f := range c.Fragments {
+				if f.Text != "" {
+					fmt.Fprintf(&b, "<blockquote>%s</blockquote>", html.EscapeString(collapseSpace(f.Text)))
+				}
+			}
+			b.WriteString("</li>\n")
+		}
+		b.WriteString("</ol>\n</footer>\n")
+	}
+	b.WriteString("</article>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const exportCSS = `<style>
+body { font: 16px/1.6 Georgia, serif; max-width: 46em; margin: 2em auto; padding: 0 1em; color: #222; }
+h1, h2, h3 { font-family: Helvetica, Arial, sans-serif; line-height: 1.25; }
+pre, code { font-family: Menlo, Consolas, monospace; font-size: 0.9em; }
+pre { background: #f5f5f5; padding: 0.75em; overflow-x: auto; }
+sup.cite a { text-decoration: none; }
+footer { margin-top: 3em; border-top: 1px solid #ccc; font-size: 0.9em; }
+blockquote { margin: 0.25em 0 0.5em 1em; color: #555; font-style: italic; }
+@media print {
+  body { margin: 0; max-width: none; }
+  a { color: inherit; }
+  h1, h2, h3 { page-break-after: avoid; }
+  pre, blockquote, li { page-break-inside: avoid; }
+}
+</style>
+`
+
+// renderJSON writes d as indented JSON.
+func renderJSON(w io.Writer, d *ArtifactDocument) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// exportArtifact implements artifact-export.
+func exportArtifact(c *api.Client, args []string) error {
+	opts, pos, err := parseArtifactExportFlags(args)
+	if err != nil {
+		return err
+	}
+	artifactID := pos[0]
+
+	artifact, err := c.GetArtifact(artifactID)
+	if err != nil {
+		return fmt.Errorf("export artifact: %w", err)
+	}
+	return writeArtifactExport(c, artifact, opts)
+}
+
+// writeArtifactExport renders artifact as described by opts, resolving
+// cited source IDs to titles.
+func writeArtifactExport(c *api.Client, artifact *pb.Artifact, opts *ArtifactExportOptions) error {
+	titles := map[string]string{}
+	if len(artifact.GetSources()) > 0 && artifact.GetProjectId() != "" {
+		if p, err := c.GetProject(artifact.GetProjectId()); err != nil {
+			fmt.Fprintf(os.Stderr, "nlm: could not load source titles, citing by ID: %v\n", err)
+		} else {
+			for _, src := range p.GetSources() {
+				titles[src.GetSourceId().GetSourceId()] = strings.TrimSpace(src.GetTitle())
+			}
+		}
+	}
+	doc, err := newArtifactDocument(artifact, titles)
+	if err != nil {
+		return fmt.Errorf("export artifact: %w", err)
+	}
+
+	render := renderMarkdown
+	switch opts.Format {
+	case "html":
+		render = renderHTML
+	case "json":
+		render = renderJSON
+	}
+	if opts.Output == "" {
+		return render(os.Stdout, doc)
+	}
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("export artifact: %w", err)
+	}
+	if err := render(f, doc); err != nil {
+		f.Close()
+		return fmt.Errorf("export artifact: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("export artifact: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Exported %s to %s\n", doc.Title, opts.Output)
+	return nil
+}
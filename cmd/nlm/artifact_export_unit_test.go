@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+func testReportArtifact() *pb.Artifact {
+	return &pb.Artifact{
+		ArtifactId: "art1",
+		Type:       pb.ArtifactType_ARTIFACT_TYPE_REPORT,
+		TailoredReport: &pb.Report{
+			Title:   "Café <Briefing>",
+			Content: "Revenue grew **12%** in Q3. Costs fell.",
+			Sections: []*pb.Section{
+				{Title: "Outlook", Content: "- steady\n- cautious"},
+			},
+		},
+		Sources: []*pb.ArtifactSource{
+			{
+				SourceId: &pb.SourceId{SourceId: "s1"},
+				// Offsets are in runes and match the text.
+				TextFragments: []*pb.TextFragment{{Text: "Revenue grew **12%** in Q3.", StartOffset: 0, EndOffset: 27}},
+			},
+			{
+				SourceId: &pb.SourceId{SourceId: "s2"},
+				// Bad offsets fall back to a text search, in the sections too.
+				TextFragments: []*pb.TextFragment{{Text: "Costs fell.", StartOffset: 500, EndOffset: 510}, {Text: "cautious"}},
+			},
+			{
+				SourceId:      &pb.SourceId{SourceId: "s3"},
+				TextFragments: []*pb.TextFragment{{Text: "not in the report"}},
+			},
+		},
+	}
+}
+
+func TestNewArtifactDocument(t *testing.T) {
+	doc, err := newArtifactDocument(testReportArtifact(), map[string]string{"s1": "Annual Report"})
+	if err != nil {
+		t.Fatalf("newArtifactDocument() error: %v", err)
+	}
+	if doc.Title != "Café <Briefing>" || doc.Type != "REPORT" || len(doc.Sections) != 1 {
+		t.Errorf("doc = %+v", doc)
+	}
+	if len(doc.Citations) != 3 {
+		t.Fatalf("got %d citations, want 3", len(doc.Citations))
+	}
+	if c := doc.Citations[0]; c.SourceTitle != "Annual Report" || c.Fragments[0].End != 27 {
+		t.Errorf("citation 1 = %+v", c)
+	}
+	if c := doc.Citations[1]; c.SourceTitle != "s2" || c.Fragments[0].Start != 28 || c.Fragments[0].End != 39 {
+		t.Errorf("citation 2 = %+v", c)
+	}
+	if f := doc.Citations[1].Fragments[1]; f.Section != 1 || f.Start != 11 || f.End != 19 {
+		t.Errorf("section fragment = %+v, want section 1, 11-19", f)
+	}
+	if f := doc.Citations[2].Fragments[0]; f.End != -1 {
+		t.Errorf("unlocated fragment = %+v, want End -1", f)
+	}
+
+	marker := func(n int) string { return "{" + string(rune('0'+n)) + "}" }
+	if got, want := doc.markedContent(0, marker), "Revenue grew **12%** in Q3.{1} Costs fell.{2}"; got != want {
+		t.Errorf("markedContent(0) = %q, want %q", got, want)
+	}
+	if got, want := doc.markedContent(1, marker), "- steady\n- cautious{2}"; got != want {
+		t.Errorf("markedContent(1) = %q, want %q", got, want)
+	}
+}
+
+func TestNewArtifactDocumentNote(t *testing.T) {
+	doc, err := newArtifactDocument(&pb.Artifact{
+		Type: pb.ArtifactType_ARTIFACT_TYPE_NOTE,
+		Note: &pb.Source{Title: "Todo", Content: "- one"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("newArtifactDocument() error: %v", err)
+	}
+	if doc.Title != "Todo" || doc.Content != "- one" {
+		t.Errorf("doc = %+v", doc)
+	}
+
+	if _, err := newArtifactDocument(&pb.Artifact{Type: pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW}, nil); err == nil {
+		t.Error("newArtifactDocument() for an audio artifact succeeded")
+	}
+}
+
+func TestRenderArtifact(t *testing.T) {
+	doc, err := newArtifactDocument(testReportArtifact(), map[string]string{"s1": "Annual Report"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var md bytes.Buffer
+	if err := renderMarkdown(&md, doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Café <Briefing>\n",
+		"in Q3.[^1] Costs fell.[^2]",
+		"## Outlook\n\n- steady\n- cautious[^2]\n",
+		"[^1]: Annual Report — “Revenue grew **12%** in Q3.”",
+		"[^3]: s3 — “not in the report”",
+	} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, md.String())
+		}
+	}
+
+	var h bytes.Buffer
+	if err := renderHTML(&h, doc); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Café &lt;Briefing&gt;</title>",
+		"<strong>12%</strong> in Q3.<sup class=\"cite\"><a href=\"#cite-1\">[1]</a></sup>",
+		"<h2>Outlook</h2>\n<ul>\n<li>steady</li>\n<li>cautious<sup class=\"cite\"><a href=\"#cite-2\">[2]</a></sup></li>",
+		"<li id=\"cite-2\">s2<blockquote>Costs fell.</blockquote><blockquote>cautious</blockquote></li>",
+		"@media print",
+	} {
+		if !strings.Contains(h.String(), want) {
+			t.Errorf("html missing %q:\n%s", want, h.String())
+		}
+	}
+	if strings.Contains(h.String(), "\x00") {
+		t.Error("html contains unreplaced citation tokens")
+	}
+
+	var js bytes.Buffer
+	if err := renderJSON(&js, doc); err != nil {
+		t.Fatal(err)
+	}
+	var back ArtifactDocument
+	if err := json.Unmarshal(js.Bytes(), &back); err != nil {
+		t.Fatalf("json output does not parse: %v", err)
+	}
+	if back.ID != "art1" || len(back.Citations) != 3 {
+		t.Errorf("json round trip = %+v", back)
+	}
+}
+
+func TestParseArtifactExportFlags(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"a"}, "md"},
+		{[]string{"a", "-o", "out.HTML"}, "html"},
+		{[]string{"a", "-o", "out.json"}, "json"},
+		{[]string{"a", "--format", "markdown", "-o", "out.html"}, "md"},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseArtifactExportFlags(tt.args)
+		if err != nil {
+			t.Errorf("parseArtifactExportFlags(%q) error: %v", tt.args, err)
+			continue
+		}
+		if opts.Format != tt.want {
+			t.Errorf("parseArtifactExportFlags(%q) format = %s, want %s", tt.args, opts.Format, tt.want)
+		}
+	}
+}
+
+func TestMarkdownToHTML(t *testing.T) {
+	got := markdownToHTML("## Plan\n\n1. first\n2. *second*\n\nSee [docs](https://example.com/?a=1&b=2) and [bad](javascript:alert(1)).\n\n```\n<x>\n```")
+	for _, want := range []string{
+		"<h2>Plan</h2>",
+		"<ol>\n<li>first</li>\n<li><em>second</em></li>\n</ol>",
+		`<a href="https://example.com/?a=1&amp;b=2">docs</a>`,
+		"[bad](javascript:alert(1))",
+		"<pre><code>&lt;x&gt;</code></pre>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdownToHTML() missing %q:\n%s", want, got)
+		}
+	}
+}
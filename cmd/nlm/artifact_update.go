@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+// ArtifactUpdateOptions contains the options for update-artifact.
+type ArtifactUpdateOptions struct {
+	Title       string
+	ContentFile string
+	Sources     stringList
+}
+
+// parseArtifactUpdateFlags parses
+// `update-artifact <artifact-id> [--title T] [--content-file F] [--sources id,...]`.
+func parseArtifactUpdateFlags(args []string) (*ArtifactUpdateOptions, []string, error) {
+	opts := &ArtifactUpdateOptions{}
+	fs := newCommandFlags("update-artifact")
+	fs.StringVar(&opts.Title, "title", "", "new title")
+	fs.StringVar(&opts.ContentFile, "content-file", "", "replace the content with this file (- for stdin)")
+	fs.Var(&opts.Sources, "sources", "replace the cited sources with these source IDs (repeatable)")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Title == "" && opts.ContentFile == "" && len(opts.Sources) == 0 {
+		return nil, nil, fmt.Errorf("nothing to update: give --title, --content-file or --sources")
+	}
+	return opts, pos, nil
+}
+
+func validateArtifactUpdateArgs(args []string) error {
+	_, pos, err := parseArtifactUpdateFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm update-artifact <artifact-id> [--title T] [--content-file F] [--sources id,...]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// artifactPatch builds the partial artifact and field mask paths that
+// apply opts to current. Title and content live on the report or note the
+// artifact wraps, so current decides which paths are used.
+func artifactPatch(current *pb.Artifact, opts *ArtifactUpdateOptions, content *string) (*pb.Artifact, []string, error) {
+	patch := &pb.Artifact{ArtifactId: current.GetArtifactId()}
+	var paths []string
+
+	if opts.Title != "" || content != nil {
+		switch {
+		case current.GetTailoredReport() != nil || current.GetType() == pb.ArtifactType_ARTIFACT_TYPE_REPORT:
+			patch.TailoredReport = &pb.Report{}
+			if opts.Title != "" {
+				patch.TailoredReport.Title = opts.Title
+				paths = append(paths, "tailored_report.title")
+			}
+			if content != nil {
+				patch.TailoredReport.Content = *content
+				paths = append(paths, "tailored_report.content")
+			}
+		case current.GetNote() != nil || current.GetType() == pb.ArtifactType_ARTIFACT_TYPE_NOTE:
+			patch.Note = &pb.Source{}
+			if opts.Title != "" {
+				patch.Note.Title = opts.Title
+				paths = append(paths, "note.title")
+			}
+			if content != nil {
+				patch.Note.Content = *content
+				paths = append(paths, "note.content")
+			}
+		default:
+			return nil, nil, fmt.Errorf("%s artifacts have no title or content to update", current.GetType())
+		}
+	}
+
+	if len(opts.Sources) > 0 {
+		for _, id := range opts.Sources {
+			patch.Sources = append(patch.Sources, &pb.ArtifactSource{SourceId: &pb.SourceId{SourceId: id}})
+		}
+		paths = append(paths, "sources")
+	}
+	return patch, paths, nil
+}
+
+// updateArtifact implements update-artifact.
+func updateArtifact(c *api.Client, args []string) error {
+	opts, pos, err := parseArtifactUpdateFlags(args)
+	if err != nil {
+		return err
+	}
+	artifactID := pos[0]
+
+	var content *string
+	if opts.ContentFile != "" {
+		var data []byte
+		if opts.ContentFile == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(opts.ContentFile)
+		}
+		if err != nil {
+			return fmt.Errorf("read content: %w", err)
+		}
+		s := string(data)
+		content = &s
+	}
+
+	current, err := c.GetArtifact(artifactID)
+	if err != nil {
+		return fmt.Errorf("update artifact: %w", err)
+	}
+	if current.GetArtifactId() == "" {
+		current.ArtifactId = artifactID
+	}
+	patch, paths, err := artifactPatch(current, opts, content)
+	if err != nil {
+		return fmt.Errorf("update artifact: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Updating artifact %s...\n", artifactID)
+	if _, err := c.UpdateArtifact(context.Background(), patch, paths...); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Updated artifact %s (%d field(s))\n", artifactID, len(paths))
+	return nil
+}
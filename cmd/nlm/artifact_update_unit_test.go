@@ -0,0 +1,70 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+func TestParseArtifactUpdateFlags(t *testing.T) {
+	opts, pos, err := parseArtifactUpdateFlags([]string{"art1", "--sources", "s1,s2", "--sources", "s3"})
+	if err != nil {
+		t.Fatalf("parseArtifactUpdateFlags() error: %v", err)
+	}
+	if len(pos) != 1 || !reflect.DeepEqual([]string(opts.Sources), []string{"s1", "s2", "s3"}) {
+		t.Errorf("opts = %+v, pos = %q", opts, pos)
+	}
+	if _, _, err := parseArtifactUpdateFlags([]string{"art1"}); err == nil {
+		t.Error("parseArtifactUpdateFlags() without changes succeeded")
+	}
+}
+
+func TestArtifactPatch(t *testing.T) {
+	content := "New body"
+	tests := []struct {
+		name      string
+		current   *pb.Artifact
+		opts      ArtifactUpdateOptions
+		content   *string
+		wantPaths []string
+	}{
+		{
+			name:      "report title and content",
+			current:   &pb.Artifact{ArtifactId: "a", Type: pb.ArtifactType_ARTIFACT_TYPE_REPORT},
+			opts:      ArtifactUpdateOptions{Title: "T"},
+			content:   &content,
+			wantPaths: []string{"tailored_report.title", "tailored_report.content"},
+		},
+		{
+			name:      "note content",
+			current:   &pb.Artifact{ArtifactId: "a", Note: &pb.Source{Title: "old"}},
+			content:   &content,
+			wantPaths: []string{"note.content"},
+		},
+		{
+			name:      "sources only on audio",
+			current:   &pb.Artifact{ArtifactId: "a", Type: pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW},
+			opts:      ArtifactUpdateOptions{Sources: stringList{"s1"}},
+			wantPaths: []string{"sources"},
+		},
+	}
+	for _, tt := range tests {
+		patch, paths, err := artifactPatch(tt.current, &tt.opts, tt.content)
+		if err != nil {
+			t.Errorf("%s: artifactPatch() error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(paths, tt.wantPaths) {
+			t.Errorf("%s: paths = %q, want %q", tt.name, paths, tt.wantPaths)
+		}
+		if patch.GetArtifactId() != "a" {
+			t.Errorf("%s: patch ID = %q", tt.name, patch.GetArtifactId())
+		}
+	}
+
+	_, _, err := artifactPatch(&pb.Artifact{Type: pb.ArtifactType_ARTIFACT_TYPE_APP}, &ArtifactUpdateOptions{Title: "x"}, nil)
+	if err == nil {
+		t.Error("artifactPatch() set a title on an app artifact")
+	}
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+// AskOptions contains the options for ask.
+type AskOptions struct {
+	JSON bool
+	ChatOptions
+}
+
+// parseAskFlags parses `ask <notebook-id> [question|-] [--session name] [--context C] [--json] [source flags]`.
+func parseAskFlags(args []string) (*AskOptions, []string, error) {
+	opts := &AskOptions{}
+	fs := newCommandFlags("ask")
+	fs.StringVar(&opts.Session, "session", "", "continue the named chat session and record the exchange in it")
+	fs.BoolVar(&opts.JSON, "json", false, "write the answer, citations and timing as JSON")
+	opts.registerSources(fs)
+	opts.registerContext(fs)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Session != "" {
+		if err := checkSessionName(opts.Session); err != nil {
+			return nil, nil, err
+		}
+	}
+	if _, err := ParseContextStrategy(opts.Context); err != nil {
+		return nil, nil, err
+	}
+	if _, err := opts.sourceFilter(); err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateAskArgs(args []string) error {
+	_, pos, err := parseAskFlags(args)
+	if err == nil && (len(pos) == 1 || len(pos) == 2) {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm ask <notebook-id> [question|-] [--session name] [--context full|window:N|summary] [--json] [--sources id,...] [--source-type T] [--source-match glob]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return &exitError{exitUsage, fmt.Errorf("invalid arguments")}
+}
+
+// AskResult is what ask --json writes.
+type AskResult struct {
+	Question string `json:"question"`
+	ChatAnswer
+	Session    string `json:"session,omitempty"`
+	DurationMS int64  `json:"duration_ms"`
+}
+
+// readQuestion returns the question given on the command line, or reads
+// it with readStdin when it is absent or "-".
+func readQuestion(pos []string, readStdin func() ([]byte, error)) (string, error) {
+	if len(pos) > 1 && pos[1] != "-" {
+		return strings.TrimSpace(pos[1]), nil
+	}
+	data, err := readStdin()
+	if err != nil {
+		return "", fmt.Errorf("read question: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ask implements ask: one question, the answer alone on stdout. Failures
+// exit with exitAuth, exitEmptyAnswer or 1 so scripts can tell them apart.
+func ask(c *api.Client, args []string) error {
+	opts, pos, err := parseAskFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+	question, err := readQuestion(pos, func() ([]byte, error) { return io.ReadAll(os.Stdin) })
+	if err != nil {
+		return err
+	}
+	if question == "" {
+		return &exitError{exitUsage, fmt.Errorf("no question given")}
+	}
+
+	var session *ChatSession
+	if opts.Session != "" {
+		session, err = loadNamedChatSession(notebookID, opts.Session)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				return fmt.Errorf("load session %s: %w", opts.Session, err)
+			}
+			session = &ChatSession{NotebookID: notebookID, Name: opts.Session, CreatedAt: time.Now()}
+		}
+	}
+
+	var sourceIDs []string
+	if opts.scoped() {
+		filter, _ := opts.sourceFilter()
+		if sourceIDs, err = chatSources(c, notebookID, opts.Sources, filter); err != nil {
+			return fmt.Errorf("ask: %w", err)
+		}
+	}
+	prompt := question
+	if session != nil {
+		if opts.scoped() {
+			session.SourceIDs = sourceIDs
+		}
+		sourceIDs = session.SourceIDs
+		strategy, _ := ParseContextStrategy(opts.Context)
+		prompt = newContextManager(strategy, chatSummarizer(c, notebookID)).prompt(session, question)
+	}
+
+	start := time.Now()
+	resp, err := c.GenerateFreeFormStreamed(notebookID, prompt, sourceIDs)
+	elapsed := time.Since(start)
+	if err != nil {
+		if isAuthenticationError(err) {
+			// ask runs unattended, so fail with exitAuth rather than
+			// letting run open a browser to log in again.
+			return &exitError{exitAuth, fmt.Errorf("ask: %w", err)}
+		}
+		return fmt.Errorf("ask: %w", err)
+	}
+	resp.Text = strings.TrimSpace(resp.Text)
+	if resp.Text == "" {
+		return &exitError{exitEmptyAnswer, fmt.Errorf("ask: empty answer")}
+	}
+
+	if session != nil {
+		now := time.Now()
+		session.Messages = append(session.Messages,
+			ChatMessage{Role: "user", Content: question, Timestamp: start},
+			ChatMessage{Role: "assistant", Content: resp.Text, Citations: resp.Citations, Timestamp: now},
+		)
+		session.UpdatedAt = now
+		if err := saveChatSession(session); err != nil {
+			fmt.Fprintf(os.Stderr, "nlm: warning: could not save session %s: %v\n", opts.Session, err)
+		}
+	}
+
+	if !opts.JSON {
+		fmt.Println(resp.Text)
+		return nil
+	}
+	titles := map[string]string{}
+	if len(resp.Citations) > 0 {
+		titles = chatSourceTitles(c, notebookID)
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(AskResult{
+		Question:   question,
+		ChatAnswer: *newChatAnswer(resp, titles),
+		Session:    opts.Session,
+		DurationMS: elapsed.Milliseconds(),
+	})
+}
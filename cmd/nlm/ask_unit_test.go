@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+func TestParseAskFlags(t *testing.T) {
+	opts, pos, err := parseAskFlags([]string{"nb", "--session", "ci-run_1", "What changed?", "--json", "--sources", "s1"})
+	if err != nil {
+		t.Fatalf("parseAskFlags error: %v", err)
+	}
+	if opts.Session != "ci-run_1" || !opts.JSON || len(pos) != 2 || !opts.scoped() {
+		t.Fatalf("got %+v %q", opts, pos)
+	}
+
+	for _, args := range [][]string{
+		{"nb", "--session", "../escape"},
+		{"nb", "--session", "two words"},
+		{"nb", "--source-type", "podcast"},
+	} {
+		if _, _, err := parseAskFlags(args); err == nil {
+			t.Errorf("parseAskFlags(%q) succeeded, want error", args)
+		}
+	}
+}
+
+func TestValidateAskArgsExitCode(t *testing.T) {
+	err := validateAskArgs(nil)
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitUsage {
+		t.Fatalf("validateAskArgs(nil) = %v, want exit code %d", err, exitUsage)
+	}
+	if err := validateAskArgs([]string{"nb", "-"}); err != nil {
+		t.Fatalf("validateAskArgs(nb -) = %v", err)
+	}
+}
+
+func TestExitErrorWrapping(t *testing.T) {
+	inner := fmt.Errorf("authentication required")
+	err := fmt.Errorf("run: %w", &exitError{exitAuth, inner})
+	var ee *exitError
+	if !errors.As(err, &ee) || ee.code != exitAuth {
+		t.Fatalf("errors.As failed for %v", err)
+	}
+	if !errors.Is(err, inner) || err.Error() != "run: authentication required" {
+		t.Errorf("exitError does not wrap transparently: %v", err)
+	}
+}
+
+func TestReadQuestion(t *testing.T) {
+	stdin := func() ([]byte, error) { return []byte("  from stdin\n"), nil }
+	tests := []struct {
+		pos  []string
+		want string
+	}{
+		{[]string{"nb", " inline "}, "inline"},
+		{[]string{"nb", "-"}, "from stdin"},
+		{[]string{"nb"}, "from stdin"},
+	}
+	for _, tt := range tests {
+		got, err := readQuestion(tt.pos, stdin)
+		if err != nil || got != tt.want {
+			t.Errorf("readQuestion(%q) = %q, %v; want %q", tt.pos, got, err, tt.want)
+		}
+	}
+
+	failing := func() ([]byte, error) { return nil, errors.New("closed") }
+	if _, err := readQuestion([]string{"nb"}, failing); err == nil {
+		t.Error("expected read error")
+	}
+}
+
+func TestAskResultJSON(t *testing.T) {
+	resp := &api.ChatResponse{
+		Text:      "Yes.",
+		Citations: []api.ChatCitation{{Number: 1, SourceID: "s1"}},
+	}
+	data, err := json.Marshal(AskResult{
+		Question:   "Is it?",
+		ChatAnswer: *newChatAnswer(resp, map[string]string{"s1": "Spec"}),
+		DurationMS: 42,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"question":"Is it?","answer":"Yes.","citations":[{"number":1,"source_id":"s1","source_title":"Spec"}],"duration_ms":42}`
+	if string(data) != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", data, want)
+	}
+	if strings.Contains(string(data), "session") {
+		t.Error("empty session should be omitted")
+	}
+}
+
+func TestNamedChatSessionPath(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	def := namedChatSessionPath("nb", "")
+	named := namedChatSessionPath("nb", "ci")
+	if def != getChatSessionPath("nb") {
+		t.Errorf("default session path = %s, want %s", def, getChatSessionPath("nb"))
+	}
+	if !strings.HasSuffix(named, filepath.Join("chats", "nb", "ci.json")) {
+		t.Errorf("named session path = %s", named)
+	}
+
+	session := &ChatSession{NotebookID: "nb", Name: "ci", Messages: []ChatMessage{{Role: "user", Content: "q"}}}
+	if err := saveChatSession(session); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadChatSession("nb"); err == nil {
+		t.Error("saving a named session should not create the default one")
+	}
+	loaded, err := loadNamedChatSession("nb", "ci")
+	if err != nil || loaded.Name != "ci" || len(loaded.Messages) != 1 {
+		t.Errorf("loadNamedChatSession = %+v, %v", loaded, err)
+	}
+}
@@ -213,6 +213,9 @@ func handleAuth(args []string, debug bool) (string, string, error) {
 	useDebug := opts.Debug || debug
 
 	a := auth.New(useDebug)
+	if logger != nil {
+		a.SetLogger(logger)
+	}
 
 	// Prepare options for auth call
 	// Custom options
@@ -0,0 +1,331 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+// Values of --citations.
+const (
+	citationsFootnotes = "footnotes"
+	citationsJSON      = "json"
+	citationsOff       = "off"
+)
+
+// ChatOptions contains the options for generate-chat and chat.
+type ChatOptions struct {
+	Session     string
+	Context     string
+	Citations   string
+	Sources     stringList
+	SourceTypes stringList
+	SourceMatch string
+}
+
+func (o *ChatOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.Citations, "citations", citationsFootnotes, "show citations as footnotes, json or off")
+	o.registerSources(fs)
+}
+
+// registerSources registers only the source selection flags.
+func (o *ChatOptions) registerSources(fs *flag.FlagSet) {
+	fs.Var(&o.Sources, "sources", "ask only these source IDs (repeatable)")
+	fs.Var(&o.SourceTypes, "source-type", "ask only sources of this type, e.g. PDF or YOUTUBE_VIDEO (repeatable)")
+	fs.StringVar(&o.SourceMatch, "source-match", "", "ask only sources whose title matches this glob")
+}
+
+// sourceFilter builds the filter described by --source-type and
+// --source-match.
+func (o *ChatOptions) sourceFilter() (api.SourceFilter, error) {
+	return (&BulkSourceOptions{Match: o.SourceMatch, Types: o.SourceTypes}).Filter()
+}
+
+// scoped reports whether the options limit the chat to some sources.
+func (o *ChatOptions) scoped() bool {
+	return len(o.Sources) > 0 || len(o.SourceTypes) > 0 || o.SourceMatch != ""
+}
+
+// parseChatFlags parses the flags of generate-chat and chat. Only chat
+// takes --session and --context.
+func parseChatFlags(cmd string, args []string) (*ChatOptions, []string, error) {
+	opts := &ChatOptions{}
+	fs := newCommandFlags(cmd)
+	opts.register(fs)
+	if cmd == "chat" {
+		fs.StringVar(&opts.Session, "session", defaultSessionName, "name of the conversation to continue or start")
+		opts.registerContext(fs)
+	}
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cmd == "chat" {
+		if err := checkSessionName(opts.Session); err != nil {
+			return nil, nil, err
+		}
+		if _, err := ParseContextStrategy(opts.Context); err != nil {
+			return nil, nil, err
+		}
+	}
+	switch opts.Citations {
+	case citationsFootnotes, citationsJSON, citationsOff:
+	default:
+		return nil, nil, fmt.Errorf("unknown --citations value %q (want footnotes, json or off)", opts.Citations)
+	}
+	if _, err := opts.sourceFilter(); err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateChatArgs(cmd, usage string, nargs int, args []string) error {
+	_, pos, err := parseChatFlags(cmd, args)
+	if err == nil && len(pos) == nargs {
+		return nil
+	}
+	session := ""
+	if cmd == "chat" {
+		session = " [--session name] [--context full|window:N|summary]"
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm %s %s%s [--sources id,...] [--source-type T] [--source-match glob] [--citations footnotes|json|off]\n", cmd, usage, session)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// printChatHelp lists the commands of the chat REPL.
+func printChatHelp() {
+	fmt.Println("\nCommands:")
+	fmt.Println("  /exit or /quit - Exit chat")
+	fmt.Println("  /clear - Clear screen")
+	fmt.Println("  /history - Show recent chat history")
+	fmt.Println("  /reset - Clear chat history")
+	fmt.Println("  /save - Save current session")
+	fmt.Println("  /sources [id...|all] - List sources, or pin the chat to some of them")
+	fmt.Println("  /undo - Remove the last question and answer")
+	fmt.Println("  /retry - Ask the last question again")
+	fmt.Println("  /fork [name] - Continue in a copy of this session")
+	fmt.Println("  /rename <name> - Rename this session")
+	fmt.Println("  /help - Show this help")
+	fmt.Println("  /multiline - Toggle multiline mode (end with empty line)")
+}
+
+// chatSources resolves the source IDs a chat is limited to. It returns
+// nil, meaning every source in the notebook, when neither IDs nor filters
+// are given.
+func chatSources(c *api.Client, notebookID string, ids []string, filter api.SourceFilter) ([]string, error) {
+	if len(ids) == 0 && filter.IsZero() {
+		return nil, nil
+	}
+	sources, err := selectSources(c, notebookID, ids, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no sources match")
+	}
+	return sourceIDs(sources), nil
+}
+
+// chatSourcesCommand implements the /sources REPL command:
+//
+//	/sources                                  list sources, marking the pinned ones
+//	/sources <id...> [--type T] [--match glob] pin the chat to the selected sources
+//	/sources all                              use every source again
+//
+// The selection is stored in the session so that it survives restarts.
+func chatSourcesCommand(c *api.Client, session *ChatSession, args []string) error {
+	if len(args) == 1 && strings.EqualFold(args[0], "all") {
+		session.SourceIDs = nil
+		fmt.Println("Chat now uses all sources.")
+		return saveChatSession(session)
+	}
+	if len(args) == 0 {
+		p, err := c.GetProject(session.NotebookID)
+		if err != nil {
+			return fmt.Errorf("get sources: %w", err)
+		}
+		return printChatSources(os.Stdout, p.GetSources(), session.SourceIDs)
+	}
+
+	opts := &BulkSourceOptions{}
+	fs := newCommandFlags("/sources")
+	fs.StringVar(&opts.Match, "match", "", "")
+	fs.Var(&opts.Types, "type", "")
+	ids, err := parseInterspersed(fs, args)
+	if err != nil {
+		return err
+	}
+	filter, err := opts.Filter()
+	if err != nil {
+		return err
+	}
+	pinned, err := chatSources(c, session.NotebookID, ids, filter)
+	if err != nil {
+		return err
+	}
+	session.SourceIDs = pinned
+	fmt.Printf("Chat pinned to %d source(s).\n", len(pinned))
+	return saveChatSession(session)
+}
+
+// printChatSources lists sources, marking those in pinned with an
+// asterisk. With nothing pinned every source is in use.
+func printChatSources(out io.Writer, sources []*pb.Source, pinned []string) error {
+	in := map[string]bool{}
+	for _, id := range pinned {
+		in[id] = true
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "USE\tID\tTITLE\tTYPE")
+	for _, src := range sources {
+		id := src.GetSourceId().GetSourceId()
+		use := ""
+		if len(pinned) == 0 || in[id] {
+			use = "*"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", use, id, strings.TrimSpace(src.GetTitle()), src.GetMetadata().GetSourceType())
+	}
+	return w.Flush()
+}
+
+// ChatAnswer is a chat answer with its citations resolved to source
+// titles. It is what --citations json writes.
+type ChatAnswer struct {
+	Answer    string         `json:"answer"`
+	Citations []ChatFootnote `json:"citations"`
+}
+
+// ChatFootnote is a citation together with the title of the cited source.
+type ChatFootnote struct {
+	api.ChatCitation
+	SourceTitle string `json:"source_title"`
+}
+
+// newChatAnswer resolves the citations of resp using sourceTitles, which
+// maps source IDs to titles; sources missing from it are cited by ID.
+func newChatAnswer(resp *api.ChatResponse, sourceTitles map[string]string) *ChatAnswer {
+	a := &ChatAnswer{Answer: resp.Text, Citations: []ChatFootnote{}}
+	for _, c := range resp.Citations {
+		f := ChatFootnote{ChatCitation: c, SourceTitle: sourceTitles[c.SourceID]}
+		if f.SourceTitle == "" {
+			f.SourceTitle = c.SourceID
+		}
+		a.Citations = append(a.Citations, f)
+	}
+	return a
+}
+
+// chatSourceTitles maps the source IDs of notebookID to their titles. A
+// notebook that cannot be loaded yields an empty map, so that footnotes
+// fall back to citing sources by ID.
+func chatSourceTitles(c *api.Client, notebookID string) map[string]string {
+	titles := map[string]string{}
+	p, err := c.GetProject(notebookID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: could not load source titles, citing by ID: %v\n", err)
+		return titles
+	}
+	for _, src := range p.GetSources() {
+		titles[src.GetSourceId().GetSourceId()] = strings.TrimSpace(src.GetTitle())
+	}
+	return titles
+}
+
+// markedAnswer returns the answer with a [n] marker after every span of
+// the answer that citation n supports.
+func (a *ChatAnswer) markedAnswer() string {
+	type mark struct{ pos, n int }
+	var marks []mark
+	for _, c := range a.Citations {
+		seen := map[int]bool{}
+		for _, s := range c.Spans {
+			if !seen[s.End] {
+				seen[s.End] = true
+				marks = append(marks, mark{s.End, c.Number})
+			}
+		}
+	}
+	sort.SliceStable(marks, func(i, j int) bool {
+		if marks[i].pos != marks[j].pos {
+			return marks[i].pos < marks[j].pos
+		}
+		return marks[i].n < marks[j].n
+	})
+
+	text := []rune(a.Answer)
+	var b strings.Builder
+	last := 0
+	for _, m := range marks {
+		if m.pos > len(text) {
+			continue
+		}
+		b.WriteString(string(text[last:m.pos]))
+		fmt.Fprintf(&b, "[%d]", m.n)
+		last = m.pos
+	}
+	b.WriteString(string(text[last:]))
+	return b.String()
+}
+
+// writeFootnotes writes one line per citation: its number, the source
+// title and the start of the cited passage.
+func (a *ChatAnswer) writeFootnotes(w io.Writer) {
+	if len(a.Citations) == 0 {
+		return
+	}
+	fmt.Fprintln(w)
+	for _, c := range a.Citations {
+		line := fmt.Sprintf("[%d] %s", c.Number, c.SourceTitle)
+		if len(c.Passages) > 0 {
+			if excerpt := collapseSpace(c.Passages[0].Text); excerpt != "" {
+				line += fmt.Sprintf(": %q", truncateRunes(excerpt, 80))
+			}
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// truncateRunes shortens s to at most n runes, marking the cut with an
+// ellipsis.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// writeChatAnswer writes resp to w in the form --citations asks for.
+// Source titles are only looked up when there are citations to show.
+func writeChatAnswer(w io.Writer, c *api.Client, notebookID string, resp *api.ChatResponse, mode string) error {
+	titles := map[string]string{}
+	if len(resp.Citations) > 0 && mode != citationsOff {
+		titles = chatSourceTitles(c, notebookID)
+	}
+	a := newChatAnswer(resp, titles)
+	switch mode {
+	case citationsJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(a)
+	case citationsOff:
+		_, err := fmt.Fprintln(w, a.Answer)
+		return err
+	}
+	if _, err := fmt.Fprintln(w, a.markedAnswer()); err != nil {
+		return err
+	}
+	a.writeFootnotes(w)
+	return nil
+}
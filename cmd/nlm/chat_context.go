@@ -0,0 +1,251 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+// Values of --context.
+const (
+	contextFull    = "full"
+	contextWindow  = "window"
+	contextSummary = "summary"
+)
+
+// contextTokens is how much of a prompt, in approximate tokens, the
+// conversation history and the new question may take together.
+const contextTokens = 3000
+
+// ChatSummary is a rolling summary of the older part of a conversation.
+type ChatSummary struct {
+	Text    string `json:"text"`
+	Through int    `json:"through"` // number of leading messages it covers
+}
+
+// ContextStrategy says which earlier turns go into a chat prompt:
+//
+//	full      as many turns as fit in the budget, newest first
+//	window:N  at most the last N messages
+//	summary   the recent turns that fit, plus a summary of the rest
+type ContextStrategy struct {
+	Mode   string
+	Window int
+}
+
+func (s ContextStrategy) String() string {
+	if s.Mode == contextWindow {
+		return fmt.Sprintf("%s:%d", contextWindow, s.Window)
+	}
+	return s.Mode
+}
+
+// ParseContextStrategy parses a --context value.
+func ParseContextStrategy(v string) (ContextStrategy, error) {
+	mode, arg, hasArg := strings.Cut(strings.ToLower(strings.TrimSpace(v)), ":")
+	switch {
+	case (mode == contextFull || mode == contextSummary) && !hasArg:
+		return ContextStrategy{Mode: mode}, nil
+	case mode == contextWindow:
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return ContextStrategy{}, fmt.Errorf("invalid --context %q: window needs a message count, e.g. window:6", v)
+		}
+		return ContextStrategy{Mode: contextWindow, Window: n}, nil
+	}
+	return ContextStrategy{}, fmt.Errorf("unknown --context %q (want full, window:N or summary)", v)
+}
+
+// registerContext registers --context.
+func (o *ChatOptions) registerContext(fs *flag.FlagSet) {
+	fs.StringVar(&o.Context, "context", contextSummary, "earlier turns to send: full, window:N or summary")
+}
+
+// contextManager builds chat prompts that stay within a token budget.
+type contextManager struct {
+	strategy ContextStrategy
+	budget   int // approximate tokens
+
+	// summarize answers prompt; it is used by the summary strategy to
+	// fold older turns into the session's rolling summary.
+	summarize func(prompt string) (string, error)
+}
+
+func newContextManager(strategy ContextStrategy, summarize func(string) (string, error)) *contextManager {
+	return &contextManager{strategy: strategy, budget: contextTokens, summarize: summarize}
+}
+
+// chatSummarizer returns a summarize function that asks the notebook's
+// chat, without streaming the reply to the terminal.
+func chatSummarizer(c *api.Client, notebookID string) func(string) (string, error) {
+	return func(prompt string) (string, error) {
+		resp, err := c.GenerateFreeFormStreamed(notebookID, prompt, nil)
+		if err != nil {
+			return "", err
+		}
+		return resp.Text, nil
+	}
+}
+
+// approxTokens estimates the token count of s at four bytes per token,
+// which is close enough for budgeting English text.
+func approxTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// isFallback reports whether the assistant message m is one of the canned
+// replies of getFallbackResponse rather than a real answer. Older sessions
+// did not mark them, so those are recognized by their text.
+func isFallback(m ChatMessage, question, notebookID string) bool {
+	return m.Role == "assistant" && (m.Fallback || m.Content == getFallbackResponse(question, notebookID))
+}
+
+// contextTurn is a message considered for the prompt, with its index in
+// the session.
+type contextTurn struct {
+	index int
+	msg   ChatMessage
+}
+
+// turns returns the messages worth sending as context: fallback replies
+// and the questions they answered are left out.
+func (m *contextManager) turns(s *ChatSession) []contextTurn {
+	var turns []contextTurn
+	for i := 0; i < len(s.Messages); i++ {
+		msg := s.Messages[i]
+		if msg.Role == "user" && i+1 < len(s.Messages) && isFallback(s.Messages[i+1], msg.Content, s.NotebookID) {
+			i++
+			continue
+		}
+		if msg.Fallback {
+			continue
+		}
+		turns = append(turns, contextTurn{i, msg})
+	}
+	return turns
+}
+
+// prompt returns the prompt for input given the conversation so far. With
+// the summary strategy it may update s.Summary, which the caller saves
+// with the session.
+func (m *contextManager) prompt(s *ChatSession, input string) string {
+	if s.Summary != nil && s.Summary.Through > len(s.Messages) {
+		// Messages it covered were undone or reset.
+		s.Summary = nil
+	}
+	turns := m.turns(s)
+	if m.strategy.Mode == contextWindow && len(turns) > m.strategy.Window {
+		turns = turns[len(turns)-m.strategy.Window:]
+	}
+
+	budget := m.budget - approxTokens(input)
+	if m.strategy.Mode == contextSummary {
+		// Leave room for the summary.
+		budget = budget * 2 / 3
+	}
+	recent := len(turns)
+	for recent > 0 {
+		t := approxTokens(turns[recent-1].msg.Content) + 4
+		if t > budget {
+			break
+		}
+		budget -= t
+		recent--
+	}
+
+	summary := ""
+	if m.strategy.Mode == contextSummary {
+		summary = m.rollSummary(s, turns[:recent])
+		if s.Summary != nil {
+			// Turns the summary covers are not repeated.
+			for recent < len(turns) && turns[recent].index < s.Summary.Through {
+				recent++
+			}
+		}
+	}
+	return formatContextPrompt(summary, turns[recent:], input)
+}
+
+// rollSummary folds the older turns that are not yet summarized into
+// s.Summary and returns the summary text. If summarizing fails the old
+// summary is kept and the turns are left out.
+func (m *contextManager) rollSummary(s *ChatSession, older []contextTurn) string {
+	through := 0
+	var prev string
+	if s.Summary != nil {
+		through, prev = s.Summary.Through, s.Summary.Text
+	}
+	var pending []contextTurn
+	for _, t := range older {
+		if t.index >= through {
+			pending = append(pending, t)
+		}
+	}
+	if len(pending) == 0 || m.summarize == nil {
+		return prev
+	}
+
+	text, err := m.summarize(summaryPrompt(prev, pending, m.budget))
+	text = strings.TrimSpace(text)
+	if err != nil || text == "" {
+		if err == nil {
+			err = fmt.Errorf("empty summary")
+		}
+		fmt.Fprintf(os.Stderr, "nlm: could not summarize earlier conversation, leaving it out: %v\n", err)
+		return prev
+	}
+	s.Summary = &ChatSummary{Text: text, Through: pending[len(pending)-1].index + 1}
+	return text
+}
+
+// summaryPrompt asks for prev to be extended with turns. The turns are cut
+// from the front to keep the request itself within budget.
+func summaryPrompt(prev string, turns []contextTurn, budget int) string {
+	var lines []string
+	used := approxTokens(prev)
+	for i := len(turns) - 1; i >= 0 && used < budget; i-- {
+		line := turnLine(turns[i].msg)
+		if t := approxTokens(line); used+t > budget {
+			line = truncateRunes(line, max(utf8.RuneCountInString(line)*(budget-used)/t, 1))
+		}
+		used += approxTokens(line)
+		lines = append([]string{line}, lines...)
+	}
+	var b strings.Builder
+	b.WriteString("Summarize the conversation below in at most 150 words, keeping facts, decisions and open questions that later questions may refer to. Reply with the summary only.\n\n")
+	if prev != "" {
+		fmt.Fprintf(&b, "Summary so far:\n%s\n\n", prev)
+	}
+	fmt.Fprintf(&b, "Conversation:\n%s", strings.Join(lines, "\n"))
+	return b.String()
+}
+
+func turnLine(m ChatMessage) string {
+	if m.Role == "user" {
+		return "User: " + m.Content
+	}
+	return "Assistant: " + m.Content
+}
+
+// formatContextPrompt wraps input with the summary and turns, or returns
+// it unchanged when there is no context.
+func formatContextPrompt(summary string, turns []contextTurn, input string) string {
+	if summary == "" && len(turns) == 0 {
+		return input
+	}
+	var b strings.Builder
+	if summary != "" {
+		fmt.Fprintf(&b, "Summary of the earlier conversation:\n%s\n\n", summary)
+	}
+	b.WriteString("Previous conversation:\n")
+	for _, t := range turns {
+		b.WriteString(turnLine(t.msg) + "\n")
+	}
+	fmt.Fprintf(&b, "User: %s\n\nPlease respond to the latest message, considering the conversation context.", input)
+	return b.String()
+}
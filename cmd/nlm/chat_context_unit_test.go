@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseContextStrategy(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ContextStrategy
+		wantErr bool
+	}{
+		{"full", ContextStrategy{Mode: contextFull}, false},
+		{"summary", ContextStrategy{Mode: contextSummary}, false},
+		{"window:6", ContextStrategy{Mode: contextWindow, Window: 6}, false},
+		{"Window:0", ContextStrategy{Mode: contextWindow}, false},
+		{"window", ContextStrategy{}, true},
+		{"window:-1", ContextStrategy{}, true},
+		{"full:3", ContextStrategy{}, true},
+		{"recent", ContextStrategy{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseContextStrategy(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseContextStrategy(%q) = %+v, %v", tt.in, got, err)
+		}
+	}
+}
+
+func conversation(n int) *ChatSession {
+	s := &ChatSession{NotebookID: "nb"}
+	for i := range n {
+		s.Messages = append(s.Messages,
+			ChatMessage{Role: "user", Content: "question " + strings.Repeat("q", 400) + string(rune('A'+i))},
+			ChatMessage{Role: "assistant", Content: "answer " + strings.Repeat("a", 400) + string(rune('A'+i))},
+		)
+	}
+	return s
+}
+
+func TestContextPromptWindow(t *testing.T) {
+	s := conversation(3)
+	prompt := newContextManager(ContextStrategy{Mode: contextWindow, Window: 2}, nil).prompt(s, "next")
+	if strings.Contains(prompt, "aB\n") || !strings.Contains(prompt, "qC\n") || !strings.Contains(prompt, "aC\n") {
+		t.Errorf("window:2 prompt should hold only the last exchange:\n%s", prompt)
+	}
+	if got := newContextManager(ContextStrategy{Mode: contextWindow}, nil).prompt(s, "next"); got != "next" {
+		t.Errorf("window:0 prompt = %q, want the bare question", got)
+	}
+}
+
+func TestContextPromptBudget(t *testing.T) {
+	s := conversation(20)
+	m := newContextManager(ContextStrategy{Mode: contextFull}, nil)
+	m.budget = 1000
+	prompt := m.prompt(s, "next")
+	if n := approxTokens(prompt); n > m.budget+100 {
+		t.Errorf("prompt is %d tokens, budget %d", n, m.budget)
+	}
+	if !strings.Contains(prompt, "aT\n") || strings.Contains(prompt, "qA\n") {
+		t.Errorf("full prompt should keep the newest turns and drop the oldest:\n%s", prompt)
+	}
+}
+
+func TestContextPromptDropsFallbacks(t *testing.T) {
+	s := &ChatSession{NotebookID: "nb", Messages: []ChatMessage{
+		{Role: "user", Content: "what is new"},
+		{Role: "assistant", Content: getFallbackResponse("what is new", "nb")},
+		{Role: "user", Content: "summarize"},
+		{Role: "assistant", Content: "Sorry, offline.", Fallback: true},
+		{Role: "user", Content: "list risks"},
+		{Role: "assistant", Content: "Two risks."},
+	}}
+	prompt := newContextManager(ContextStrategy{Mode: contextFull}, nil).prompt(s, "and mitigations?")
+	for _, unwanted := range []string{"what is new", "trouble connecting", "summarize", "offline"} {
+		if strings.Contains(prompt, unwanted) {
+			t.Errorf("prompt contains fallback exchange %q:\n%s", unwanted, prompt)
+		}
+	}
+	if !strings.Contains(prompt, "User: list risks\nAssistant: Two risks.\nUser: and mitigations?") {
+		t.Errorf("prompt lost the real exchange:\n%s", prompt)
+	}
+}
+
+func TestContextPromptSummary(t *testing.T) {
+	s := conversation(10)
+	var asked []string
+	m := newContextManager(ContextStrategy{Mode: contextSummary}, func(p string) (string, error) {
+		asked = append(asked, p)
+		return "They discussed letters.", nil
+	})
+	m.budget = 1000
+
+	prompt := m.prompt(s, "next")
+	if len(asked) != 1 || !strings.Contains(asked[0], "aG") || approxTokens(asked[0]) > m.budget+100 {
+		t.Fatalf("summarize calls = %d, first prompt:\n%v", len(asked), asked)
+	}
+	if s.Summary == nil || s.Summary.Through == 0 || s.Summary.Through >= len(s.Messages) {
+		t.Fatalf("Summary = %+v", s.Summary)
+	}
+	if !strings.Contains(prompt, "They discussed letters.") || !strings.Contains(prompt, "aJ\n") || strings.Contains(prompt, "qA\n") {
+		t.Errorf("summary prompt:\n%s", prompt)
+	}
+
+	// Nothing new to fold in: the stored summary is reused.
+	m.prompt(s, "again")
+	if len(asked) != 1 {
+		t.Errorf("summary was recomputed without new turns")
+	}
+
+	// New turns push older ones out: the summary rolls forward.
+	through := s.Summary.Through
+	s.Messages = append(s.Messages, conversation(4).Messages...)
+	m.prompt(s, "more")
+	if len(asked) != 2 || !strings.Contains(asked[1], "Summary so far:\nThey discussed letters.") || s.Summary.Through <= through {
+		t.Errorf("rolling summary: calls=%d through=%d (was %d)", len(asked), s.Summary.Through, through)
+	}
+
+	// Undoing summarized messages invalidates the summary.
+	s.Messages = s.Messages[:2]
+	m.summarize = func(string) (string, error) { return "", errors.New("offline") }
+	if prompt := m.prompt(s, "x"); strings.Contains(prompt, "letters") || s.Summary != nil {
+		t.Errorf("stale summary kept: %+v\n%s", s.Summary, prompt)
+	}
+}
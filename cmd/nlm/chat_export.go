@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+// ChatExportOptions contains the options for chat-export.
+type ChatExportOptions struct {
+	Session string
+	Format  string
+	Output  string
+}
+
+// parseChatExportFlags parses
+// `chat-export <notebook-id> [--session name] [--format md|html|jsonl] [-o file]`.
+func parseChatExportFlags(args []string) (*ChatExportOptions, []string, error) {
+	opts := &ChatExportOptions{}
+	fs := newCommandFlags(cmdChatExport)
+	fs.StringVar(&opts.Session, "session", defaultSessionName, "session to export")
+	fs.StringVar(&opts.Format, "format", "", "output format: md, html or jsonl (default from -o, else md)")
+	fs.StringVar(&opts.Output, "o", "", "write to this file instead of stdout")
+	fs.StringVar(&opts.Output, "output", "", "write to this file instead of stdout")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkSessionName(opts.Session); err != nil {
+		return nil, nil, err
+	}
+	if opts.Format == "" {
+		opts.Format = "md"
+		if strings.HasSuffix(strings.ToLower(opts.Output), ".jsonl") {
+			opts.Format = "jsonl"
+		} else if opts.Output != "" {
+			opts.Format = formatForPath(opts.Output)
+		}
+	}
+	if opts.Format == "markdown" {
+		opts.Format = "md"
+	}
+	switch opts.Format {
+	case "md", "html", "jsonl":
+	default:
+		return nil, nil, fmt.Errorf("unknown format %q (want md, html or jsonl)", opts.Format)
+	}
+	return opts, pos, nil
+}
+
+func validateChatExportArgs(args []string) error {
+	_, pos, err := parseChatExportFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm chat-export <notebook-id> [--session name] [--format md|html|jsonl] [-o file]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// exportChat implements chat-export. It works from the saved session
+// alone, so cited sources are shown by ID.
+func exportChat(args []string) error {
+	opts, pos, err := parseChatExportFlags(args)
+	if err != nil {
+		return err
+	}
+	session, err := loadNamedChatSession(pos[0], opts.Session)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("export chat: no session %s for notebook %s (have: %s)",
+				opts.Session, pos[0], strings.Join(listNotebookSessions(pos[0]), ", "))
+		}
+		return fmt.Errorf("export chat: %w", err)
+	}
+
+	render := renderChatMarkdown
+	switch opts.Format {
+	case "html":
+		render = renderChatHTML
+	case "jsonl":
+		render = renderChatJSONL
+	}
+	if opts.Output == "" {
+		return render(os.Stdout, session)
+	}
+	f, err := os.Create(opts.Output)
+	if err != nil {
+		return fmt.Errorf("export chat: %w", err)
+	}
+	if err := render(f, session); err != nil {
+		f.Close()
+		return fmt.Errorf("export chat: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("export chat: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Exported %d message(s) to %s\n", len(session.Messages), opts.Output)
+	return nil
+}
+
+func chatTitle(s *ChatSession) string {
+	return fmt.Sprintf("Chat %s (notebook %s)", s.Name, s.NotebookID)
+}
+
+func chatSpeaker(m ChatMessage) string {
+	if m.Role == "user" {
+		return "You"
+	}
+	return "Assistant"
+}
+
+// messageAnswer returns m as a ChatAnswer, with sources cited by ID.
+func messageAnswer(m ChatMessage) *ChatAnswer {
+	return newChatAnswer(&api.ChatResponse{Text: m.Content, Citations: m.Citations}, nil)
+}
+
+// renderChatMarkdown writes the session as Markdown, one heading per
+// message, with each answer's citations listed below it.
+func renderChatMarkdown(w io.Writer, s *ChatSession) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n", chatTitle(s))
+	for _, m := range s.Messages {
+		fmt.Fprintf(&b, "\n## %s · %s\n\n", chatSpeaker(m), m.Timestamp.Format("2006-01-02 15:04"))
+		a := messageAnswer(m)
+		b.WriteString(strings.TrimSpace(a.markedAnswer()) + "\n")
+		if len(a.Citations) > 0 {
+			b.WriteString("\n")
+			for _, c := range a.Citations {
+				fmt.Fprintf(&b, "%d. %s", c.Number, c.SourceTitle)
+				if len(c.Passages) > 0 && c.Passages[0].Text != "" {
+					fmt.Fprintf(&b, ": “%s”", collapseSpace(c.Passages[0].Text))
+				}
+				b.WriteString("\n")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderChatHTML writes the session as a standalone HTML page using the
+// same print styles as artifact-export.
+func renderChatHTML(w io.Writer, s *ChatSession) error {
+	var b strings.Builder
+	title := html.EscapeString(chatTitle(s))
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n", title)
+	b.WriteString(exportCSS)
+	b.WriteString("</head>\n<body>\n<article>\n")
+	fmt.Fprintf(&b, "<h1>%s</h1>\n", title)
+	for i, m := range s.Messages {
+		fmt.Fprintf(&b, "<section class=\"%s\">\n<h2>%s · %s</h2>\n", html.EscapeString(m.Role),
+			chatSpeaker(m), m.Timestamp.Format("2006-01-02 15:04"))
+		a := messageAnswer(m)
+		body := markdownToHTML(a.markedAnswer())
+		for _, c := range a.Citations {
+			body = strings.ReplaceAll(body, fmt.Sprintf("[%d]", c.Number),
+				fmt.Sprintf(`<sup class="cite"><a href="#m%d-cite-%d">[%d]</a></sup>`, i+1, c.Number, c.Number))
+		}
+		b.WriteString(body)
+		if len(a.Citations) > 0 {
+			b.WriteString("<ol>\n")
+			for _, c := range a.Citations {
+				fmt.Fprintf(&b, "<li id=\"m%d-cite-%d\">%s", i+1, c.Number, html.EscapeString(c.SourceTitle))
+				for _, p := range c.Passages {
+					if p.Text != "" {
+						fmt.Fprintf(&b, "<blockquote>%s</blockquote>", html.EscapeString(collapseSpace(p.Text)))
+					}
+				}
+				b.WriteString("</li>\n")
+			}
+			b.WriteString("</ol>\n")
+		}
+		b.WriteString("</section>\n")
+	}
+	b.WriteString("</article>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// chatLine is one line of a JSONL chat export.
+type chatLine struct {
+	NotebookID string `json:"notebook_id"`
+	Session    string `json:"session"`
+	ChatMessage
+}
+
+// renderChatJSONL writes one JSON object per message.
+func renderChatJSONL(w io.Writer, s *ChatSession) error {
+	enc := json.NewEncoder(w)
+	for _, m := range s.Messages {
+		if err := enc.Encode(chatLine{NotebookID: s.NotebookID, Session: s.Name, ChatMessage: m}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,317 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// Chat sessions are stored one file per session, as
+// ~/.nlm/chats/<notebook-id>/<name>.json. Version 1 files, which predate
+// named sessions, held one session per notebook as ~/.nlm/chat-<notebook-id>.json,
+// or $TMPDIR/nlm-chat-<notebook-id>.json when there was no home directory,
+// and are moved on first use.
+const (
+	chatSessionVersion = 2
+	defaultSessionName = "default"
+)
+
+var sessionName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// checkSessionName reports whether name can be used as a session name.
+func checkSessionName(name string) error {
+	if !sessionName.MatchString(name) {
+		return fmt.Errorf("invalid session name %q: use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// nlmDir returns ~/.nlm, or the temp directory if there is no home.
+func nlmDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return os.TempDir()
+	}
+	return filepath.Join(homeDir, ".nlm")
+}
+
+func chatSessionsDir() string {
+	return filepath.Join(nlmDir(), "chats")
+}
+
+// getChatSessionPath returns the file of the notebook's default session,
+// creating its directory if needed.
+func getChatSessionPath(notebookID string) string {
+	path := namedChatSessionPath(notebookID, "")
+	_ = os.MkdirAll(filepath.Dir(path), 0o700)
+	return path
+}
+
+// namedChatSessionPath returns the file holding the session called name.
+// The unnamed session is the notebook's default one.
+func namedChatSessionPath(notebookID, name string) string {
+	if name == "" {
+		name = defaultSessionName
+	}
+	dir := notebookID
+	if dir == "" {
+		dir = "_"
+	}
+	return filepath.Join(chatSessionsDir(), filepath.Base(dir), name+".json")
+}
+
+func loadChatSession(notebookID string) (*ChatSession, error) {
+	return loadNamedChatSession(notebookID, "")
+}
+
+func loadNamedChatSession(notebookID, name string) (*ChatSession, error) {
+	ensureChatSessionsMigrated()
+	//nolint:gosec // file path is user-provided
+	data, err := os.ReadFile(namedChatSessionPath(notebookID, name))
+	if err != nil {
+		return nil, err
+	}
+
+	var session ChatSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, err
+	}
+	if session.Version > chatSessionVersion {
+		return nil, fmt.Errorf("chat session %s was written by a newer nlm (version %d)", name, session.Version)
+	}
+	if session.Name == "" {
+		session.Name = defaultSessionName
+	}
+	return &session, nil
+}
+
+func saveChatSession(session *ChatSession) error {
+	if session.Name == "" {
+		session.Name = defaultSessionName
+	}
+	session.Version = chatSessionVersion
+	path := namedChatSessionPath(session.NotebookID, session.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(session, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+// migrated records the directories migrateChatSessions has already run for.
+var migrated sync.Map
+
+// ensureChatSessionsMigrated runs migrateChatSessions the first time the
+// process uses a sessions directory.
+func ensureChatSessionsMigrated() {
+	if _, done := migrated.LoadOrStore(nlmDir(), true); !done {
+		migrateChatSessions()
+	}
+}
+
+// migrateChatSessions moves version 1 session files into the per-notebook
+// layout. Files that cannot be read or would overwrite an existing session
+// are left where they are.
+func migrateChatSessions() {
+	var paths []string
+	if home, err := os.UserHomeDir(); err == nil {
+		paths, _ = filepath.Glob(filepath.Join(home, ".nlm", "chat-*.json"))
+	} else {
+		// Without a home directory version 1 used the temp directory,
+		// which other users can write to: only take our own files.
+		tmp, _ := filepath.Glob(filepath.Join(os.TempDir(), "nlm-chat-*.json"))
+		for _, path := range tmp {
+			if info, err := os.Lstat(path); err == nil && info.Mode().IsRegular() && ownedByUser(info) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	for _, path := range paths {
+		//nolint:gosec // file path is from our own directory
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var session ChatSession
+		if err := json.Unmarshal(data, &session); err != nil || session.NotebookID == "" {
+			continue
+		}
+		if session.Name == "" {
+			session.Name = defaultSessionName
+		}
+		if _, err := os.Stat(namedChatSessionPath(session.NotebookID, session.Name)); err == nil {
+			continue
+		}
+		if err := saveChatSession(&session); err != nil {
+			continue
+		}
+		_ = os.Remove(path)
+	}
+}
+
+// listNotebookSessions returns the names of the notebook's sessions.
+func listNotebookSessions(notebookID string) []string {
+	ensureChatSessionsMigrated()
+	files, _ := filepath.Glob(filepath.Join(filepath.Dir(namedChatSessionPath(notebookID, "")), "*.json"))
+	var names []string
+	for _, f := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(f), ".json"))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func listChatSessions() error {
+	ensureChatSessionsMigrated()
+	files, _ := filepath.Glob(filepath.Join(chatSessionsDir(), "*", "*.json"))
+
+	var sessions []ChatSession
+	for _, f := range files {
+		//nolint:gosec // file path is from our own directory
+		data, err := os.ReadFile(f)
+		if err != nil {
+			continue
+		}
+
+		var session ChatSession
+		if err := json.Unmarshal(data, &session); err != nil {
+			continue
+		}
+		if session.Name == "" {
+			session.Name = defaultSessionName
+		}
+		sessions = append(sessions, session)
+	}
+
+	if len(sessions) == 0 {
+		fmt.Println("No chat sessions found.")
+		return nil
+	}
+
+	fmt.Printf("📚 Chat Sessions (%d total)\n", len(sessions))
+	fmt.Println("=" + strings.Repeat("=", 40))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NOTEBOOK\tSESSION\tMESSAGES\tLAST UPDATED\tCREATED")
+	_, _ = fmt.Fprintln(w, "--------\t-------\t--------\t------------\t-------")
+
+	for _, session := range sessions {
+		lastUpdated := session.UpdatedAt.Format("Jan 2 15:04")
+		created := session.CreatedAt.Format("Jan 2 15:04")
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n",
+			session.NotebookID,
+			session.Name,
+			len(session.Messages),
+			lastUpdated,
+			created)
+	}
+
+	return w.Flush()
+}
+
+// undo removes the last exchange: the last assistant reply and the
+// question before it, or a trailing question that got no reply. It
+// returns the number of messages removed.
+func (s *ChatSession) undo() int {
+	n := len(s.Messages)
+	if n == 0 {
+		return 0
+	}
+	cut := n - 1
+	if s.Messages[cut].Role == "assistant" && cut > 0 && s.Messages[cut-1].Role == "user" {
+		cut--
+	}
+	s.Messages = s.Messages[:cut]
+	s.UpdatedAt = time.Now()
+	return n - cut
+}
+
+// popLastQuestion removes the last question and everything after it, and
+// returns the question so that it can be asked again.
+func (s *ChatSession) popLastQuestion() (string, bool) {
+	for i := len(s.Messages) - 1; i >= 0; i-- {
+		if s.Messages[i].Role == "user" {
+			q := s.Messages[i].Content
+			s.Messages = s.Messages[:i]
+			s.UpdatedAt = time.Now()
+			return q, true
+		}
+	}
+	return "", false
+}
+
+// fork returns a copy of the session called name. With an empty name the
+// fork is called <session>-2, <session>-3, ... whichever is free.
+func (s *ChatSession) fork(name string) (*ChatSession, error) {
+	if name == "" {
+		taken := map[string]bool{}
+		for _, n := range listNotebookSessions(s.NotebookID) {
+			taken[n] = true
+		}
+		for i := 2; ; i++ {
+			if name = fmt.Sprintf("%s-%d", s.Name, i); !taken[name] {
+				break
+			}
+		}
+	} else if err := s.checkFreeName(name); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	var summary *ChatSummary
+	if s.Summary != nil {
+		copied := *s.Summary
+		summary = &copied
+	}
+	return &ChatSession{
+		NotebookID: s.NotebookID,
+		Name:       name,
+		ForkedFrom: s.Name,
+		SourceIDs:  append([]string(nil), s.SourceIDs...),
+		Summary:    summary,
+		Messages:   append([]ChatMessage(nil), s.Messages...),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}, nil
+}
+
+// rename saves the session under a new name and removes the old file.
+func (s *ChatSession) rename(name string) error {
+	if err := s.checkFreeName(name); err != nil {
+		return err
+	}
+	old := namedChatSessionPath(s.NotebookID, s.Name)
+	prev := s.Name
+	s.Name = name
+	if err := saveChatSession(s); err != nil {
+		s.Name = prev
+		return err
+	}
+	if err := os.Remove(old); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// checkFreeName reports an error unless name is valid and not yet used by
+// another session of the notebook.
+func (s *ChatSession) checkFreeName(name string) error {
+	if err := checkSessionName(name); err != nil {
+		return err
+	}
+	if _, err := os.Stat(namedChatSessionPath(s.NotebookID, name)); err == nil {
+		return fmt.Errorf("session %s already exists", name)
+	}
+	return nil
+}
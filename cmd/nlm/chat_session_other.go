@@ -0,0 +1,11 @@
+//go:build !unix
+
+package main
+
+import "os"
+
+// ownedByUser reports true: where there are no Unix owners, such as on
+// Windows, the temp directory is already private to the user.
+func ownedByUser(info os.FileInfo) bool {
+	return true
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+func writeChatSessionFile(t *testing.T, path string, s ChatSession) {
+	t.Helper()
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateChatSessions(t *testing.T) {
+	home, tmp := t.TempDir(), t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("TMPDIR", tmp)
+	dir := filepath.Join(home, ".nlm")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	writeChatSessionFile(t, filepath.Join(dir, "chat-nb-1.json"), ChatSession{NotebookID: "nb-1", Messages: []ChatMessage{{Role: "user", Content: "hi"}}})
+	writeChatSessionFile(t, filepath.Join(dir, "chat-orphan.json"), ChatSession{})
+	// The temp directory is only used when there is no home directory.
+	writeChatSessionFile(t, filepath.Join(tmp, "nlm-chat-nb-2.json"), ChatSession{NotebookID: "nb-2", Messages: []ChatMessage{{Role: "user", Content: "hello"}}})
+	if err := os.WriteFile(filepath.Join(dir, "chat-bad.json"), []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	migrateChatSessions()
+	s, err := loadChatSession("nb-1")
+	if err != nil {
+		t.Fatalf("loadChatSession after migration: %v", err)
+	}
+	if s.Name != defaultSessionName || len(s.Messages) != 1 {
+		t.Errorf("migrated default session = %+v", s)
+	}
+	if got := listNotebookSessions("nb-1"); !reflect.DeepEqual(got, []string{"default"}) {
+		t.Errorf("listNotebookSessions = %v", got)
+	}
+	if _, err := loadChatSession("nb-2"); err == nil {
+		t.Error("session in the temp directory was imported although there is a home directory")
+	}
+	for path, want := range map[string]bool{
+		filepath.Join(dir, "chat-nb-1.json"):     false,
+		filepath.Join(tmp, "nlm-chat-nb-2.json"): true,
+		filepath.Join(dir, "chat-orphan.json"):   true,
+		filepath.Join(dir, "chat-bad.json"):      true,
+	} {
+		if _, err := os.Stat(path); (err == nil) != want {
+			t.Errorf("%s still present = %v, want %v", filepath.Base(path), err == nil, want)
+		}
+	}
+
+	data, err := os.ReadFile(namedChatSessionPath("nb-1", ""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct{ Version int }
+	if err := json.Unmarshal(data, &raw); err != nil || raw.Version != chatSessionVersion {
+		t.Errorf("migrated file version = %d, %v", raw.Version, err)
+	}
+}
+
+func TestMigrateChatSessionsWithoutHome(t *testing.T) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "plan9" {
+		t.Skip("needs $HOME to decide the home directory")
+	}
+	tmp := t.TempDir()
+	t.Setenv("HOME", "")
+	t.Setenv("TMPDIR", tmp)
+	mine := filepath.Join(tmp, "nlm-chat-nb-1.json")
+	writeChatSessionFile(t, mine, ChatSession{NotebookID: "nb-1", Messages: []ChatMessage{{Role: "user", Content: "hi"}}})
+	// A file planted by another user must not be imported.
+	theirs := filepath.Join(tmp, "nlm-chat-nb-2.json")
+	writeChatSessionFile(t, theirs, ChatSession{NotebookID: "nb-2", Messages: []ChatMessage{{Role: "user", Content: "hello"}}})
+	canChown := os.Chown(theirs, os.Getuid()+1, -1) == nil
+
+	migrateChatSessions()
+	if s, err := loadChatSession("nb-1"); err != nil || len(s.Messages) != 1 {
+		t.Errorf("session migrated from the temp directory = %+v, %v", s, err)
+	}
+	if _, err := os.Stat(mine); err == nil {
+		t.Error("migrated temp file still present")
+	}
+	if !canChown {
+		return
+	}
+	if _, err := loadChatSession("nb-2"); err == nil {
+		t.Error("imported a session file owned by another user")
+	}
+	if _, err := os.Stat(theirs); err != nil {
+		t.Errorf("another user's file was removed: %v", err)
+	}
+}
+
+func TestLoadChatSessionNewerVersion(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	path := namedChatSessionPath("nb", "")
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(`{"version": 99, "notebook_id": "nb"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadChatSession("nb"); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("loadChatSession error = %v, want newer version error", err)
+	}
+}
+
+func chatHistory(roles ...string) *ChatSession {
+	s := &ChatSession{NotebookID: "nb", Name: defaultSessionName}
+	for i, r := range roles {
+		s.Messages = append(s.Messages, ChatMessage{Role: r, Content: r + string(rune('0'+i))})
+	}
+	return s
+}
+
+func TestChatSessionUndo(t *testing.T) {
+	tests := []struct {
+		roles       []string
+		wantRemoved int
+		wantLeft    int
+	}{
+		{nil, 0, 0},
+		{[]string{"user", "assistant", "user", "assistant"}, 2, 2},
+		{[]string{"user", "assistant", "user"}, 1, 2},
+		{[]string{"assistant"}, 1, 0},
+	}
+	for _, tt := range tests {
+		s := chatHistory(tt.roles...)
+		if got := s.undo(); got != tt.wantRemoved || len(s.Messages) != tt.wantLeft {
+			t.Errorf("undo(%v) removed %d leaving %d, want %d leaving %d",
+				tt.roles, got, len(s.Messages), tt.wantRemoved, tt.wantLeft)
+		}
+	}
+}
+
+func TestChatSessionPopLastQuestion(t *testing.T) {
+	s := chatHistory("user", "assistant", "user", "assistant")
+	q, ok := s.popLastQuestion()
+	if !ok || q != "user2" || len(s.Messages) != 2 {
+		t.Errorf("popLastQuestion = %q, %v with %d left", q, ok, len(s.Messages))
+	}
+	if _, ok := chatHistory("assistant").popLastQuestion(); ok {
+		t.Error("popLastQuestion without a question should fail")
+	}
+}
+
+func TestChatSessionForkAndRename(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	s := chatHistory("user", "assistant")
+	s.SourceIDs = []string{"src-1"}
+	if err := saveChatSession(s); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := s.fork("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Name != "default-2" || f.ForkedFrom != defaultSessionName || len(f.Messages) != 2 {
+		t.Errorf("fork = %+v", f)
+	}
+	f.Messages[0].Content = "changed"
+	f.SourceIDs[0] = "changed"
+	if s.Messages[0].Content == "changed" || s.SourceIDs[0] == "changed" {
+		t.Error("fork shares state with the original session")
+	}
+	if err := saveChatSession(f); err != nil {
+		t.Fatal(err)
+	}
+	if f2, err := s.fork(""); err != nil || f2.Name != "default-3" {
+		t.Errorf("second fork = %v, %v", f2, err)
+	}
+	if _, err := s.fork("default-2"); err == nil {
+		t.Error("fork onto an existing session should fail")
+	}
+	if _, err := s.fork("bad name"); err == nil {
+		t.Error("fork with an invalid name should fail")
+	}
+
+	if err := f.rename(defaultSessionName); err == nil {
+		t.Error("rename onto an existing session should fail")
+	}
+	if err := f.rename("design-review"); err != nil {
+		t.Fatal(err)
+	}
+	if got := listNotebookSessions("nb"); !reflect.DeepEqual(got, []string{"default", "design-review"}) {
+		t.Errorf("sessions after rename = %v", got)
+	}
+}
+
+func TestParseChatExportFlags(t *testing.T) {
+	tests := []struct {
+		args       []string
+		wantFormat string
+		wantErr    bool
+	}{
+		{[]string{"nb"}, "md", false},
+		{[]string{"nb", "-o", "chat.html"}, "html", false},
+		{[]string{"nb", "-o", "chat.jsonl"}, "jsonl", false},
+		{[]string{"nb", "--format", "markdown"}, "md", false},
+		{[]string{"nb", "--format", "jsonl", "-o", "chat.txt"}, "jsonl", false},
+		{[]string{"nb", "--format", "pdf"}, "", true},
+		{[]string{"nb", "--session", "a b"}, "", true},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseChatExportFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseChatExportFlags(%v) error = %v", tt.args, err)
+			continue
+		}
+		if err == nil && opts.Format != tt.wantFormat {
+			t.Errorf("parseChatExportFlags(%v) format = %q, want %q", tt.args, opts.Format, tt.wantFormat)
+		}
+	}
+}
+
+func exportSession() *ChatSession {
+	ts := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	return &ChatSession{
+		NotebookID: "nb",
+		Name:       "review",
+		Messages: []ChatMessage{
+			{Role: "user", Content: "Is Go fast?", Timestamp: ts},
+			{Role: "assistant", Content: "Go is fast.", Timestamp: ts, Citations: []api.ChatCitation{{
+				Number: 1, SourceID: "src-a",
+				Spans:    []api.ChatSpan{{Start: 0, End: 11}},
+				Passages: []api.ChatPassage{{Text: "Go compiles quickly."}},
+			}}},
+		},
+	}
+}
+
+func TestRenderChatMarkdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderChatMarkdown(&buf, exportSession()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Chat review (notebook nb)",
+		"## You · 2026-03-01 09:30\n\nIs Go fast?",
+		"Go is fast.[1]",
+		"1. src-a: “Go compiles quickly.”",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRenderChatHTML(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderChatHTML(&buf, exportSession()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<title>Chat review (notebook nb)</title>",
+		`<a href="#m2-cite-1">[1]</a>`,
+		`<li id="m2-cite-1">src-a<blockquote>Go compiles quickly.</blockquote></li>`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("html missing %q:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestRenderChatJSONL(t *testing.T) {
+	var buf bytes.Buffer
+	if err := renderChatJSONL(&buf, exportSession()); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+	var line chatLine
+	if err := json.Unmarshal([]byte(lines[1]), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line.Session != "review" || line.Role != "assistant" || len(line.Citations) != 1 {
+		t.Errorf("second line = %+v", line)
+	}
+}
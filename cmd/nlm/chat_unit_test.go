@@ -1,12 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
 )
 
 func TestChatSessionPathsAndPersistence(t *testing.T) {
@@ -157,7 +161,7 @@ func TestShowRecentHistory(t *testing.T) {
 	}
 }
 
-func TestBuildContextualPrompt(t *testing.T) {
+func TestContextualPrompt(t *testing.T) {
 	session := &ChatSession{
 		NotebookID: "nb",
 		Messages: []ChatMessage{
@@ -169,7 +173,7 @@ func TestBuildContextualPrompt(t *testing.T) {
 		},
 	}
 
-	prompt := buildContextualPrompt(session, "Six")
+	prompt := newContextManager(ContextStrategy{Mode: contextWindow, Window: 4}, nil).prompt(session, "Six")
 	if !strings.Contains(prompt, "Previous conversation") {
 		t.Fatalf("expected contextual prompt")
 	}
@@ -218,3 +222,166 @@ func TestChatSessionJSONRoundTrip(t *testing.T) {
 		t.Fatalf("expected %q, got %q", session.NotebookID, roundTrip.NotebookID)
 	}
 }
+
+func TestParseChatFlags(t *testing.T) {
+	opts, pos, err := parseChatFlags("generate-chat", []string{"nb", "--citations", "json", "a question"})
+	if err != nil {
+		t.Fatalf("parseChatFlags error: %v", err)
+	}
+	if opts.Citations != citationsJSON || len(pos) != 2 || pos[1] != "a question" {
+		t.Fatalf("got %+v %q", opts, pos)
+	}
+
+	opts, _, err = parseChatFlags("chat", []string{"nb"})
+	if err != nil || opts.Citations != citationsFootnotes {
+		t.Fatalf("default citations = %+v, %v", opts, err)
+	}
+
+	if _, _, err := parseChatFlags("chat", []string{"nb", "--citations", "xml"}); err == nil {
+		t.Fatal("expected error for unknown --citations value")
+	}
+}
+
+func testChatResponse() *api.ChatResponse {
+	return &api.ChatResponse{
+		Text: "Go is fast. It is simple.",
+		Citations: []api.ChatCitation{
+			{
+				Number: 1, SourceID: "src-a",
+				Spans:    []api.ChatSpan{{Start: 0, End: 11}, {Start: 12, End: 25}},
+				Passages: []api.ChatPassage{{Text: "Go compiles\n quickly.", Start: 100, End: 140}},
+			},
+			{
+				Number: 2, SourceID: "src-b",
+				Spans: []api.ChatSpan{{Start: 12, End: 25}, {Start: 30, End: 40}},
+			},
+		},
+	}
+}
+
+func TestChatAnswerFootnotes(t *testing.T) {
+	a := newChatAnswer(testChatResponse(), map[string]string{"src-a": "Go Spec"})
+
+	if got, want := a.markedAnswer(), "Go is fast.[1] It is simple.[1][2]"; got != want {
+		t.Errorf("markedAnswer() = %q, want %q", got, want)
+	}
+
+	var buf bytes.Buffer
+	a.writeFootnotes(&buf)
+	want := "\n[1] Go Spec: \"Go compiles quickly.\"\n[2] src-b\n"
+	if buf.String() != want {
+		t.Errorf("writeFootnotes() = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestWriteChatAnswer(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeChatAnswer(&buf, nil, "nb", testChatResponse(), citationsOff); err != nil {
+		t.Fatalf("writeChatAnswer error: %v", err)
+	}
+	if buf.String() != "Go is fast. It is simple.\n" {
+		t.Errorf("off: got %q", buf.String())
+	}
+
+	buf.Reset()
+	resp := &api.ChatResponse{Text: "No sources here."}
+	if err := writeChatAnswer(&buf, nil, "nb", resp, citationsJSON); err != nil {
+		t.Fatalf("writeChatAnswer error: %v", err)
+	}
+	var got ChatAnswer
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshal %q: %v", buf.String(), err)
+	}
+	if got.Answer != resp.Text || got.Citations == nil || len(got.Citations) != 0 {
+		t.Errorf("json: got %+v", got)
+	}
+}
+
+func TestChatAnswerJSON(t *testing.T) {
+	a := newChatAnswer(testChatResponse(), map[string]string{"src-a": "Go Spec"})
+	data, err := json.Marshal(a)
+	if err != nil {
+		t.Fatalf("marshal error: %v", err)
+	}
+	for _, want := range []string{
+		`"answer":"Go is fast. It is simple."`,
+		`"number":1,"source_id":"src-a"`,
+		`"source_title":"Go Spec"`,
+		`"source_title":"src-b"`,
+		`"passages":[{"text":"Go compiles\n quickly.","start":100,"end":140}]`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("JSON missing %s:\n%s", want, data)
+		}
+	}
+}
+
+func TestParseChatSourceFlags(t *testing.T) {
+	opts, pos, err := parseChatFlags("generate-chat", []string{
+		"nb", "q", "--sources", "a,b", "--sources", "c", "--source-type", "PDF", "--source-match", "RFC*",
+	})
+	if err != nil {
+		t.Fatalf("parseChatFlags error: %v", err)
+	}
+	if len(pos) != 2 || strings.Join(opts.Sources, ",") != "a,b,c" || !opts.scoped() {
+		t.Fatalf("got %+v %q", opts, pos)
+	}
+	filter, err := opts.sourceFilter()
+	if err != nil {
+		t.Fatalf("sourceFilter error: %v", err)
+	}
+	if filter.Match != "RFC*" || len(filter.Types) != 1 || filter.Types[0] != pb.SourceType_SOURCE_TYPE_LOCAL_FILE {
+		t.Errorf("filter = %+v", filter)
+	}
+
+	opts, _, _ = parseChatFlags("chat", []string{"nb"})
+	if opts.scoped() {
+		t.Error("chat without source flags should not be scoped")
+	}
+	if ids, err := chatSources(nil, "nb", nil, api.SourceFilter{}); ids != nil || err != nil {
+		t.Errorf("chatSources without selection = %v, %v; want all sources", ids, err)
+	}
+
+	if _, _, err := parseChatFlags("chat", []string{"nb", "--source-type", "podcast"}); err == nil {
+		t.Error("expected error for unknown source type")
+	}
+}
+
+func TestPrintChatSources(t *testing.T) {
+	sources := []*pb.Source{
+		{SourceId: &pb.SourceId{SourceId: "s1"}, Title: "RFC 9110"},
+		{SourceId: &pb.SourceId{SourceId: "s2"}, Title: "Notes"},
+	}
+
+	var buf bytes.Buffer
+	if err := printChatSources(&buf, sources, []string{"s2"}); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || strings.HasPrefix(lines[1], "*") || !strings.HasPrefix(lines[2], "*") {
+		t.Errorf("pinned s2:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	if err := printChatSources(&buf, sources, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "*"); got != 2 {
+		t.Errorf("nothing pinned marks %d sources, want 2:\n%s", got, buf.String())
+	}
+}
+
+func TestChatSessionPinnedSources(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	session := &ChatSession{NotebookID: "nb-pinned", SourceIDs: []string{"s1", "s2"}}
+	if err := saveChatSession(session); err != nil {
+		t.Fatalf("saveChatSession error: %v", err)
+	}
+	loaded, err := loadChatSession("nb-pinned")
+	if err != nil {
+		t.Fatalf("loadChatSession error: %v", err)
+	}
+	if strings.Join(loaded.SourceIDs, ",") != "s1,s2" {
+		t.Errorf("SourceIDs = %v", loaded.SourceIDs)
+	}
+}
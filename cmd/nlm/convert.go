@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+// NoteToSourceOptions contains the options for note-to-source.
+type NoteToSourceOptions struct {
+	All    bool
+	Delete bool
+	DryRun bool
+	Yes    bool
+}
+
+// parseNoteToSourceFlags parses
+// `note-to-source <notebook-id> [note-id...] [--all] [--delete] [--dry-run] [-y]`.
+func parseNoteToSourceFlags(args []string) (*NoteToSourceOptions, []string, error) {
+	opts := &NoteToSourceOptions{}
+	fs := newCommandFlags("note-to-source")
+	fs.BoolVar(&opts.All, "all", false, "convert every note in the notebook")
+	fs.BoolVar(&opts.Delete, "delete", false, "delete each note once its source has been added")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "show the selected notes without changing anything")
+	fs.BoolVar(&opts.Yes, "y", false, "skip the confirmation prompt")
+	fs.BoolVar(&opts.Yes, "yes", false, "skip the confirmation prompt")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.All && len(pos) > 1 {
+		return nil, nil, fmt.Errorf("--all cannot be combined with note IDs")
+	}
+	return opts, pos, nil
+}
+
+func validateNoteToSourceArgs(args []string) error {
+	opts, pos, err := parseNoteToSourceFlags(args)
+	if err == nil && (len(pos) >= 2 || (len(pos) == 1 && opts.All)) {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm note-to-source <notebook-id> <note-id...>|--all [--delete] [--dry-run] [-y]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// parseSourceToNoteFlags parses
+// `source-to-note <notebook-id> [source-id...] [--delete] [filters]`.
+func parseSourceToNoteFlags(args []string) (*BulkSourceOptions, []string, error) {
+	opts := &BulkSourceOptions{}
+	fs := newCommandFlags("source-to-note")
+	opts.register(fs)
+	fs.BoolVar(&opts.Delete, "delete", false, "delete each source once its note has been created")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := opts.Filter(); err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateSourceToNoteArgs(args []string) error {
+	opts, pos, err := parseSourceToNoteFlags(args)
+	if err == nil {
+		filter, _ := opts.Filter()
+		switch {
+		case len(pos) >= 2:
+			return nil
+		case len(pos) == 1 && !filter.IsZero():
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm source-to-note <notebook-id> [source-id...] [--match glob] [--type T] [--status S] [--older-than 30d] [--delete] [--dry-run] [-y]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// selectNotes returns the notes named by ids, in order, or every note when
+// all is set.
+func selectNotes(notes []*api.Note, ids []string, all bool) ([]*api.Note, error) {
+	if all {
+		return notes, nil
+	}
+	selected := make([]*api.Note, 0, len(ids))
+	for _, id := range ids {
+		n := findNote(notes, id)
+		if n == nil {
+			return nil, fmt.Errorf("note %s not found", id)
+		}
+		selected = append(selected, n)
+	}
+	return selected, nil
+}
+
+// confirmConversion asks once before converting n items when the
+// originals are going to be deleted. Conversions that keep the originals
+// are not destructive and proceed without a prompt.
+func confirmConversion(n int, what, into string, del, yes bool) error {
+	if !del || yes {
+		return nil
+	}
+	fmt.Printf("Convert %d %s to %s and delete the originals? [y/N] ", n, what, into)
+	var response string
+	_, _ = fmt.Scanln(&response)
+	if !strings.HasPrefix(strings.ToLower(response), "y") {
+		return fmt.Errorf("operation cancelled")
+	}
+	return nil
+}
+
+// conversion records one converted item for the summary table.
+type conversion struct {
+	from, to, title string
+}
+
+func printConversions(header string, conversions []conversion) error {
+	if len(conversions) == 0 {
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, header)
+	for _, cv := range conversions {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", cv.from, cv.to, cv.title)
+	}
+	return w.Flush()
+}
+
+// noteToSource implements note-to-source. Each note's content is added as
+// a text source so it takes part in chat grounding.
+func noteToSource(c *api.Client, args []string) error {
+	opts, pos, err := parseNoteToSourceFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+
+	notes, err := c.GetNotes(notebookID)
+	if err != nil {
+		return fmt.Errorf("convert notes: %w", err)
+	}
+	selected, err := selectNotes(notes, pos[1:], opts.All)
+	if err != nil {
+		return fmt.Errorf("convert notes: %w", err)
+	}
+	if len(selected) == 0 {
+		fmt.Fprintf(os.Stderr, "No notes found in this notebook.\n")
+		return nil
+	}
+	if opts.DryRun {
+		for _, n := range selected {
+			fmt.Printf("%s\t%s\n", noteIDOf(n), n.GetTitle())
+		}
+		fmt.Fprintf(os.Stderr, "Dry run: %d note(s) would be converted to sources.\n", len(selected))
+		return nil
+	}
+	if err := confirmConversion(len(selected), "note(s)", "sources", opts.Delete, opts.Yes); err != nil {
+		return err
+	}
+
+	var done []conversion
+	var failed int
+	for _, n := range selected {
+		id, title := noteIDOf(n), n.GetTitle()
+		if title == "" {
+			title = id
+		}
+		if strings.TrimSpace(n.Content) == "" {
+			failed++
+			fmt.Fprintf(os.Stderr, "nlm: note %s: note is empty\n", id)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Converting note %s...\n", id)
+		sourceID, err := c.AddSourceFromText(notebookID, n.Content, title)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "nlm: note %s: %v\n", id, err)
+			continue
+		}
+		done = append(done, conversion{from: id, to: sourceID, title: title})
+	}
+	return finishConversion(c, notebookID, opts.Delete, "NOTE ID\tSOURCE ID\tTITLE", done, failed, "note")
+}
+
+// sourceToNote implements source-to-note. Each source's extracted text is
+// saved as a new note.
+func sourceToNote(c *api.Client, args []string) error {
+	opts, pos, err := parseSourceToNoteFlags(args)
+	if err != nil {
+		return err
+	}
+	filter, err := opts.Filter()
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+
+	sources, err := selectSources(c, notebookID, pos[1:], filter)
+	if err != nil {
+		return fmt.Errorf("convert sources: %w", err)
+	}
+	if len(sources) == 0 {
+		fmt.Fprintf(os.Stderr, "No sources match.\n")
+		return nil
+	}
+	if opts.DryRun {
+		if err := printSourceTable(sources); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Dry run: %d source(s) would be converted to notes.\n", len(sources))
+		return nil
+	}
+	if err := confirmConversion(len(sources), "source(s)", "notes", opts.Delete, opts.Yes); err != nil {
+		return err
+	}
+
+	var done []conversion
+	var failed int
+	for _, src := range sources {
+		id := src.GetSourceId().GetSourceId()
+		fmt.Fprintf(os.Stderr, "Converting source %s...\n", id)
+		note, err := sourceAsNote(c, notebookID, src)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "nlm: source %s: %v\n", id, err)
+			continue
+		}
+		done = append(done, conversion{from: id, to: noteIDOf(note), title: note.GetTitle()})
+	}
+	return finishConversion(c, notebookID, opts.Delete, "SOURCE ID\tNOTE ID\tTITLE", done, failed, "source")
+}
+
+// sourceAsNote loads the extracted text of src and creates a note from it.
+func sourceAsNote(c *api.Client, notebookID string, src *pb.Source) (*api.Note, error) {
+	sc, err := c.LoadSourceContent(src.GetSourceId().GetSourceId())
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(sc.Text) == "" {
+		return nil, fmt.Errorf("source has no extracted text")
+	}
+	title := strings.TrimSpace(src.GetTitle())
+	if title == "" {
+		title = strings.TrimSpace(sc.Source.GetTitle())
+	}
+	return c.CreateNote(notebookID, title, sc.Text)
+}
+
+// finishConversion deletes the converted originals when requested, prints
+// the summary table and reports partial failures. Originals are only
+// deleted after their copy was created.
+func finishConversion(c *api.Client, notebookID string, del bool, header string, done []conversion, failed int, kind string) error {
+	if err := printConversions(header, done); err != nil {
+		return err
+	}
+	if del && len(done) > 0 {
+		ids := make([]string, len(done))
+		for i, cv := range done {
+			ids[i] = cv.from
+		}
+		var err error
+		if kind == "note" {
+			err = c.DeleteNotes(notebookID, ids)
+		} else {
+			err = c.DeleteSources(notebookID, ids)
+		}
+		if err != nil {
+			return fmt.Errorf("delete converted %ss: %w", kind, err)
+		}
+		fmt.Fprintf(os.Stderr, "Deleted %d original %s(s).\n", len(ids), kind)
+	}
+	if failed > 0 {
+		return fmt.Errorf("convert %ss: %d of %d failed", kind, failed, failed+len(done))
+	}
+	fmt.Fprintf(os.Stderr, "✅ Converted %d %s(s)\n", len(done), kind)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+func TestParseNoteToSourceFlags(t *testing.T) {
+	opts, pos, err := parseNoteToSourceFlags([]string{"nb", "--all", "--delete", "-y"})
+	if err != nil {
+		t.Fatalf("parseNoteToSourceFlags() error: %v", err)
+	}
+	if len(pos) != 1 || !opts.All || !opts.Delete || !opts.Yes {
+		t.Errorf("opts = %+v, pos = %q", opts, pos)
+	}
+	if _, _, err := parseNoteToSourceFlags([]string{"nb", "n1", "--all"}); err == nil {
+		t.Error("--all with note IDs should be rejected")
+	}
+}
+
+func TestParseSourceToNoteFlags(t *testing.T) {
+	opts, pos, err := parseSourceToNoteFlags([]string{"nb", "--type", "YOUTUBE_VIDEO", "--delete", "--dry-run"})
+	if err != nil {
+		t.Fatalf("parseSourceToNoteFlags() error: %v", err)
+	}
+	filter, _ := opts.Filter()
+	if len(pos) != 1 || !opts.Delete || !opts.DryRun || filter.IsZero() {
+		t.Errorf("opts = %+v, pos = %q", opts, pos)
+	}
+}
+
+func TestSelectNotes(t *testing.T) {
+	notes := []*api.Note{
+		testNote("a", "A", "", time.Time{}),
+		testNote("b", "B", "", time.Time{}),
+	}
+	got, err := selectNotes(notes, []string{"b", "a"}, false)
+	if err != nil {
+		t.Fatalf("selectNotes() error: %v", err)
+	}
+	if len(got) != 2 || noteIDOf(got[0]) != "b" || noteIDOf(got[1]) != "a" {
+		t.Errorf("selectNotes() = %v", got)
+	}
+	if got, _ := selectNotes(notes, nil, true); len(got) != 2 {
+		t.Errorf("selectNotes(all) returned %d notes", len(got))
+	}
+	if _, err := selectNotes(notes, []string{"missing"}, false); err == nil {
+		t.Error("selectNotes() with unknown ID succeeded")
+	}
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/crawl"
+)
+
+// Commands that add web pages found by crawling, sitemaps or feeds.
+const (
+	cmdAddCrawl   = "add-crawl"
+	cmdAddSitemap = "add-sitemap"
+	cmdAddFeed    = "add-feed"
+)
+
+const (
+	// defaultSourceLimit is how many sources a standard notebook holds.
+	defaultSourceLimit = 50
+	// webBatchSize is how many URLs go into one AddSources call.
+	webBatchSize = 10
+)
+
+// WebAddOptions contains the options for add-crawl, add-sitemap and
+// add-feed.
+type WebAddOptions struct {
+	Max    int
+	Limit  int
+	DryRun bool
+
+	// add-crawl only
+	Depth    int
+	SameHost bool
+
+	// add-feed only
+	Since string
+}
+
+// parseWebAddFlags parses the flags of cmd, one of the add-crawl,
+// add-sitemap and add-feed commands.
+func parseWebAddFlags(cmd string, args []string) (*WebAddOptions, []string, error) {
+	opts := &WebAddOptions{}
+	fs := newCommandFlags(cmd)
+	maxDefault := 0
+	if cmd == cmdAddCrawl {
+		maxDefault = 50
+		fs.IntVar(&opts.Depth, "depth", 1, "follow links this many steps from the start page")
+		fs.BoolVar(&opts.SameHost, "same-host", false, "only follow links to the start page's host")
+	}
+	if cmd == cmdAddFeed {
+		fs.StringVar(&opts.Since, "since", "", "only add entries published within this age, e.g. 7d, 2w, 12h")
+	}
+	fs.IntVar(&opts.Max, "max", maxDefault, "add at most this many pages (0 for no limit)")
+	fs.IntVar(&opts.Limit, "limit", defaultSourceLimit, "the notebook's source limit")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "list the pages that would be added without adding them")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Max < 0 || opts.Depth < 0 || opts.Limit < 1 {
+		return nil, nil, fmt.Errorf("--max and --depth must not be negative and --limit must be positive")
+	}
+	if opts.Since != "" {
+		if _, err := api.ParseAge(opts.Since); err != nil {
+			return nil, nil, err
+		}
+	}
+	return opts, pos, nil
+}
+
+func validateWebAddArgs(cmd string, args []string) error {
+	_, pos, err := parseWebAddFlags(cmd, args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	usage := map[string]string{
+		cmdAddCrawl:   "<notebook-id> <url> [--depth N] [--same-host] [--max N]",
+		cmdAddSitemap: "<notebook-id> <sitemap-url|file> [--max N]",
+		cmdAddFeed:    "<notebook-id> <feed-url> [--since 7d] [--max N]",
+	}[cmd]
+	fmt.Fprintf(os.Stderr, "usage: nlm %s %s [--limit N] [--dry-run]\n", cmd, usage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+func newCrawler() *crawl.Crawler {
+	cr := crawl.New(&crawl.HTTPFetcher{})
+	cr.Logger = logger
+	return cr
+}
+
+// addWeb implements add-crawl, add-sitemap and add-feed: it finds URLs
+// locally, then adds those not yet in the notebook.
+func addWeb(c *api.Client, cmd string, args []string) error {
+	opts, pos, err := parseWebAddFlags(cmd, args)
+	if err != nil {
+		return err
+	}
+	notebookID, target := pos[0], pos[1]
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cr := newCrawler()
+	var urls []string
+	switch cmd {
+	case cmdAddCrawl:
+		fmt.Fprintf(os.Stderr, "Crawling %s (depth %d)...\n", target, opts.Depth)
+		// Crawl up to twice --max pages so that those already in the notebook
+		// do not use up the budget.
+		limit := 0
+		if opts.Max > 0 {
+			limit = opts.Max * 2
+		}
+		urls, err = cr.Crawl(ctx, target, crawl.Options{Depth: opts.Depth, SameHost: opts.SameHost, Max: limit})
+	case cmdAddSitemap:
+		var entries []crawl.Entry
+		if strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://") {
+			entries, err = cr.Sitemap(ctx, target)
+		} else {
+			var data []byte
+			if data, err = os.ReadFile(target); err == nil {
+				entries, err = cr.ExpandSitemap(ctx, data)
+			}
+		}
+		urls = entryURLs(entries)
+	case cmdAddFeed:
+		var since time.Time
+		if opts.Since != "" {
+			age, _ := api.ParseAge(opts.Since)
+			since = time.Now().Add(-age)
+		}
+		var entries []crawl.Entry
+		entries, err = cr.Feed(ctx, target, since)
+		urls = entryURLs(entries)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd, err)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d page(s).\n", len(urls))
+	return addWebURLs(c, notebookID, urls, opts)
+}
+
+func entryURLs(entries []crawl.Entry) []string {
+	urls := make([]string, len(entries))
+	for i, e := range entries {
+		urls[i] = e.URL
+	}
+	return urls
+}
+
+// newWebURLs returns urls without duplicates and without those already in
+// the notebook, keeping their order.
+func newWebURLs(urls []string, existing map[string]string) []string {
+	seen := map[string]bool{}
+	for u := range existing {
+		seen[normalizeURL(u)] = true
+	}
+	var fresh []string
+	for _, u := range urls {
+		key := normalizeURL(crawl.Normalize(u))
+		if !seen[key] {
+			seen[key] = true
+			fresh = append(fresh, u)
+		}
+	}
+	return fresh
+}
+
+// addWebURLs adds the new URLs in batches of webBatchSize, stopping at
+// --max and at the notebook's source limit.
+func addWebURLs(c *api.Client, notebookID string, urls []string, opts *WebAddOptions) error {
+	p, err := c.GetProject(notebookID)
+	if err != nil {
+		return fmt.Errorf("get notebook: %w", err)
+	}
+	existing, err := c.SourceURLs(notebookID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: could not check existing sources, duplicates may be added: %v\n", err)
+	}
+	fresh := newWebURLs(urls, existing)
+	if skipped := len(urls) - len(fresh); skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d page(s) already in the notebook or listed twice.\n", skipped)
+	}
+	if opts.Max > 0 && len(fresh) > opts.Max {
+		fresh = fresh[:opts.Max]
+	}
+	if len(fresh) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to add.")
+		return nil
+	}
+	have := len(p.GetSources())
+	room := opts.Limit - have
+	if room <= 0 {
+		return fmt.Errorf("notebook already has %d sources (limit %d)", have, opts.Limit)
+	}
+	if len(fresh) > room {
+		fmt.Fprintf(os.Stderr, "Notebook has %d of %d sources; adding only the first %d of %d page(s).\n", have, opts.Limit, room, len(fresh))
+		fresh = fresh[:room]
+	}
+
+	if opts.DryRun {
+		for _, u := range fresh {
+			fmt.Println(u)
+		}
+		fmt.Fprintf(os.Stderr, "Would add %d page(s).\n", len(fresh))
+		return nil
+	}
+	added := 0
+	for start := 0; start < len(fresh); start += webBatchSize {
+		batch := fresh[start:min(start+webBatchSize, len(fresh))]
+		fmt.Fprintf(os.Stderr, "Adding %d-%d of %d...\n", start+1, start+len(batch), len(fresh))
+		ids, err := c.AddURLSources(notebookID, batch)
+		if err != nil {
+			return fmt.Errorf("add pages (added %d of %d): %w", added, len(fresh), err)
+		}
+		added += len(batch)
+		for _, id := range ids {
+			fmt.Println(id)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "✅ Added %d page(s)\n", added)
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWebAddFlags(t *testing.T) {
+	tests := []struct {
+		cmd     string
+		args    []string
+		want    WebAddOptions
+		wantErr bool
+	}{
+		{cmdAddCrawl, []string{"nb", "https://go.dev"}, WebAddOptions{Depth: 1, Max: 50, Limit: defaultSourceLimit}, false},
+		{cmdAddCrawl, []string{"nb", "https://go.dev", "--depth", "2", "--same-host", "--max", "20"}, WebAddOptions{Depth: 2, SameHost: true, Max: 20, Limit: defaultSourceLimit}, false},
+		{cmdAddSitemap, []string{"nb", "sitemap.xml", "--limit", "300", "--dry-run"}, WebAddOptions{Limit: 300, DryRun: true}, false},
+		{cmdAddFeed, []string{"nb", "https://go.dev/blog/feed.atom", "--since", "7d"}, WebAddOptions{Since: "7d", Limit: defaultSourceLimit}, false},
+		{cmdAddFeed, []string{"nb", "u", "--since", "soon"}, WebAddOptions{}, true},
+		{cmdAddSitemap, []string{"nb", "u", "--depth", "2"}, WebAddOptions{}, true},
+		{cmdAddCrawl, []string{"nb", "u", "--max", "-1"}, WebAddOptions{}, true},
+		{cmdAddCrawl, []string{"nb", "u", "--limit", "0"}, WebAddOptions{}, true},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseWebAddFlags(tt.cmd, tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseWebAddFlags(%s, %v) error = %v", tt.cmd, tt.args, err)
+			continue
+		}
+		if err == nil && *opts != tt.want {
+			t.Errorf("parseWebAddFlags(%s, %v) = %+v, want %+v", tt.cmd, tt.args, *opts, tt.want)
+		}
+	}
+}
+
+func TestNewWebURLs(t *testing.T) {
+	existing := map[string]string{"https://go.dev/doc/": "src-1"}
+	urls := []string{
+		"https://go.dev/doc",
+		"https://go.dev/blog",
+		"http://GO.dev/blog#intro",
+		"https://go.dev/ref/spec",
+	}
+	got := newWebURLs(urls, existing)
+	want := []string{"https://go.dev/blog", "https://go.dev/ref/spec"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("newWebURLs = %v, want %v", got, want)
+	}
+	if got := newWebURLs(urls[:1], nil); len(got) != 1 {
+		t.Errorf("newWebURLs without existing sources = %v", got)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+// DiscoverOptions contains the options for discover.
+type DiscoverOptions struct {
+	Interactive bool
+	ImportTop   int
+	JSON        bool
+}
+
+// parseDiscoverFlags parses
+// `discover <notebook-id> <query> [--interactive | --import-top N] [--json]`.
+func parseDiscoverFlags(args []string) (*DiscoverOptions, []string, error) {
+	opts := &DiscoverOptions{}
+	fs := newCommandFlags("discover")
+	fs.BoolVar(&opts.Interactive, "interactive", false, "choose which discovered sources to add")
+	fs.BoolVar(&opts.Interactive, "i", false, "choose which discovered sources to add")
+	fs.IntVar(&opts.ImportTop, "import-top", 0, "add the N best discovered sources not already in the notebook")
+	fs.BoolVar(&opts.JSON, "json", false, "list the discovered sources as JSON")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.ImportTop < 0 {
+		return nil, nil, fmt.Errorf("--import-top must not be negative")
+	}
+	if opts.Interactive && opts.ImportTop > 0 {
+		return nil, nil, fmt.Errorf("--interactive and --import-top cannot be used together")
+	}
+	if opts.JSON && opts.Interactive {
+		return nil, nil, fmt.Errorf("--json and --interactive cannot be used together")
+	}
+	return opts, pos, nil
+}
+
+func validateDiscoverArgs(args []string) error {
+	_, pos, err := parseDiscoverFlags(args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm discover <notebook-id> <query> [--interactive | --import-top N] [--json]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// discoveredSource is a discovery result as shown to the user.
+type discoveredSource struct {
+	Rank int `json:"rank"`
+	api.DiscoveredSource
+	InNotebook bool `json:"in_notebook"`
+}
+
+// discover implements discover and discover-sources: it lists what
+// DiscoverSources suggests for the query and optionally adds a selection
+// of it to the notebook in one AddSources call.
+func discover(c *api.Client, args []string) error {
+	opts, pos, err := parseDiscoverFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID, query := pos[0], pos[1]
+
+	fmt.Fprintf(os.Stderr, "Discovering sources for query: %s\n", query)
+	found, err := c.DiscoverSources(notebookID, query)
+	if err != nil {
+		return err
+	}
+	if len(found) == 0 {
+		if opts.JSON {
+			fmt.Println("[]")
+			return nil
+		}
+		fmt.Println("No sources found for the query.")
+		return nil
+	}
+
+	existing, err := c.SourceURLs(notebookID)
+	if err != nil {
+		// Without the list every result looks new; the server may then
+		// add duplicates, which is annoying but harmless.
+		fmt.Fprintf(os.Stderr, "nlm: could not check existing sources: %v\n", err)
+		existing = map[string]string{}
+	}
+	results := markExisting(found, existing)
+
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(results); err != nil {
+			return err
+		}
+	} else if err := printDiscovered(os.Stdout, results); err != nil {
+		return err
+	}
+
+	var selected []discoveredSource
+	switch {
+	case opts.ImportTop > 0:
+		selected = topNew(results, opts.ImportTop)
+	case opts.Interactive:
+		fmt.Print("\nAdd which sources? (e.g. 1,3-5, all; empty for none) ")
+		line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		ranks, err := parseSelection(line, len(results))
+		if err != nil {
+			return err
+		}
+		for _, r := range ranks {
+			selected = append(selected, results[r-1])
+		}
+	default:
+		return nil
+	}
+	// Keep stdout a single JSON document; the new IDs go to stderr.
+	idOut := io.Writer(os.Stdout)
+	if opts.JSON {
+		idOut = os.Stderr
+	}
+	return importDiscovered(c, notebookID, selected, idOut)
+}
+
+// markExisting numbers the results and flags those whose URL is already a
+// source of the notebook.
+func markExisting(found []api.DiscoveredSource, existing map[string]string) []discoveredSource {
+	have := map[string]bool{}
+	for u := range existing {
+		have[normalizeURL(u)] = true
+	}
+	results := make([]discoveredSource, len(found))
+	for i, f := range found {
+		results[i] = discoveredSource{Rank: i + 1, DiscoveredSource: f, InNotebook: have[normalizeURL(f.URL)]}
+	}
+	return results
+}
+
+// normalizeURL makes URLs that differ only in scheme, a trailing slash or
+// a fragment compare equal.
+func normalizeURL(u string) string {
+	u, _, _ = strings.Cut(u, "#")
+	u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+	return strings.TrimSuffix(u, "/")
+}
+
+func printDiscovered(out io.Writer, results []discoveredSource) error {
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "#\tTITLE\tTYPE\tURL\tSTATUS")
+	for _, r := range results {
+		status := ""
+		if r.InNotebook {
+			status = "in notebook"
+		}
+		title := r.Title
+		if title == "" {
+			title = untitledTitle
+		}
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", r.Rank, truncateRunes(title, 60), r.Type, r.URL, status)
+	}
+	return w.Flush()
+}
+
+// topNew returns the n best ranked results that are not in the notebook.
+func topNew(results []discoveredSource, n int) []discoveredSource {
+	var top []discoveredSource
+	for _, r := range results {
+		if len(top) == n {
+			break
+		}
+		if !r.InNotebook {
+			top = append(top, r)
+		}
+	}
+	return top
+}
+
+// parseSelection parses a list of ranks between 1 and n such as "1,3-5"
+// or "all". It returns them sorted without duplicates; an empty selection
+// selects nothing.
+func parseSelection(s string, n int) ([]int, error) {
+	s = strings.TrimSpace(s)
+	if strings.EqualFold(s, "all") {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i + 1
+		}
+		return all, nil
+	}
+	picked := map[int]bool{}
+	for _, field := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' }) {
+		lo, hi, isRange := strings.Cut(field, "-")
+		from, err := strconv.Atoi(lo)
+		to := from
+		if err == nil && isRange {
+			to, err = strconv.Atoi(hi)
+		}
+		if err != nil || from < 1 || to > n || from > to {
+			return nil, fmt.Errorf("invalid selection %q: use numbers between 1 and %d", field, n)
+		}
+		for i := from; i <= to; i++ {
+			picked[i] = true
+		}
+	}
+	ranks := make([]int, 0, len(picked))
+	for r := range picked {
+		ranks = append(ranks, r)
+	}
+	sort.Ints(ranks)
+	return ranks, nil
+}
+
+// importDiscovered adds the selected results that are not yet in the
+// notebook and writes the IDs of the new sources to w.
+func importDiscovered(c *api.Client, notebookID string, selected []discoveredSource, w io.Writer) error {
+	var urls []string
+	for _, r := range selected {
+		if r.InNotebook {
+			fmt.Fprintf(os.Stderr, "Skipping %d (already in notebook): %s\n", r.Rank, r.URL)
+			continue
+		}
+		urls = append(urls, r.URL)
+	}
+	if len(urls) == 0 {
+		fmt.Fprintln(os.Stderr, "Nothing to add.")
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Adding %d source(s)...\n", len(urls))
+	ids, err := c.AddURLSources(notebookID, urls)
+	if err != nil {
+		return fmt.Errorf("import discovered sources: %w", err)
+	}
+	for _, id := range ids {
+		fmt.Fprintln(w, id)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Added %d source(s)\n", len(urls))
+	return nil
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+func TestParseDiscoverFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    DiscoverOptions
+		wantErr bool
+	}{
+		{[]string{"nb", "go memory"}, DiscoverOptions{}, false},
+		{[]string{"nb", "go memory", "--import-top", "3"}, DiscoverOptions{ImportTop: 3}, false},
+		{[]string{"nb", "-i", "go memory"}, DiscoverOptions{Interactive: true}, false},
+		{[]string{"nb", "q", "--interactive", "--import-top", "2"}, DiscoverOptions{}, true},
+		{[]string{"nb", "q", "--import-top", "-1"}, DiscoverOptions{}, true},
+		{[]string{"nb", "q", "--json", "-i"}, DiscoverOptions{}, true},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseDiscoverFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDiscoverFlags(%v) error = %v", tt.args, err)
+			continue
+		}
+		if err == nil && *opts != tt.want {
+			t.Errorf("parseDiscoverFlags(%v) = %+v, want %+v", tt.args, *opts, tt.want)
+		}
+	}
+}
+
+func TestParseSelection(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []int
+		wantErr bool
+	}{
+		{"", []int{}, false},
+		{"all\n", []int{1, 2, 3, 4, 5}, false},
+		{"3,1", []int{1, 3}, false},
+		{"2-4, 3 5", []int{2, 3, 4, 5}, false},
+		{"0", nil, true},
+		{"6", nil, true},
+		{"4-2", nil, true},
+		{"x", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := parseSelection(tt.in, 5)
+		if (err != nil) != tt.wantErr || (err == nil && !reflect.DeepEqual(got, tt.want)) {
+			t.Errorf("parseSelection(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func discoveredFixture() []discoveredSource {
+	return markExisting([]api.DiscoveredSource{
+		{Title: "Go Memory Model", URL: "https://go.dev/ref/mem", Type: pb.SourceType_SOURCE_TYPE_WEB_PAGE},
+		{Title: "Effective Go", URL: "https://go.dev/doc/effective_go", Type: pb.SourceType_SOURCE_TYPE_WEB_PAGE},
+		{Title: "Channels", URL: "https://www.youtube.com/watch?v=abc", Type: pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO},
+	}, map[string]string{"http://go.dev/ref/mem/": "src-1"})
+}
+
+func TestMarkExistingAndTopNew(t *testing.T) {
+	results := discoveredFixture()
+	if !results[0].InNotebook || results[1].InNotebook || results[2].Rank != 3 {
+		t.Fatalf("markExisting = %+v", results)
+	}
+	top := topNew(results, 1)
+	if len(top) != 1 || top[0].Rank != 2 {
+		t.Errorf("topNew(1) = %+v", top)
+	}
+	if top := topNew(results, 10); len(top) != 2 {
+		t.Errorf("topNew(10) returned %d results, want 2", len(top))
+	}
+}
+
+func TestPrintDiscovered(t *testing.T) {
+	var b strings.Builder
+	if err := printDiscovered(&b, discoveredFixture()); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{"#", "TITLE", "TYPE", "URL", "STATUS", "SOURCE_TYPE_YOUTUBE_VIDEO", "in notebook"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "RELEVANCE") {
+		t.Errorf("output still has a RELEVANCE column:\n%s", out)
+	}
+}
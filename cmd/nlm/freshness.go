@@ -0,0 +1,278 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/drive"
+)
+
+// FreshnessOptions contains the options for freshness.
+type FreshnessOptions struct {
+	All         bool
+	Refresh     bool
+	Since       string
+	Concurrency int
+}
+
+// parseFreshnessFlags parses `freshness <notebook-id>|--all [--refresh]
+// [--since 24h] [--concurrency N]`.
+func parseFreshnessFlags(args []string) (*FreshnessOptions, []string, error) {
+	opts := &FreshnessOptions{}
+	fs := newCommandFlags("freshness")
+	fs.BoolVar(&opts.All, "all", false, "check the sources of every notebook")
+	fs.BoolVar(&opts.Refresh, "refresh", false, "refresh the sources found stale")
+	fs.StringVar(&opts.Since, "since", "", "skip sources synced within this age, e.g. 24h or 7d")
+	fs.IntVar(&opts.Concurrency, "concurrency", 8, "check this many sources at once")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Concurrency < 1 {
+		return nil, nil, fmt.Errorf("--concurrency must be positive")
+	}
+	if opts.Since != "" {
+		if _, err := api.ParseAge(opts.Since); err != nil {
+			return nil, nil, err
+		}
+	}
+	return opts, pos, nil
+}
+
+func validateFreshnessArgs(args []string) error {
+	opts, pos, err := parseFreshnessFlags(args)
+	if err == nil {
+		switch {
+		case opts.All && len(pos) != 0:
+			err = fmt.Errorf("--all checks every notebook; do not name one")
+		case !opts.All && len(pos) == 1, opts.All:
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm freshness <notebook-id>|--all [--refresh] [--since 24h] [--concurrency N]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// isRefreshable reports whether NotebookLM can re-sync a source of type t
+// from where it came from: Google Drive files, web pages and YouTube videos.
+func isRefreshable(t pb.SourceType) bool {
+	return drive.IsSourceType(t) ||
+		t == pb.SourceType_SOURCE_TYPE_WEB_PAGE ||
+		t == pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO
+}
+
+// freshnessTarget is a source to check, with the notebook it is in.
+type freshnessTarget struct {
+	NotebookID string
+	Notebook   string // title
+	Source     *pb.Source
+}
+
+// freshnessResult is the outcome of checking, and perhaps refreshing, one
+// source.
+type freshnessResult struct {
+	freshnessTarget
+	Fresh       bool
+	LastChecked time.Time
+	Err         error
+
+	Refreshed  bool
+	RefreshErr error
+}
+
+// freshnessTargets returns the refreshable sources of the notebooks,
+// leaving out those synced within since of now.
+func freshnessTargets(notebooks []*pb.Project, since time.Duration, now time.Time) []freshnessTarget {
+	var targets []freshnessTarget
+	for _, nb := range notebooks {
+		for _, src := range nb.GetSources() {
+			if !isRefreshable(src.GetMetadata().GetSourceType()) {
+				continue
+			}
+			if since > 0 {
+				if synced, ok := api.SourceModifiedTime(src); ok && now.Sub(synced) < since {
+					continue
+				}
+			}
+			targets = append(targets, freshnessTarget{
+				NotebookID: nb.GetProjectId(),
+				Notebook:   strings.TrimSpace(nb.GetTitle()),
+				Source:     src,
+			})
+		}
+	}
+	return targets
+}
+
+// freshnessClient is the subset of the API freshness uses.
+// *api.Client satisfies it.
+type freshnessClient interface {
+	CheckSourceFreshness(sourceID string) (*pb.CheckSourceFreshnessResponse, error)
+	RefreshSource(sourceID string) (*pb.Source, error)
+}
+
+// checkFreshness checks the targets, n at a time, and refreshes those
+// that are stale if refresh is set. Results are in the order of targets.
+func checkFreshness(c freshnessClient, targets []freshnessTarget, n int, refresh bool) []freshnessResult {
+	results := make([]freshnessResult, len(targets))
+	work := make(chan int)
+	var wg sync.WaitGroup
+	for range min(n, len(targets)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				results[i] = checkOne(c, targets[i], refresh)
+			}
+		}()
+	}
+	for i := range targets {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+	return results
+}
+
+func checkOne(c freshnessClient, t freshnessTarget, refresh bool) freshnessResult {
+	r := freshnessResult{freshnessTarget: t}
+	id := t.Source.GetSourceId().GetSourceId()
+	resp, err := c.CheckSourceFreshness(id)
+	if err != nil {
+		r.Err = err
+		return r
+	}
+	r.Fresh = resp.GetIsFresh()
+	if ts := resp.GetLastChecked(); ts != nil {
+		r.LastChecked = ts.AsTime()
+	}
+	if refresh && !r.Fresh {
+		_, r.RefreshErr = c.RefreshSource(id)
+		r.Refreshed = r.RefreshErr == nil
+	}
+	return r
+}
+
+// freshness implements the freshness command: it checks the refreshable
+// sources of one notebook, or of all of them, and reports the stale ones.
+func freshness(c *api.Client, args []string) error {
+	opts, pos, err := parseFreshnessFlags(args)
+	if err != nil {
+		return err
+	}
+	var since time.Duration
+	if opts.Since != "" {
+		since, _ = api.ParseAge(opts.Since)
+	}
+
+	var notebooks []*pb.Project
+	if opts.All {
+		list, err := c.ListRecentlyViewedProjects()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Loading %d notebooks...\n", len(list))
+		for _, nb := range list {
+			p, err := c.GetProject(nb.GetProjectId())
+			if err != nil {
+				return fmt.Errorf("get notebook %s: %w", nb.GetProjectId(), err)
+			}
+			notebooks = append(notebooks, p)
+		}
+	} else {
+		p, err := c.GetProject(pos[0])
+		if err != nil {
+			return fmt.Errorf("get notebook: %w", err)
+		}
+		notebooks = append(notebooks, p)
+	}
+
+	targets := freshnessTargets(notebooks, since, time.Now())
+	if len(targets) == 0 {
+		fmt.Fprintf(os.Stderr, "No Drive, web or YouTube sources to check.\n")
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "Checking %d sources in %d notebooks...\n", len(targets), len(notebooks))
+	results := checkFreshness(c, targets, opts.Concurrency, opts.Refresh)
+	return printFreshnessReport(results, opts.Refresh)
+}
+
+// printFreshnessReport lists the sources that are stale or could not be
+// checked, then a summary. It fails if any check or refresh failed, so
+// that scheduled jobs notice.
+func printFreshnessReport(results []freshnessResult, refresh bool) error {
+	var stale, failed, refreshed int
+	var rows []freshnessResult
+	for _, r := range results {
+		switch {
+		case r.Err != nil:
+			failed++
+		case !r.Fresh:
+			stale++
+		default:
+			continue
+		}
+		if r.Refreshed {
+			refreshed++
+		}
+		if r.RefreshErr != nil {
+			failed++
+		}
+		rows = append(rows, r)
+	}
+
+	if len(rows) == 0 {
+		fmt.Printf("✅ All %d sources are fresh.\n", len(results))
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "NOTEBOOK\tSOURCE ID\tTITLE\tTYPE\tSTATE\tLAST CHECKED")
+	for _, r := range rows {
+		lastChecked := unknownValue
+		if !r.LastChecked.IsZero() {
+			lastChecked = r.LastChecked.Format(time.RFC3339)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			r.Notebook,
+			r.Source.GetSourceId().GetSourceId(),
+			strings.TrimSpace(r.Source.GetTitle()),
+			strings.TrimPrefix(r.Source.GetMetadata().GetSourceType().String(), "SOURCE_TYPE_"),
+			freshnessState(r),
+			lastChecked,
+		)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("%d of %d sources stale", stale, len(results))
+	if refresh {
+		summary += fmt.Sprintf(", %d refreshed", refreshed)
+	}
+	fmt.Fprintf(os.Stderr, "%s.\n", summary)
+	if failed > 0 {
+		return fmt.Errorf("%d checks or refreshes failed", failed)
+	}
+	return nil
+}
+
+func freshnessState(r freshnessResult) string {
+	switch {
+	case r.Err != nil:
+		return "error: " + r.Err.Error()
+	case r.Refreshed:
+		return "refreshed"
+	case r.RefreshErr != nil:
+		return "stale, refresh failed: " + r.RefreshErr.Error()
+	}
+	return "stale"
+}
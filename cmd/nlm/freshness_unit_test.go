@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestParseFreshnessFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    FreshnessOptions
+		wantErr bool
+	}{
+		{[]string{"nb"}, FreshnessOptions{Concurrency: 8}, false},
+		{[]string{"--all", "--refresh", "--since", "24h", "--concurrency", "2"}, FreshnessOptions{All: true, Refresh: true, Since: "24h", Concurrency: 2}, false},
+		{[]string{"nb", "--since", "soon"}, FreshnessOptions{}, true},
+		{[]string{"nb", "--concurrency", "0"}, FreshnessOptions{}, true},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseFreshnessFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseFreshnessFlags(%v) error = %v", tt.args, err)
+			continue
+		}
+		if err == nil && *opts != tt.want {
+			t.Errorf("parseFreshnessFlags(%v) = %+v, want %+v", tt.args, *opts, tt.want)
+		}
+	}
+}
+
+func testSource(id string, typ pb.SourceType, modified time.Time) *pb.Source {
+	return &pb.Source{
+		SourceId: &pb.SourceId{SourceId: id},
+		Title:    "Source " + id,
+		Metadata: &pb.SourceMetadata{SourceType: typ, LastModifiedTime: timestamppb.New(modified)},
+	}
+}
+
+func TestFreshnessTargets(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	notebooks := []*pb.Project{
+		{ProjectId: "nb1", Title: "Specs", Sources: []*pb.Source{
+			testSource("doc", pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS, now.Add(-48*time.Hour)),
+			testSource("pdf", pb.SourceType_SOURCE_TYPE_LOCAL_FILE, now.Add(-48*time.Hour)),
+			testSource("web", pb.SourceType_SOURCE_TYPE_WEB_PAGE, now.Add(-time.Hour)),
+		}},
+		{ProjectId: "nb2", Title: "Talks", Sources: []*pb.Source{
+			testSource("yt", pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO, now.Add(-72*time.Hour)),
+			testSource("text", pb.SourceType_SOURCE_TYPE_TEXT, now.Add(-72*time.Hour)),
+		}},
+	}
+	ids := func(targets []freshnessTarget) []string {
+		var out []string
+		for _, tg := range targets {
+			out = append(out, tg.NotebookID+"/"+tg.Source.GetSourceId().GetSourceId())
+		}
+		return out
+	}
+	if got, want := ids(freshnessTargets(notebooks, 0, now)), []string{"nb1/doc", "nb1/web", "nb2/yt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("freshnessTargets = %v, want %v", got, want)
+	}
+	if got, want := ids(freshnessTargets(notebooks, 24*time.Hour, now)), []string{"nb1/doc", "nb2/yt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("freshnessTargets(since 24h) = %v, want %v", got, want)
+	}
+}
+
+type fakeFreshnessClient struct {
+	mu        sync.Mutex
+	stale     map[string]bool
+	fail      map[string]bool
+	refreshed []string
+}
+
+func (c *fakeFreshnessClient) CheckSourceFreshness(id string) (*pb.CheckSourceFreshnessResponse, error) {
+	if c.fail[id] {
+		return nil, errors.New("check source freshness: not found")
+	}
+	return &pb.CheckSourceFreshnessResponse{IsFresh: !c.stale[id], LastChecked: timestamppb.New(time.Unix(0, 0))}, nil
+}
+
+func (c *fakeFreshnessClient) RefreshSource(id string) (*pb.Source, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshed = append(c.refreshed, id)
+	return &pb.Source{}, nil
+}
+
+func TestCheckFreshness(t *testing.T) {
+	var targets []freshnessTarget
+	for _, id := range []string{"a", "b", "c", "d", "e"} {
+		targets = append(targets, freshnessTarget{NotebookID: "nb", Source: testSource(id, pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS, time.Time{})})
+	}
+	c := &fakeFreshnessClient{stale: map[string]bool{"b": true, "d": true}, fail: map[string]bool{"e": true}}
+
+	results := checkFreshness(c, targets, 3, false)
+	var states []string
+	for _, r := range results {
+		if r.Err != nil || !r.Fresh {
+			states = append(states, r.Source.GetSourceId().GetSourceId()+":"+freshnessState(r))
+		}
+	}
+	want := []string{"b:stale", "d:stale", "e:error: check source freshness: not found"}
+	if !reflect.DeepEqual(states, want) {
+		t.Errorf("stale results = %q, want %q", states, want)
+	}
+	if len(c.refreshed) != 0 {
+		t.Errorf("refreshed %v without --refresh", c.refreshed)
+	}
+
+	results = checkFreshness(c, targets, 8, true)
+	if len(c.refreshed) != 2 || !results[1].Refreshed || !results[3].Refreshed || results[0].Refreshed {
+		t.Errorf("refresh: refreshed %v, results %+v", c.refreshed, results)
+	}
+}
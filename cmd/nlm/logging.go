@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/tmc/nlm/internal/batchexecute"
+	"github.com/tmc/nlm/internal/rpc"
+)
+
+// newLogger builds the structured logger selected by -log-level.
+// It returns nil when no level is set so that clients fall back to their
+// defaults (stderr with -debug, discarded otherwise).
+func newLogger(level string) (*slog.Logger, error) {
+	if level == "" {
+		return nil, nil
+	}
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		return nil, fmt.Errorf("invalid log level %q (want debug, info, warn or error)", level)
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: l})), nil
+}
+
+// rpcOptions returns the client options shared by every RPC client the CLI
+// constructs, including those built outside api.Client.
+func rpcOptions() []batchexecute.Option {
+	var (
+		opts         []batchexecute.Option
+		interceptors []rpc.Interceptor
+	)
+	if logger != nil {
+		opts = append(opts, batchexecute.WithLogger(logger))
+		interceptors = append(interceptors, rpc.LoggingInterceptor(logger))
+	}
+	if rpcTiming {
+		interceptors = append(interceptors, rpc.TimingInterceptor(printRPCTiming))
+	}
+	if rpcDumpDir != "" {
+		interceptors = append(interceptors, rpc.DumpInterceptor(rpcDumpDir))
+	}
+	if len(interceptors) > 0 {
+		opts = append(opts, rpc.WithInterceptors(interceptors...))
+	}
+	return opts
+}
+
+func printRPCTiming(call rpc.Call, d time.Duration, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	fmt.Fprintf(os.Stderr, "nlm: rpc %s %s in %v\n", call.ID, status, d.Round(time.Millisecond))
+}
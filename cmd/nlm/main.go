@@ -7,6 +7,8 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -19,6 +21,7 @@ import (
 	"github.com/tmc/nlm/internal/auth"
 	"github.com/tmc/nlm/internal/batchexecute"
 	"github.com/tmc/nlm/internal/beprotojson"
+	"github.com/tmc/nlm/internal/drive"
 	"github.com/tmc/nlm/internal/rpc"
 )
 
@@ -35,11 +38,16 @@ var (
 	chunkedResponse   bool // Control rt=c parameter for chunked vs JSON array response
 	useDirectRPC      bool // Use direct RPC calls instead of orchestration service
 	skipSources       bool // Skip fetching sources for chat (useful when project is inaccessible)
+	logLevel          string
+	rpcDumpDir        string       // Directory to dump RPC requests and responses into
+	rpcTiming         bool         // Print per-RPC timing to stderr
+	logger            *slog.Logger // Structured logger from -log-level; nil uses client defaults
 )
 
 const (
 	cmdArtifacts  = "artifacts"
 	cmdChatList   = "chat-list"
+	cmdChatExport = "chat-export"
 	cmdRefresh    = "refresh"
 	unknownValue  = "unknown"
 	untitledTitle = "(untitled)"
@@ -47,7 +55,12 @@ const (
 
 // ChatSession represents a persistent chat conversation
 type ChatSession struct {
+	Version    int           `json:"version"` // chatSessionVersion when saved
 	NotebookID string        `json:"notebook_id"`
+	Name       string        `json:"name,omitempty"`        // empty for the notebook's default session
+	ForkedFrom string        `json:"forked_from,omitempty"` // session this one was forked from
+	SourceIDs  []string      `json:"source_ids,omitempty"`  // pinned sources; empty means all
+	Summary    *ChatSummary  `json:"summary,omitempty"`     // rolling summary of older turns
 	Messages   []ChatMessage `json:"messages"`
 	CreatedAt  time.Time     `json:"created_at"`
 	UpdatedAt  time.Time     `json:"updated_at"`
@@ -55,9 +68,11 @@ type ChatSession struct {
 
 // ChatMessage represents a single message in the conversation
 type ChatMessage struct {
-	Role      string    `json:"role"` // "user" or "assistant"
-	Content   string    `json:"content"`
-	Timestamp time.Time `json:"timestamp"`
+	Role      string             `json:"role"` // "user" or "assistant"
+	Content   string             `json:"content"`
+	Citations []api.ChatCitation `json:"citations,omitempty"`
+	Fallback  bool               `json:"fallback,omitempty"` // canned reply given when the chat call failed
+	Timestamp time.Time          `json:"timestamp"`
 }
 
 func init() {
@@ -72,6 +87,9 @@ func init() {
 	flag.StringVar(&authToken, "auth", os.Getenv("NLM_AUTH_TOKEN"), "auth token (or set NLM_AUTH_TOKEN)")
 	flag.StringVar(&cookies, "cookies", os.Getenv("NLM_COOKIES"), "cookies for authentication (or set NLM_COOKIES)")
 	flag.StringVar(&mimeType, "mime", "", "specify MIME type for content (e.g. 'text/xml', 'application/json')")
+	flag.StringVar(&rpcDumpDir, "rpc-dump", "", "write every RPC request and response as JSON files into this directory")
+	flag.BoolVar(&rpcTiming, "rpc-timing", false, "print the duration of each RPC call to stderr")
+	flag.StringVar(&logLevel, "log-level", os.Getenv("NLM_LOG_LEVEL"), "structured log level for requests: debug, info, warn, error (or set NLM_LOG_LEVEL)")
 
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: nlm <command> [arguments]\n\n")
@@ -84,22 +102,37 @@ func init() {
 
 		fmt.Fprintf(os.Stderr, "Source Commands:\n")
 		fmt.Fprintf(os.Stderr, "  sources <id>      List sources in notebook\n")
-		fmt.Fprintf(os.Stderr, "  add <id> <input>  Add source to notebook\n")
+		fmt.Fprintf(os.Stderr, "  add <id> <input>  Add source to notebook (a YouTube playlist or channel adds each video)\n")
 		fmt.Fprintf(os.Stderr, "  rm-source <id> <source-id>  Remove source\n")
+		fmt.Fprintf(os.Stderr, "  rm-source <id> --match <glob> [--type T] [--status S] [--older-than 30d] [--dry-run]  Remove sources by filter\n")
+		fmt.Fprintf(os.Stderr, "  sources-toggle <id> [source-id...] --enable|--disable [filters]  Enable or disable sources\n")
 		fmt.Fprintf(os.Stderr, "  rename-source <source-id> <new-name>  Rename source\n")
-		fmt.Fprintf(os.Stderr, "  refresh-source <source-id>  Refresh source content\n")
+		fmt.Fprintf(os.Stderr, "  refresh-source <source-id>  Refresh source content (re-sync Google Drive sources)\n")
 		fmt.Fprintf(os.Stderr, "  check-source <source-id>  Check source freshness\n")
-		fmt.Fprintf(os.Stderr, "  discover-sources <id> <query>  Discover relevant sources\n\n")
+		fmt.Fprintf(os.Stderr, "  freshness <id>|--all [--refresh] [--since 24h]  Report, and refresh, stale Drive, web and YouTube sources\n")
+		fmt.Fprintf(os.Stderr, "  source-get <source-id> [--content] [-o file]  Show source metadata and extracted text\n")
+		fmt.Fprintf(os.Stderr, "  discover-sources <id> <query>  Discover relevant sources\n")
+		fmt.Fprintf(os.Stderr, "  add-crawl <id> <url> [--depth N] [--same-host] [--max N] [--dry-run]  Add pages found by following links\n")
+		fmt.Fprintf(os.Stderr, "  add-sitemap <id> <sitemap-url|file> [--max N] [--dry-run]  Add the pages listed in a sitemap\n")
+		fmt.Fprintf(os.Stderr, "  add-feed <id> <feed-url> [--since 7d] [--max N] [--dry-run]  Add the entries of an RSS or Atom feed\n")
+		fmt.Fprintf(os.Stderr, "  add-repo <id> <path|git-url> [--ref main] [--include '*.go'] [--dry-run]  Add a git repository as per-directory Markdown sources\n")
+		fmt.Fprintf(os.Stderr, "  discover <id> <query> [--interactive | --import-top N] [--json]  Discover sources and add a selection\n")
+		fmt.Fprintf(os.Stderr, "  watch <id> <dir> [--state file] [--poll 5s]  Keep notebook sources in sync with a folder\n\n")
 
 		fmt.Fprintf(os.Stderr, "Note Commands:\n")
 		fmt.Fprintf(os.Stderr, "  notes <id>        List notes in notebook\n")
 		fmt.Fprintf(os.Stderr, "  new-note <id> <title>  Create new note\n")
 		fmt.Fprintf(os.Stderr, "  update-note <id> <note-id> <content> <title>  Edit note\n")
+		fmt.Fprintf(os.Stderr, "  note-edit <id> <note-id>  Edit note in $EDITOR\n")
+		fmt.Fprintf(os.Stderr, "  notes-export <id> [-o dir]  Write notes as Markdown files\n")
+		fmt.Fprintf(os.Stderr, "  notes-import <id> <dir>  Create or update notes from Markdown files\n")
+		fmt.Fprintf(os.Stderr, "  note-to-source <id> <note-id...>|--all [--delete]  Add notes as sources\n")
+		fmt.Fprintf(os.Stderr, "  source-to-note <id> <source-id...> [filters] [--delete]  Save source text as notes\n")
 		fmt.Fprintf(os.Stderr, "  rm-note <note-id>  Remove note\n\n")
 
 		fmt.Fprintf(os.Stderr, "Audio Commands:\n")
 		fmt.Fprintf(os.Stderr, "  audio-list <id>   List all audio overviews for a notebook with status\n")
-		fmt.Fprintf(os.Stderr, "  audio-create <id> <instructions>  Create audio overview\n")
+		fmt.Fprintf(os.Stderr, "  audio-create <id> <instructions> [--wait] [--then-download file]  Create audio overview\n")
 		fmt.Fprintf(os.Stderr, "  audio-get <id>    Get audio overview\n")
 		fmt.Fprintf(os.Stderr, "  audio-download <id> [filename]  Download audio file (requires --direct-rpc)\n")
 		fmt.Fprintf(os.Stderr, "  audio-rm <id>     Delete audio overview\n")
@@ -107,25 +140,32 @@ func init() {
 
 		fmt.Fprintf(os.Stderr, "Video Commands:\n")
 		fmt.Fprintf(os.Stderr, "  video-list <id>   List all video overviews for a notebook with status\n")
-		fmt.Fprintf(os.Stderr, "  video-create <id> <instructions>  Create video overview\n")
-		fmt.Fprintf(os.Stderr, "  video-download <id> [filename]  Download video file (requires --direct-rpc)\n\n")
+		fmt.Fprintf(os.Stderr, "  video-create <id> <instructions> [--wait] [--then-download file]  Create video overview\n")
+		fmt.Fprintf(os.Stderr, "  video-download <id> [filename]  Download video file (requires --direct-rpc)\n")
+		fmt.Fprintf(os.Stderr, "  media-download <id> [--all|--audio|--video] [--dir path]  Download audio and video overviews (requires --direct-rpc)\n\n")
 
 		fmt.Fprintf(os.Stderr, "Artifact Commands:\n")
-		fmt.Fprintf(os.Stderr, "  create-artifact <id> <type>  Create artifact (note|audio|report|app)\n")
+		fmt.Fprintf(os.Stderr, "  create-artifact <id> <type> [--wait] [--then-download file]  Create artifact (note|audio|report|app)\n")
+		fmt.Fprintf(os.Stderr, "  report-suggest <id>  List suggested reports\n")
+		fmt.Fprintf(os.Stderr, "  report-create <id> --suggestion N|--prompt text [--sources id,...] [--wait]  Create a tailored report\n")
 		fmt.Fprintf(os.Stderr, "  get-artifact <artifact-id>  Get artifact details\n")
+		fmt.Fprintf(os.Stderr, "  artifact-export <artifact-id> [--format md|html|json] [-o file]  Render a report or note with citations\n")
 		fmt.Fprintf(os.Stderr, "  artifacts <id>       List artifacts in notebook\n")
 		fmt.Fprintf(os.Stderr, "  list-artifacts <id>  List artifacts in notebook (alias)\n")
 		fmt.Fprintf(os.Stderr, "  rename-artifact <artifact-id> <new-title>  Rename artifact\n")
+		fmt.Fprintf(os.Stderr, "  update-artifact <artifact-id> [--title T] [--content-file F] [--sources id,...]  Edit a report or note\n")
 		fmt.Fprintf(os.Stderr, "  delete-artifact <artifact-id>  Delete artifact\n\n")
 
 		fmt.Fprintf(os.Stderr, "Generation Commands:\n")
 		fmt.Fprintf(os.Stderr, "  generate-guide <id>  Generate notebook guide\n")
 		fmt.Fprintf(os.Stderr, "  generate-outline <id>  Generate content outline\n")
 		fmt.Fprintf(os.Stderr, "  generate-section <id>  Generate new section\n")
-		fmt.Fprintf(os.Stderr, "  generate-chat <id> <prompt>  Free-form chat generation\n")
+		fmt.Fprintf(os.Stderr, "  generate-chat <id> <prompt> [--sources ids] [--source-type T] [--source-match glob] [--citations footnotes|json|off]  Free-form chat generation\n")
 		fmt.Fprintf(os.Stderr, "  generate-magic <id> <source-ids...>  Generate magic view from sources\n")
-		fmt.Fprintf(os.Stderr, "  chat <id>               Interactive chat session\n")
-		fmt.Fprintf(os.Stderr, "  chat-list               List all saved chat sessions\n\n")
+		fmt.Fprintf(os.Stderr, "  ask <id> [question|-] [--session name] [--context C] [--json]  Answer one question on stdout, for scripts\n")
+		fmt.Fprintf(os.Stderr, "  chat <id> [--session name] [--context full|window:N|summary] [--sources ids] [--source-type T] [--source-match glob] [--citations footnotes|json|off]  Interactive chat session\n")
+		fmt.Fprintf(os.Stderr, "  chat-list               List all saved chat sessions\n")
+		fmt.Fprintf(os.Stderr, "  chat-export <id> [--session name] [--format md|html|jsonl] [-o file]  Export a chat session\n\n")
 
 		fmt.Fprintf(os.Stderr, "Content Transformation Commands:\n")
 		fmt.Fprintf(os.Stderr, "  rephrase <id> <source-ids...>     Rephrase content from sources\n")
@@ -159,6 +199,12 @@ func init() {
 func main() {
 	flag.Parse()
 
+	var err error
+	if logger, err = newLogger(logLevel); err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+		os.Exit(1)
+	}
+
 	if debug {
 		fmt.Fprintf(os.Stderr, "nlm: debug mode enabled\n")
 		if chromeProfile != "" {
@@ -196,10 +242,30 @@ func main() {
 
 	if err := run(); err != nil {
 		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+		var ee *exitError
+		if errors.As(err, &ee) {
+			os.Exit(ee.code)
+		}
 		os.Exit(1)
 	}
 }
 
+// Exit codes other than 1, the code for all other failures.
+const (
+	exitUsage       = 2 // invalid arguments (ask only)
+	exitAuth        = 3 // missing or rejected credentials (ask only)
+	exitEmptyAnswer = 4 // ask got an empty answer
+)
+
+// exitError makes the process exit with code instead of 1.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
 // isAuthCommand returns true if the command requires authentication
 // validateArgs validates command arguments without requiring authentication
 func validateArgs(cmd string, args []string) error {
@@ -220,15 +286,11 @@ func validateArgs(cmd string, args []string) error {
 			return fmt.Errorf("invalid arguments")
 		}
 	case "add":
-		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "usage: nlm add <notebook-id> <file>\n")
-			return fmt.Errorf("invalid arguments")
-		}
+		return validateAddArgs(args)
 	case "rm-source":
-		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "usage: nlm rm-source <notebook-id> <source-id>\n")
-			return fmt.Errorf("invalid arguments")
-		}
+		return validateRmSourceArgs(args)
+	case "sources-toggle":
+		return validateSourcesToggleArgs(args)
 	case "rename-source":
 		if len(args) != 2 {
 			fmt.Fprintf(os.Stderr, "usage: nlm rename-source <source-id> <new-name>\n")
@@ -244,6 +306,16 @@ func validateArgs(cmd string, args []string) error {
 			fmt.Fprintf(os.Stderr, "usage: nlm update-note <notebook-id> <note-id> <content> <title>\n")
 			return fmt.Errorf("invalid arguments")
 		}
+	case "note-edit":
+		return validateNoteEditArgs(args)
+	case "notes-export":
+		return validateNotesExportArgs(args)
+	case "notes-import":
+		return validateNotesImportArgs(args)
+	case "note-to-source":
+		return validateNoteToSourceArgs(args)
+	case "source-to-note":
+		return validateSourceToNoteArgs(args)
 	case "rm-note":
 		if len(args) != 2 {
 			fmt.Fprintf(os.Stderr, "usage: nlm rm-note <notebook-id> <note-id>\n")
@@ -270,10 +342,7 @@ func validateArgs(cmd string, args []string) error {
 			return fmt.Errorf("invalid arguments")
 		}
 	case "audio-create":
-		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "usage: nlm audio-create <notebook-id> <instructions>\n")
-			return fmt.Errorf("invalid arguments")
-		}
+		return validateWaitArgs(cmd, "<notebook-id> <instructions>", args)
 	case "audio-get":
 		if len(args) != 1 {
 			fmt.Fprintf(os.Stderr, "usage: nlm audio-get <notebook-id>\n")
@@ -289,9 +358,16 @@ func validateArgs(cmd string, args []string) error {
 			fmt.Fprintf(os.Stderr, "usage: nlm audio-share <notebook-id>\n")
 			return fmt.Errorf("invalid arguments")
 		}
+	case "media-download":
+		return validateMediaDownloadArgs(args)
 	case "video-create":
-		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "usage: nlm video-create <notebook-id> <instructions>\n")
+		if err := validateWaitArgs(cmd, "<notebook-id> <instructions>", args); err != nil {
+			return err
+		}
+		// Polling for the video needs direct RPC; fail before starting
+		// a generation we could not wait for.
+		if w, _, _ := parseWaitFlags(cmd, args); w.waiting() && !useDirectRPC {
+			fmt.Fprintf(os.Stderr, "nlm: video-create --wait and --then-download require --direct-rpc\n")
 			return fmt.Errorf("invalid arguments")
 		}
 	case "share":
@@ -343,30 +419,36 @@ func validateArgs(cmd string, args []string) error {
 			return fmt.Errorf("invalid arguments")
 		}
 	case "generate-chat":
-		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "usage: nlm generate-chat <notebook-id> <prompt>\n")
-			return fmt.Errorf("invalid arguments")
-		}
+		return validateChatArgs(cmd, "<notebook-id> <prompt>", 2, args)
+	case "ask":
+		return validateAskArgs(args)
 	case "chat":
-		if len(args) != 1 {
-			fmt.Fprintf(os.Stderr, "usage: nlm chat <notebook-id>\n")
-			return fmt.Errorf("invalid arguments")
-		}
+		return validateChatArgs(cmd, "<notebook-id>", 1, args)
 	case cmdChatList:
 		if len(args) != 0 {
 			fmt.Fprintf(os.Stderr, "usage: nlm chat-list\n")
 			return fmt.Errorf("invalid arguments")
 		}
+	case cmdChatExport:
+		return validateChatExportArgs(args)
 	case "create-artifact":
-		if len(args) != 2 {
-			fmt.Fprintf(os.Stderr, "usage: nlm create-artifact <notebook-id> <type>\n")
-			return fmt.Errorf("invalid arguments")
-		}
+		return validateWaitArgs(cmd, "<notebook-id> <type>", args)
 	case "get-artifact":
 		if len(args) != 1 {
 			fmt.Fprintf(os.Stderr, "usage: nlm get-artifact <artifact-id>\n")
 			return fmt.Errorf("invalid arguments")
 		}
+	case "report-suggest":
+		if len(args) != 1 {
+			fmt.Fprintf(os.Stderr, "usage: nlm report-suggest <notebook-id>\n")
+			return fmt.Errorf("invalid arguments")
+		}
+	case "report-create":
+		return validateReportCreateArgs(args)
+	case "artifact-export":
+		return validateArtifactExportArgs(args)
+	case "update-artifact":
+		return validateArtifactUpdateArgs(args)
 	case "list-artifacts", cmdArtifacts:
 		if len(args) != 1 {
 			if cmd == cmdArtifacts {
@@ -391,6 +473,14 @@ func validateArgs(cmd string, args []string) error {
 			fmt.Fprintf(os.Stderr, "usage: nlm discover-sources <notebook-id> <query>\n")
 			return fmt.Errorf("invalid arguments")
 		}
+	case "discover":
+		return validateDiscoverArgs(args)
+	case cmdAddCrawl, cmdAddSitemap, cmdAddFeed:
+		return validateWebAddArgs(cmd, args)
+	case "add-repo":
+		return validateRepoArgs(args)
+	case "freshness":
+		return validateFreshnessArgs(args)
 	case "analytics":
 		if len(args) != 1 {
 			fmt.Fprintf(os.Stderr, "usage: nlm analytics <notebook-id>\n")
@@ -401,6 +491,10 @@ func validateArgs(cmd string, args []string) error {
 			fmt.Fprintf(os.Stderr, "usage: nlm check-source <source-id>\n")
 			return fmt.Errorf("invalid arguments")
 		}
+	case "source-get":
+		return validateSourceGetArgs(args)
+	case "watch":
+		return validateWatchArgs(args)
 	case "refresh-source":
 		if len(args) != 1 {
 			fmt.Fprintf(os.Stderr, "usage: nlm refresh-source <source-id>\n")
@@ -425,11 +519,11 @@ func isValidCommand(cmd string) bool {
 	validCommands := []string{
 		"help", "-h", "--help",
 		"list", "ls", "create", "rm", "analytics", "list-featured",
-		"sources", "add", "rm-source", "rename-source", "refresh-source", "check-source", "discover-sources",
-		"notes", "new-note", "update-note", "rm-note",
-		"audio-create", "audio-get", "audio-rm", "audio-share", "audio-list", "audio-download", "video-create", "video-list", "video-download",
-		"create-artifact", "get-artifact", "list-artifacts", cmdArtifacts, "rename-artifact", "delete-artifact",
-		"generate-guide", "generate-outline", "generate-section", "generate-magic", "generate-mindmap", "generate-chat", "chat", cmdChatList,
+		"sources", "add", "rm-source", "sources-toggle", "rename-source", "refresh-source", "check-source", "freshness", "source-get", "discover-sources", "discover", cmdAddCrawl, cmdAddSitemap, cmdAddFeed, "add-repo", "watch",
+		"notes", "new-note", "update-note", "note-edit", "notes-export", "notes-import", "note-to-source", "source-to-note", "rm-note",
+		"audio-create", "audio-get", "audio-rm", "audio-share", "audio-list", "audio-download", "video-create", "video-list", "video-download", "media-download",
+		"create-artifact", "report-suggest", "report-create", "get-artifact", "artifact-export", "list-artifacts", cmdArtifacts, "rename-artifact", "update-artifact", "delete-artifact",
+		"generate-guide", "generate-outline", "generate-section", "generate-magic", "generate-mindmap", "generate-chat", "ask", "chat", cmdChatList, cmdChatExport,
 		"rephrase", "expand", "summarize", "critique", "brainstorm", "verify", "explain", "outline", "study-guide", "faq", "briefing-doc", "mindmap", "timeline", "toc",
 		"auth", cmdRefresh, "hb", "share", "share-private", "share-details", "feedback",
 	}
@@ -455,8 +549,8 @@ func isAuthCommand(cmd string) bool {
 	if cmd == cmdRefresh {
 		return false
 	}
-	// Chat-list just lists local sessions, no auth needed
-	if cmd == cmdChatList {
+	// Chat-list and chat-export only read local sessions, no auth needed
+	if cmd == cmdChatList || cmd == cmdChatExport {
 		return false
 	}
 	return true
@@ -509,7 +603,11 @@ func run() error {
 	// Check if this command needs authentication
 	if isAuthCommand(cmd) && (authToken == "" || cookies == "") {
 		fmt.Fprintf(os.Stderr, "Authentication required for '%s'. Run 'nlm auth' first.\n", cmd)
-		return fmt.Errorf("authentication required")
+		err := fmt.Errorf("authentication required")
+		if cmd == "ask" {
+			return &exitError{exitAuth, err}
+		}
+		return err
 	}
 
 	// Handle help commands without creating API client
@@ -536,6 +634,9 @@ func run() error {
 		opts = append(opts, batchexecute.WithDebug(true))
 	}
 
+	logOpts := rpcOptions()
+	opts = append(opts, logOpts...)
+
 	// Add rt=c parameter if chunked response format is requested
 	if chunkedResponse {
 		opts = append(opts, batchexecute.WithURLParams(map[string]string{
@@ -565,7 +666,7 @@ func run() error {
 			}
 			debug = true
 			// Update opts to include debug when retrying auth
-			opts = []batchexecute.Option{batchexecute.WithDebug(true)}
+			opts = append([]batchexecute.Option{batchexecute.WithDebug(true)}, logOpts...)
 		}
 
 		client := api.New(authToken, cookies, opts...)
@@ -577,12 +678,15 @@ func run() error {
 			}
 		}
 		cmdErr := runCmd(client, cmd, args...)
+		var ee *exitError
 		if cmdErr == nil {
 			if i > 0 {
 				_, _ = fmt.Fprintln(os.Stderr, "nlm: authentication refreshed successfully")
 			}
 			return nil
-		} else if !isAuthenticationError(cmdErr) {
+		} else if errors.As(cmdErr, &ee) || !isAuthenticationError(cmdErr) {
+			// An error with its own exit code is final: ask reports
+			// rejected credentials that way instead of opening a browser.
 			return cmdErr
 		}
 
@@ -604,7 +708,7 @@ func run() error {
 			}
 		}
 	}
-	return fmt.Errorf("nlm: authentication failed after 3 attempts")
+	return fmt.Errorf("authentication failed after 3 attempts")
 }
 
 // isAuthenticationError checks if an error is related to authentication
@@ -697,19 +801,31 @@ func runCmd(client *api.Client, cmd string, args ...string) error {
 	case "sources":
 		err = listSources(client, args[0])
 	case "add":
-		var id string
-		id, err = addSource(client, args[0], args[1])
-		fmt.Println(id)
+		err = addInput(client, args)
 	case "rm-source":
-		err = removeSource(client, args[0], args[1])
+		err = removeSources(client, args)
+	case "sources-toggle":
+		err = toggleSources(client, args)
 	case "rename-source":
 		err = renameSource(client, args[0], args[1])
 	case "refresh-source":
 		err = refreshSource(client, args[0])
 	case "check-source":
 		err = checkSourceFreshness(client, args[0])
+	case "freshness":
+		err = freshness(client, args)
+	case "source-get":
+		err = getSource(client, args)
 	case "discover-sources":
-		err = discoverSources(client, args[0], args[1])
+		err = discover(client, args[:2])
+	case "discover":
+		err = discover(client, args)
+	case cmdAddCrawl, cmdAddSitemap, cmdAddFeed:
+		err = addWeb(client, cmd, args)
+	case "add-repo":
+		err = addRepo(client, args)
+	case "watch":
+		err = watchDir(client, args)
 
 	// Note operations
 	case "notes":
@@ -718,12 +834,25 @@ func runCmd(client *api.Client, cmd string, args ...string) error {
 		err = createNote(client, args[0], args[1])
 	case "update-note":
 		err = updateNote(client, args[0], args[1], args[2], args[3])
+	case "note-edit":
+		err = editNote(client, args)
+	case "notes-export":
+		err = exportNotes(client, args)
+	case "notes-import":
+		err = importNotes(client, args)
+	case "note-to-source":
+		err = noteToSource(client, args)
+	case "source-to-note":
+		err = sourceToNote(client, args)
 	case "rm-note":
 		err = removeNote(client, args[0], args[1])
 
 		// Audio operations
 	case "audio-create":
-		err = createAudioOverview(client, args[0], args[1])
+		var w *WaitFlags
+		if w, args, err = parseWaitFlags(cmd, args); err == nil {
+			err = createAudioOverview(client, args[0], args[1], w)
+		}
 	case "audio-get":
 		err = getAudioOverview(client, args[0])
 	case "audio-rm":
@@ -739,9 +868,14 @@ func runCmd(client *api.Client, cmd string, args ...string) error {
 		}
 		err = downloadAudioOverview(client, args[0], filename)
 	case "video-create":
-		err = createVideoOverview(client, args[0], args[1])
+		var w *WaitFlags
+		if w, args, err = parseWaitFlags(cmd, args); err == nil {
+			err = createVideoOverview(client, args[0], args[1], w)
+		}
 	case "video-list":
 		err = listVideoOverviews(client, args[0])
+	case "media-download":
+		err = mediaDownload(client, args)
 	case "video-download":
 		filename := ""
 		if len(args) > 1 {
@@ -751,9 +885,20 @@ func runCmd(client *api.Client, cmd string, args ...string) error {
 
 	// Artifact operations
 	case "create-artifact":
-		err = createArtifact(client, args[0], args[1])
+		var w *WaitFlags
+		if w, args, err = parseWaitFlags(cmd, args); err == nil {
+			err = createArtifact(client, args[0], args[1], w)
+		}
 	case "get-artifact":
 		err = getArtifact(client, args[0])
+	case "report-suggest":
+		err = reportSuggest(client, args[0])
+	case "report-create":
+		err = reportCreate(client, args)
+	case "artifact-export":
+		err = exportArtifact(client, args)
+	case "update-artifact":
+		err = updateArtifact(client, args)
 	case "list-artifacts", cmdArtifacts:
 		err = listArtifacts(client, args[0])
 	case "rename-artifact":
@@ -801,11 +946,15 @@ func runCmd(client *api.Client, cmd string, args ...string) error {
 	case "toc":
 		err = actOnSources(client, args[0], "table_of_contents", args[1:])
 	case "generate-chat":
-		err = generateFreeFormChat(client, args[0], args[1])
+		err = generateFreeFormChat(client, args)
+	case "ask":
+		err = ask(client, args)
 	case "chat":
-		err = interactiveChat(client, args[0])
+		err = interactiveChat(client, args)
 	case cmdChatList:
 		err = listChatSessions()
+	case cmdChatExport:
+		err = exportChat(args)
 
 	// Sharing operations
 	case "share":
@@ -895,10 +1044,26 @@ func listSources(c *api.Client, notebookID string) error {
 	if err != nil {
 		return fmt.Errorf("list sources: %w", err)
 	}
+	return printSourceTable(p.Sources)
+}
 
+// printSourceTable writes sources to stdout as a table.
+//
+// A DOCUMENT ID column is added when any source comes from Google Drive.
+func printSourceTable(sources []*pb.Source) error {
+	withDocs := false
+	for _, src := range sources {
+		if src.GetMetadata().GetGoogleDocs().GetDocumentId() != "" {
+			withDocs = true
+		}
+	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	_, _ = fmt.Fprintln(w, "ID\tTITLE\tTYPE\tSTATUS\tLAST UPDATED")
-	for _, src := range p.Sources {
+	header := "ID\tTITLE\tTYPE\tSTATUS\tLAST UPDATED"
+	if withDocs {
+		header += "\tDOCUMENT ID"
+	}
+	_, _ = fmt.Fprintln(w, header)
+	for _, src := range sources {
 		status := "enabled"
 		if src.Metadata != nil {
 			status = src.Metadata.Status.String()
@@ -914,31 +1079,48 @@ func listSources(c *api.Client, notebookID string) error {
 			sourceType = src.Metadata.GetSourceType().String()
 		}
 
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s",
 			src.SourceId.GetSourceId(),
 			strings.TrimSpace(src.Title),
 			sourceType,
 			status,
 			lastUpdated,
 		)
+		if withDocs {
+			docID := src.GetMetadata().GetGoogleDocs().GetDocumentId()
+			if docID == "" {
+				docID = "-"
+			}
+			_, _ = fmt.Fprintf(w, "\t%s", docID)
+		}
+		_, _ = fmt.Fprintln(w)
 	}
 	return w.Flush()
 }
 
-func addSource(c *api.Client, notebookID, input string) (string, error) {
+// addSource adds input as one source. An input of "-" is read from stdin.
+func addSource(c *api.Client, notebookID, input string, stdin io.Reader) (string, error) {
 	// Handle special input designators
 	switch input {
 	case "-": // stdin
-		_, _ = fmt.Fprintln(os.Stderr, "Reading from stdin...")
 		if mimeType != "" {
 			fmt.Fprintf(os.Stderr, "Using specified MIME type: %s\n", mimeType)
-			return c.AddSourceFromReader(notebookID, os.Stdin, "Pasted Text", mimeType)
+			return c.AddSourceFromReader(notebookID, stdin, "Pasted Text", mimeType)
 		}
-		return c.AddSourceFromReader(notebookID, os.Stdin, "Pasted Text")
+		return c.AddSourceFromReader(notebookID, stdin, "Pasted Text")
 	case "": // empty input
 		return "", fmt.Errorf("input required (file, URL, or '-' for stdin)")
 	}
 
+	// Google Docs, Slides and Sheets URLs and docs:, slides: or sheets:
+	// file IDs are added as Drive sources, unless a local file has that name.
+	if f, ok := drive.ParseRef(input); ok {
+		if _, err := os.Stat(input); err != nil {
+			fmt.Printf("Adding %s from Google Drive: %s\n", driveTypeName(f.Type), f.ID)
+			return c.AddDriveSource(notebookID, f, "")
+		}
+	}
+
 	// Check if input is a URL
 	if strings.HasPrefix(input, "http://") || strings.HasPrefix(input, "https://") {
 		fmt.Printf("Adding source from URL: %s\n", input)
@@ -971,6 +1153,17 @@ func addSource(c *api.Client, notebookID, input string) (string, error) {
 	return c.AddSourceFromText(notebookID, input, "Text Source")
 }
 
+// driveTypeName names a Google Drive source type for messages.
+func driveTypeName(t pb.SourceType) string {
+	switch t {
+	case pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES:
+		return "Google Slides presentation"
+	case pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS:
+		return "Google Sheets spreadsheet"
+	}
+	return "Google Doc"
+}
+
 func removeSource(c *api.Client, notebookID, sourceID string) error {
 	fmt.Printf("Are you sure you want to remove source %s? [y/N] ", sourceID)
 	var response string
@@ -1051,11 +1244,7 @@ func listNotes(c *api.Client, notebookID string) error {
 		if meta := note.GetMetadata(); meta != nil && meta.LastModifiedTime != nil {
 			lastModified = meta.LastModifiedTime.AsTime().Format(time.RFC3339)
 		}
-		// Extract the actual note ID from the SourceId wrapper
-		noteID := note.GetSourceId().GetSourceId()
-		if noteID == "" {
-			noteID = note.GetSourceId().String()
-		}
+		noteID := noteIDOf(note)
 		title := note.Title
 		if title == "" {
 			title = untitledTitle
@@ -1239,7 +1428,7 @@ func actOnSources(c *api.Client, notebookID, action string, sourceIDs []string)
 // }
 
 // Other operations
-func createAudioOverview(c *api.Client, projectID, instructions string) error {
+func createAudioOverview(c *api.Client, projectID, instructions string, w *WaitFlags) error {
 	fmt.Printf("Creating audio overview for notebook %s...\n", projectID)
 	fmt.Printf("Instructions: %s\n", instructions)
 
@@ -1248,10 +1437,21 @@ func createAudioOverview(c *api.Client, projectID, instructions string) error {
 		return fmt.Errorf("create audio overview: %w", err)
 	}
 
-	if !result.IsReady {
+	if !result.IsReady && !w.waiting() {
 		fmt.Println("✅ Audio overview creation started. Use 'nlm audio-get' to check status.")
 		return nil
 	}
+	if !result.IsReady {
+		s := startSpinner("Waiting for audio overview")
+		result, err = c.WaitForAudio(projectID, w.options(s))
+		s.stop()
+		if err != nil {
+			return err
+		}
+	}
+	if w.ThenDownload != "" {
+		return downloadAudioOverview(c, projectID, w.ThenDownload)
+	}
 
 	// If the result is immediately ready (unlikely but possible)
 	fmt.Printf("✅ Audio Overview created:\n")
@@ -1285,7 +1485,7 @@ func heartbeat(c *api.Client) error {
 // Analytics and featured projects
 func getAnalytics(c *api.Client, projectID string) error {
 	// Create orchestration service client using the same auth as the main client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.GetProjectAnalyticsRequest{
 		ProjectId: projectID,
@@ -1309,7 +1509,7 @@ func getAnalytics(c *api.Client, projectID string) error {
 
 func listFeaturedProjects(c *api.Client) error {
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.ListFeaturedProjectsRequest{
 		PageSize: 20,
@@ -1339,7 +1539,7 @@ func listFeaturedProjects(c *api.Client) error {
 // Enhanced source operations
 func refreshSource(c *api.Client, sourceID string) error {
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.RefreshSourceRequest{
 		SourceId: sourceID,
@@ -1351,13 +1551,18 @@ func refreshSource(c *api.Client, sourceID string) error {
 		return fmt.Errorf("refresh source: %w", err)
 	}
 
+	if md := source.GetMetadata(); drive.IsSourceType(md.GetSourceType()) {
+		fmt.Printf("✅ Re-synced %s from Google Drive: %s (document %s)\n",
+			driveTypeName(md.GetSourceType()), source.Title, md.GetGoogleDocs().GetDocumentId())
+		return nil
+	}
 	fmt.Printf("✅ Refreshed source: %s\n", source.Title)
 	return nil
 }
 
 func checkSourceFreshness(c *api.Client, sourceID string) error {
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.CheckSourceFreshnessRequest{
 		SourceId: sourceID,
@@ -1383,48 +1588,10 @@ func checkSourceFreshness(c *api.Client, sourceID string) error {
 	return nil
 }
 
-func discoverSources(c *api.Client, projectID, query string) error {
-	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
-
-	req := &pb.DiscoverSourcesRequest{
-		ProjectId: projectID,
-		Query:     query,
-	}
-
-	fmt.Fprintf(os.Stderr, "Discovering sources for query: %s\n", query)
-	resp, err := orchClient.DiscoverSources(context.Background(), req)
-	if err != nil {
-		return fmt.Errorf("discover sources: %w", err)
-	}
-
-	if len(resp.Sources) == 0 {
-		fmt.Println("No sources found for the query.")
-		return nil
-	}
-
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
-	_, _ = fmt.Fprintln(w, "ID\tTITLE\tTYPE\tRELEVANCE")
-
-	for _, source := range resp.Sources {
-		relevance := "Unknown"
-		if source.Metadata != nil {
-			relevance = source.Metadata.GetSourceType().String()
-		}
-
-		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n",
-			source.SourceId.GetSourceId(),
-			strings.TrimSpace(source.Title),
-			source.Metadata.GetSourceType(),
-			relevance)
-	}
-	return w.Flush()
-}
-
 // Artifact management
-func createArtifact(c *api.Client, projectID, artifactType string) error {
+func createArtifact(c *api.Client, projectID, artifactType string, w *WaitFlags) error {
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	// Parse artifact type
 	var aType pb.ArtifactType
@@ -1440,6 +1607,9 @@ func createArtifact(c *api.Client, projectID, artifactType string) error {
 	default:
 		return fmt.Errorf("invalid artifact type: %s (valid: note, audio, report, app)", artifactType)
 	}
+	if w.ThenDownload != "" && aType == pb.ArtifactType_ARTIFACT_TYPE_APP {
+		return fmt.Errorf("--then-download is not supported for app artifacts")
+	}
 
 	req := &pb.CreateArtifactRequest{
 		ProjectId: projectID,
@@ -1456,16 +1626,36 @@ func createArtifact(c *api.Client, projectID, artifactType string) error {
 		return fmt.Errorf("create artifact: %w", err)
 	}
 
+	if w.waiting() && artifact.State == pb.ArtifactState_ARTIFACT_STATE_CREATING {
+		s := startSpinner("Waiting for artifact " + artifact.ArtifactId)
+		artifact, err = c.WaitForArtifact(artifact.ArtifactId, w.options(s))
+		s.stop()
+		if err != nil {
+			return err
+		}
+	}
+
 	fmt.Printf("✅ Created artifact: %s\n", artifact.ArtifactId)
 	fmt.Printf("  Type: %s\n", artifact.Type.String())
 	fmt.Printf("  State: %s\n", artifact.State.String())
 
-	return nil
+	switch {
+	case w.ThenDownload == "":
+		return nil
+	case aType == pb.ArtifactType_ARTIFACT_TYPE_AUDIO_OVERVIEW:
+		return downloadAudioOverview(c, projectID, w.ThenDownload)
+	}
+	// Notes and reports are exported as report-create does, in the format
+	// the file extension names.
+	return writeArtifactExport(c, artifact, &ArtifactExportOptions{
+		Format: formatForPath(w.ThenDownload),
+		Output: w.ThenDownload,
+	})
 }
 
 func getArtifact(c *api.Client, artifactID string) error {
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.GetArtifactRequest{
 		ArtifactId: artifactID,
@@ -1561,7 +1751,7 @@ func deleteArtifact(c *api.Client, artifactID string) error {
 	}
 
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.DeleteArtifactRequest{
 		ArtifactId: artifactID,
@@ -1577,23 +1767,31 @@ func deleteArtifact(c *api.Client, artifactID string) error {
 }
 
 // Generation operations
-func generateFreeFormChat(c *api.Client, projectID, prompt string) error {
+func generateFreeFormChat(c *api.Client, args []string) error {
+	opts, pos, err := parseChatFlags("generate-chat", args)
+	if err != nil {
+		return err
+	}
+	projectID, prompt := pos[0], pos[1]
+	filter, _ := opts.sourceFilter()
+	sourceIDs, err := chatSources(c, projectID, opts.Sources, filter)
+	if err != nil {
+		return fmt.Errorf("generate chat: %w", err)
+	}
 	fmt.Fprintf(os.Stderr, "Generating response for: %s\n", prompt)
 
 	// Use the API client's GenerateFreeFormStreamed method
-	response, err := c.GenerateFreeFormStreamed(projectID, prompt, nil)
+	response, err := c.GenerateFreeFormStreamed(projectID, prompt, sourceIDs)
 	if err != nil {
 		return fmt.Errorf("generate chat: %w", err)
 	}
 
 	// Display the response
-	if response != nil && response.Chunk != "" {
-		fmt.Println(response.Chunk)
-	} else {
+	if response.Text == "" && opts.Citations != citationsJSON {
 		fmt.Println("(No response received)")
+		return nil
 	}
-
-	return nil
+	return writeChatAnswer(os.Stdout, c, projectID, response, opts.Citations)
 }
 
 // Utility functions for commented-out operations
@@ -1601,7 +1799,7 @@ func shareNotebook(c *api.Client, notebookID string) error {
 	fmt.Fprintf(os.Stderr, "Generating public share link...\n")
 
 	// Create RPC client directly for sharing project
-	rpcClient := rpc.New(authToken, cookies)
+	rpcClient := rpc.New(authToken, cookies, rpcOptions()...)
 	call := rpc.Call{
 		ID: "QDyure", // ShareProject RPC ID
 		Args: []interface{}{
@@ -1640,7 +1838,7 @@ func shareNotebook(c *api.Client, notebookID string) error {
 
 func submitFeedback(c *api.Client, message string) error {
 	// Create orchestration service client
-	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies)
+	orchClient := service.NewLabsTailwindOrchestrationServiceClient(authToken, cookies, rpcOptions()...)
 
 	req := &pb.SubmitFeedbackRequest{
 		FeedbackType: "general",
@@ -1660,7 +1858,7 @@ func shareNotebookPrivate(c *api.Client, notebookID string) error {
 	fmt.Fprintf(os.Stderr, "Generating private share link...\n")
 
 	// Create RPC client directly for sharing project
-	rpcClient := rpc.New(authToken, cookies)
+	rpcClient := rpc.New(authToken, cookies, rpcOptions()...)
 	call := rpc.Call{
 		ID: "QDyure", // ShareProject RPC ID
 		Args: []interface{}{
@@ -1701,7 +1899,7 @@ func getShareDetails(c *api.Client, shareID string) error {
 	fmt.Fprintf(os.Stderr, "Getting share details...\n")
 
 	// Create RPC client directly for getting project details
-	rpcClient := rpc.New(authToken, cookies)
+	rpcClient := rpc.New(authToken, cookies, rpcOptions()...)
 	call := rpc.Call{
 		ID:   "JFMDGd", // GetProjectDetails RPC ID
 		Args: []interface{}{shareID},
@@ -1733,102 +1931,6 @@ func getShareDetails(c *api.Client, shareID string) error {
 	return nil
 }
 
-// Chat helper functions
-func getChatSessionPath(notebookID string) string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return filepath.Join(os.TempDir(), fmt.Sprintf("nlm-chat-%s.json", notebookID))
-	}
-
-	nlmDir := filepath.Join(homeDir, ".nlm")
-	_ = os.MkdirAll(nlmDir, 0o700) // Ensure directory exists
-	return filepath.Join(nlmDir, fmt.Sprintf("chat-%s.json", notebookID))
-}
-
-func loadChatSession(notebookID string) (*ChatSession, error) {
-	path := getChatSessionPath(notebookID)
-	//nolint:gosec // file path is user-provided
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, err
-	}
-
-	var session ChatSession
-	if err := json.Unmarshal(data, &session); err != nil {
-		return nil, err
-	}
-
-	return &session, nil
-}
-
-func saveChatSession(session *ChatSession) error {
-	path := getChatSessionPath(session.NotebookID)
-
-	data, err := json.MarshalIndent(session, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(path, data, 0o600)
-}
-
-func listChatSessions() error {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return err
-	}
-
-	nlmDir := filepath.Join(homeDir, ".nlm")
-	entries, err := os.ReadDir(nlmDir)
-	if err != nil {
-		fmt.Println("No chat sessions found.")
-		return nil
-	}
-
-	var sessions []ChatSession
-	for _, entry := range entries {
-		if strings.HasPrefix(entry.Name(), "chat-") && strings.HasSuffix(entry.Name(), ".json") {
-			sessionPath := filepath.Join(nlmDir, entry.Name())
-			//nolint:gosec // file path is user-provided
-			data, err := os.ReadFile(sessionPath)
-			if err != nil {
-				continue
-			}
-
-			var session ChatSession
-			if err := json.Unmarshal(data, &session); err != nil {
-				continue
-			}
-
-			sessions = append(sessions, session)
-		}
-	}
-
-	if len(sessions) == 0 {
-		fmt.Println("No chat sessions found.")
-		return nil
-	}
-
-	fmt.Printf("📚 Chat Sessions (%d total)\n", len(sessions))
-	fmt.Println("=" + strings.Repeat("=", 40))
-
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintln(w, "NOTEBOOK\tMESSAGES\tLAST UPDATED\tCREATED")
-	_, _ = fmt.Fprintln(w, "--------\t--------\t------------\t-------")
-
-	for _, session := range sessions {
-		lastUpdated := session.UpdatedAt.Format("Jan 2 15:04")
-		created := session.CreatedAt.Format("Jan 2 15:04")
-		_, _ = fmt.Fprintf(w, "%s\t%d\t%s\t%s\n",
-			session.NotebookID,
-			len(session.Messages),
-			lastUpdated,
-			created)
-	}
-
-	return w.Flush()
-}
-
 func showRecentHistory(session *ChatSession, maxMessages int) {
 	messages := session.Messages
 	start := 0
@@ -1846,60 +1948,28 @@ func showRecentHistory(session *ChatSession, maxMessages int) {
 	}
 }
 
-func buildContextualPrompt(session *ChatSession, currentInput string) string {
-	// Build context from recent messages (last 4 messages for context)
-	const maxContextMessages = 4
-	messages := session.Messages
-	contextStart := 0
-	if len(messages) > maxContextMessages {
-		contextStart = len(messages) - maxContextMessages
-	}
-
-	var contextParts []string
-	for _, msg := range messages[contextStart:] {
-		if msg.Role == "user" {
-			contextParts = append(contextParts, fmt.Sprintf("User: %s", msg.Content))
-		} else {
-			contextParts = append(contextParts, fmt.Sprintf("Assistant: %s", msg.Content))
-		}
-	}
-
-	// Add current input
-	contextParts = append(contextParts, fmt.Sprintf("User: %s", currentInput))
-
-	// If we have context, prepend it
-	if len(contextParts) > 1 {
-		return fmt.Sprintf("Previous conversation:\n%s\n\nPlease respond to the latest message, considering the conversation context.",
-			strings.Join(contextParts, "\n"))
-	}
-
-	return currentInput
-}
-
-func generateStreamedResponse(c *api.Client, notebookID, prompt string) (string, error) {
-	var fullResponse strings.Builder
+func generateStreamedResponse(c *api.Client, notebookID, prompt string, sourceIDs []string) (*api.ChatResponse, error) {
 	fmt.Print("\n🤖 Assistant: ")
 
 	// Use the new streaming callback API
-	err := c.GenerateFreeFormStreamedWithCallback(notebookID, prompt, nil, func(chunk string) bool {
+	response, err := c.GenerateFreeFormStreamedWithCallback(notebookID, prompt, sourceIDs, func(chunk string) bool {
 		// Print each chunk as it arrives for real-time streaming effect
 		fmt.Print(chunk)
-		fullResponse.WriteString(chunk)
 		return true // Continue streaming
 	})
 
 	fmt.Println() // Add newline after streaming is complete
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	responseText := strings.TrimSpace(fullResponse.String())
-	if responseText == "" {
-		return "", fmt.Errorf("no response received")
+	response.Text = strings.TrimSpace(response.Text)
+	if response.Text == "" {
+		return nil, fmt.Errorf("no response received")
 	}
 
-	return responseText, nil
+	return response, nil
 }
 
 // typewriterEffect removed - now using real streaming
@@ -1937,13 +2007,21 @@ func getFallbackResponse(input, notebookID string) string {
 }
 
 // Interactive chat interface with history and streaming support
-func interactiveChat(c *api.Client, notebookID string) error {
+func interactiveChat(c *api.Client, args []string) error {
+	opts, pos, err := parseChatFlags("chat", args)
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+	var titles map[string]string // loaded when the first citation arrives
+
 	// Load or create chat session
-	session, err := loadChatSession(notebookID)
+	session, err := loadNamedChatSession(notebookID, opts.Session)
 	if err != nil {
 		// Create new session if loading fails
 		session = &ChatSession{
 			NotebookID: notebookID,
+			Name:       opts.Session,
 			Messages:   []ChatMessage{},
 			CreatedAt:  time.Now(),
 			UpdatedAt:  time.Now(),
@@ -1954,6 +2032,21 @@ func interactiveChat(c *api.Client, notebookID string) error {
 	fmt.Println("\n📚 NotebookLM Interactive Chat")
 	fmt.Println("================================")
 	fmt.Printf("Notebook: %s\n", notebookID)
+	fmt.Printf("Session: %s\n", session.Name)
+	strategy, _ := ParseContextStrategy(opts.Context)
+	contexts := newContextManager(strategy, chatSummarizer(c, notebookID))
+	fmt.Printf("Context: %s\n", strategy)
+
+	if opts.scoped() {
+		filter, _ := opts.sourceFilter()
+		session.SourceIDs, err = chatSources(c, notebookID, opts.Sources, filter)
+		if err != nil {
+			return fmt.Errorf("select sources: %w", err)
+		}
+	}
+	if len(session.SourceIDs) > 0 {
+		fmt.Printf("Sources: %d pinned (/sources to change)\n", len(session.SourceIDs))
+	}
 
 	if len(session.Messages) > 0 {
 		fmt.Printf("Chat history: %d messages (started %s)\n",
@@ -1961,14 +2054,7 @@ func interactiveChat(c *api.Client, notebookID string) error {
 			session.CreatedAt.Format("Jan 2 15:04"))
 	}
 
-	fmt.Println("\nCommands:")
-	fmt.Println("  /exit or /quit - Exit chat")
-	fmt.Println("  /clear - Clear screen")
-	fmt.Println("  /history - Show recent chat history")
-	fmt.Println("  /reset - Clear chat history")
-	fmt.Println("  /save - Save current session")
-	fmt.Println("  /help - Show this help")
-	fmt.Println("  /multiline - Toggle multiline mode (end with empty line)")
+	printChatHelp()
 	fmt.Println("\nType your message and press Enter to send.")
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -2016,6 +2102,61 @@ func interactiveChat(c *api.Client, notebookID string) error {
 			continue
 		}
 
+		fields := strings.Fields(input)
+		switch strings.ToLower(fields[0]) {
+		case "/sources":
+			if err := chatSourcesCommand(c, session, fields[1:]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			continue
+		case "/fork":
+			if len(fields) > 2 {
+				fmt.Println("Usage: /fork [name]")
+				continue
+			}
+			forked, err := session.fork(strings.Join(fields[1:], ""))
+			if err == nil {
+				err = saveChatSession(session)
+			}
+			if err == nil {
+				err = saveChatSession(forked)
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Forked %s into %s; now chatting in %s.\n", session.Name, forked.Name, forked.Name)
+			session = forked
+			continue
+		case "/rename":
+			if len(fields) != 2 {
+				fmt.Println("Usage: /rename <name>")
+				continue
+			}
+			prev := session.Name
+			if err := session.rename(fields[1]); err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			fmt.Printf("Renamed session %s to %s.\n", prev, session.Name)
+			continue
+		case "/undo":
+			if session.undo() == 0 {
+				fmt.Println("Nothing to undo.")
+			} else {
+				fmt.Println("Removed the last exchange.")
+			}
+			continue
+		case "/retry":
+			question, ok := session.popLastQuestion()
+			if !ok {
+				fmt.Println("Nothing to retry.")
+				continue
+			}
+			fmt.Printf("Retrying: %s\n", question)
+			input = question
+		}
+
 		switch strings.ToLower(input) {
 		case "/exit", "/quit":
 			fmt.Println("\n👋 Saving session and goodbye!")
@@ -2042,6 +2183,7 @@ func interactiveChat(c *api.Client, notebookID string) error {
 				confirm := strings.ToLower(strings.TrimSpace(scanner.Text()))
 				if confirm == "y" || confirm == "yes" {
 					session.Messages = []ChatMessage{}
+					session.Summary = nil
 					session.UpdatedAt = time.Now()
 					fmt.Println("Chat history cleared.")
 				}
@@ -2055,14 +2197,7 @@ func interactiveChat(c *api.Client, notebookID string) error {
 			}
 			continue
 		case "/help":
-			fmt.Println("\nCommands:")
-			fmt.Println("  /exit or /quit - Exit chat")
-			fmt.Println("  /clear - Clear screen")
-			fmt.Println("  /history - Show recent chat history")
-			fmt.Println("  /reset - Clear chat history")
-			fmt.Println("  /save - Save current session")
-			fmt.Println("  /help - Show this help")
-			fmt.Println("  /multiline - Toggle multiline mode")
+			printChatHelp()
 			continue
 		case "/multiline":
 			multiline = !multiline
@@ -2074,6 +2209,9 @@ func interactiveChat(c *api.Client, notebookID string) error {
 			continue
 		}
 
+		// Build context from earlier turns before recording this one
+		contextualPrompt := contexts.prompt(session, input)
+
 		// Add user message to history
 		userMsg := ChatMessage{
 			Role:      "user",
@@ -2085,11 +2223,8 @@ func interactiveChat(c *api.Client, notebookID string) error {
 		// Send the message with context to the API
 		fmt.Println("\n🤔 Thinking...")
 
-		// Build context from recent messages for better responses
-		contextualPrompt := buildContextualPrompt(session, input)
-
 		// Try the GenerateFreeFormStreamed API with streaming
-		response, err := generateStreamedResponse(c, notebookID, contextualPrompt)
+		response, err := generateStreamedResponse(c, notebookID, contextualPrompt, session.SourceIDs)
 		if err != nil {
 			fmt.Printf("\n⚠️ Chat API error: %v\n", err)
 
@@ -2101,14 +2236,29 @@ func interactiveChat(c *api.Client, notebookID string) error {
 			assistantMsg := ChatMessage{
 				Role:      "assistant",
 				Content:   fallbackResponse,
+				Fallback:  true,
 				Timestamp: time.Now(),
 			}
 			session.Messages = append(session.Messages, assistantMsg)
 		} else {
+			if len(response.Citations) > 0 && opts.Citations != citationsOff {
+				if titles == nil {
+					titles = chatSourceTitles(c, notebookID)
+				}
+				answer := newChatAnswer(response, titles)
+				if opts.Citations == citationsJSON {
+					data, _ := json.Marshal(answer.Citations)
+					fmt.Printf("\n%s\n", data)
+				} else {
+					answer.writeFootnotes(os.Stdout)
+				}
+			}
+
 			// Add successful response to history
 			assistantMsg := ChatMessage{
 				Role:      "assistant",
-				Content:   response,
+				Content:   response.Text,
+				Citations: response.Citations,
 				Timestamp: time.Now(),
 			}
 			session.Messages = append(session.Messages, assistantMsg)
@@ -2170,6 +2320,9 @@ func startAutoRefreshIfEnabled() {
 
 	// Create and start token manager
 	tokenManager := auth.NewTokenManager(debug || os.Getenv("NLM_DEBUG") == "true")
+	if logger != nil {
+		tokenManager.SetLogger(logger)
+	}
 	if err := tokenManager.StartAutoRefreshManager(); err != nil {
 		if debug {
 			fmt.Fprintf(os.Stderr, "nlm: failed to start auto-refresh: %v\n", err)
@@ -2182,7 +2335,7 @@ func startAutoRefreshIfEnabled() {
 	}
 }
 
-func createVideoOverview(c *api.Client, projectID, instructions string) error {
+func createVideoOverview(c *api.Client, projectID, instructions string, w *WaitFlags) error {
 	fmt.Printf("Creating video overview for notebook %s...\n", projectID)
 	fmt.Printf("Instructions: %s\n", instructions)
 
@@ -2191,11 +2344,22 @@ func createVideoOverview(c *api.Client, projectID, instructions string) error {
 		return fmt.Errorf("create video overview: %w", err)
 	}
 
-	if !result.IsReady {
+	if !result.IsReady && !w.waiting() {
 		fmt.Println("✅ Video overview creation started. Video generation may take several minutes.")
 		fmt.Printf("  Project ID: %s\n", result.ProjectID)
 		return nil
 	}
+	if !result.IsReady {
+		s := startSpinner("Waiting for video overview")
+		result, err = c.WaitForVideo(projectID, w.options(s))
+		s.stop()
+		if err != nil {
+			return err
+		}
+	}
+	if w.ThenDownload != "" {
+		return downloadVideoOverview(c, projectID, w.ThenDownload)
+	}
 
 	// If the result is immediately ready (unlikely but possible)
 	fmt.Printf("✅ Video Overview created:\n")
@@ -2278,65 +2442,40 @@ func listVideoOverviews(c *api.Client, notebookID string) error {
 func downloadAudioOverview(c *api.Client, notebookID, filename string) error {
 	fmt.Printf("Downloading audio overview for notebook %s...\n", notebookID)
 
-	// Generate default filename if not provided
+	// Without a filename the extension is chosen from the downloaded content.
 	if filename == "" {
-		filename = fmt.Sprintf("audio_overview_%s.wav", notebookID)
+		filename = fmt.Sprintf("audio_overview_%s", notebookID)
 	}
 
-	// Download the audio
-	audioResult, err := c.DownloadAudioOverview(notebookID)
+	res, err := saveOverview(c, "audio", notebookID, filename)
 	if err != nil {
 		return fmt.Errorf("download audio overview: %w", err)
 	}
-
-	// Save to file
-	if err := audioResult.SaveAudioToFile(filename); err != nil {
-		return fmt.Errorf("save audio file: %w", err)
-	}
-
-	fmt.Printf("✅ Audio saved to: %s\n", filename)
-
-	// Show file info
-	if stat, err := os.Stat(filename); err == nil {
-		fmt.Printf("  File size: %.2f MB\n", float64(stat.Size())/(1024*1024))
-	}
-
+	printDownloadResult(res)
 	return nil
 }
 
 func downloadVideoOverview(c *api.Client, notebookID, filename string) error {
 	fmt.Printf("Downloading video overview for notebook %s...\n", notebookID)
 
-	// Generate default filename if not provided
+	// Without a filename the extension is chosen from the downloaded content.
 	if filename == "" {
-		filename = fmt.Sprintf("video_overview_%s.mp4", notebookID)
+		filename = fmt.Sprintf("video_overview_%s", notebookID)
 	}
 
-	// Download the video
-	videoResult, err := c.DownloadVideoOverview(notebookID)
+	res, err := saveOverview(c, "video", notebookID, filename)
 	if err != nil {
 		return fmt.Errorf("download video overview: %w", err)
 	}
+	printDownloadResult(res)
+	return nil
+}
 
-	// Check if we got a video URL
-	if videoResult.VideoData != "" && (strings.HasPrefix(videoResult.VideoData, "http://") || strings.HasPrefix(videoResult.VideoData, "https://")) {
-		// Use authenticated download for URLs
-		if err := c.DownloadVideoWithAuth(videoResult.VideoData, filename); err != nil {
-			return fmt.Errorf("download video with auth: %w", err)
-		}
-	} else {
-		// Try to save base64 data or handle other formats
-		if err := videoResult.SaveVideoToFile(filename); err != nil {
-			return fmt.Errorf("save video file: %w", err)
-		}
-	}
-
-	fmt.Printf("✅ Video saved to: %s\n", filename)
-
-	// Show file info
-	if stat, err := os.Stat(filename); err == nil {
-		fmt.Printf("  File size: %.2f MB\n", float64(stat.Size())/(1024*1024))
+func printDownloadResult(res *api.DownloadResult) {
+	fmt.Printf("✅ Saved to: %s\n", res.Path)
+	fmt.Printf("  File size: %.2f MB\n", float64(res.Size)/(1024*1024))
+	fmt.Printf("  SHA-256: %s\n", res.SHA256)
+	if res.Resumed {
+		fmt.Printf("  Resumed from a partial download\n")
 	}
-
-	return nil
 }
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// markdownToHTML converts the Markdown subset NotebookLM produces in
+// reports and notes: ATX headings, paragraphs, bullet and numbered lists,
+// block quotes, fenced code, and bold, italic, code and http(s) link spans.
+// Anything else is rendered as paragraph text.
+func markdownToHTML(md string) string {
+	var b strings.Builder
+	var para []string
+	list := ""
+
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + inlineMarkdown(strings.Join(para, "\n")) + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			flushPara()
+			closeList()
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			b.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>\n")
+		case trimmed == "":
+			flushPara()
+			closeList()
+		case headingLevel(trimmed) > 0:
+			flushPara()
+			closeList()
+			n := headingLevel(trimmed)
+			tag := "h" + string(rune('0'+n))
+			b.WriteString("<" + tag + ">" + inlineMarkdown(strings.TrimSpace(trimmed[n:])) + "</" + tag + ">\n")
+		case strings.HasPrefix(trimmed, "> "):
+			flushPara()
+			closeList()
+			b.WriteString("<blockquote>" + inlineMarkdown(strings.TrimPrefix(trimmed, "> ")) + "</blockquote>\n")
+		case bulletItem.MatchString(trimmed):
+			flushPara()
+			openList("ul")
+			b.WriteString("<li>" + inlineMarkdown(bulletItem.ReplaceAllString(trimmed, "")) + "</li>\n")
+		case orderedItem.MatchString(trimmed):
+			flushPara()
+			openList("ol")
+			b.WriteString("<li>" + inlineMarkdown(orderedItem.ReplaceAllString(trimmed, "")) + "</li>\n")
+		default:
+			closeList()
+			para = append(para, trimmed)
+		}
+	}
+	flushPara()
+	closeList()
+	return b.String()
+}
+
+var (
+	bulletItem  = regexp.MustCompile(`^[-*+]\s+`)
+	orderedItem = regexp.MustCompile(`^\d+[.)]\s+`)
+
+	codeSpan   = regexp.MustCompile("`([^`]+)`")
+	boldSpan   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicSpan = regexp.MustCompile(`\*([^*]+)\*|\b_([^_]+)_\b`)
+	linkSpan   = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+)
+
+// headingLevel returns the level of an ATX heading line, or 0.
+func headingLevel(line string) int {
+	n := 0
+	for n < len(line) && line[n] == '#' {
+		n++
+	}
+	if n == 0 || n > 6 || n == len(line) || line[n] != ' ' {
+		return 0
+	}
+	return n
+}
+
+// inlineMarkdown escapes s and converts inline spans to HTML.
+func inlineMarkdown(s string) string {
+	s = html.EscapeString(s)
+	s = codeSpan.ReplaceAllString(s, "<code>$1</code>")
+	s = boldSpan.ReplaceAllString(s, "<strong>$1$2</strong>")
+	s = italicSpan.ReplaceAllString(s, "<em>$1$2</em>")
+	return linkSpan.ReplaceAllString(s, `<a href="$2">$1</a>`)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+	"golang.org/x/term"
+)
+
+// MediaDownloadOptions contains the options for media-download.
+type MediaDownloadOptions struct {
+	All   bool
+	Audio bool
+	Video bool
+	Dir   string
+}
+
+// kinds returns the overview kinds to fetch; all of them by default.
+func (o *MediaDownloadOptions) kinds() []string {
+	if o.All || (!o.Audio && !o.Video) {
+		return []string{"audio", "video"}
+	}
+	var kinds []string
+	if o.Audio {
+		kinds = append(kinds, "audio")
+	}
+	if o.Video {
+		kinds = append(kinds, "video")
+	}
+	return kinds
+}
+
+// parseMediaDownloadFlags parses `media-download <notebook-id> [--all|--audio|--video] [--dir path]`.
+func parseMediaDownloadFlags(args []string) (*MediaDownloadOptions, []string, error) {
+	opts := &MediaDownloadOptions{}
+	fs := newCommandFlags("media-download")
+	fs.BoolVar(&opts.All, "all", false, "download every audio and video overview (default)")
+	fs.BoolVar(&opts.Audio, "audio", false, "download the audio overview")
+	fs.BoolVar(&opts.Video, "video", false, "download the video overview")
+	fs.StringVar(&opts.Dir, "dir", ".", "directory to download into")
+	fs.StringVar(&opts.Dir, "d", ".", "directory to download into")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateMediaDownloadArgs(args []string) error {
+	_, pos, err := parseMediaDownloadFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm media-download <notebook-id> [--all|--audio|--video] [--dir path]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// mediaDownload implements media-download. Each overview is saved as
+// <dir>/<kind>_overview_<notebook-id>.<ext>, with the extension chosen
+// from the downloaded content.
+func mediaDownload(c *api.Client, args []string) error {
+	opts, pos, err := parseMediaDownloadFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return fmt.Errorf("media download: %w", err)
+	}
+
+	var results []*api.DownloadResult
+	var kinds []string
+	var failed int
+	for _, kind := range opts.kinds() {
+		base := filepath.Join(opts.Dir, fmt.Sprintf("%s_overview_%s", kind, notebookID))
+		res, err := saveOverview(c, kind, notebookID, base)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "nlm: %s overview: %v\n", kind, err)
+			continue
+		}
+		results = append(results, res)
+		kinds = append(kinds, kind)
+	}
+
+	if len(results) > 0 {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+		_, _ = fmt.Fprintln(w, "KIND\tPATH\tSIZE\tSHA256")
+		for i, res := range results {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", kinds[i], res.Path, formatBytes(res.Size), res.SHA256)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("media download: %d of %d downloads failed", failed, failed+len(results))
+	}
+	return nil
+}
+
+// saveOverview fetches one overview of the given kind and saves it to path.
+func saveOverview(c *api.Client, kind, notebookID, path string) (*api.DownloadResult, error) {
+	opts := api.DownloadOptions{Progress: downloadProgress(kind + " overview")}
+	switch kind {
+	case "audio":
+		r, err := c.DownloadAudioOverview(notebookID)
+		if err != nil {
+			return nil, err
+		}
+		return c.SaveAudio(r, path, opts)
+	case "video":
+		r, err := c.DownloadVideoOverview(notebookID)
+		if err != nil {
+			return nil, err
+		}
+		return c.SaveVideo(r, path, opts)
+	}
+	return nil, fmt.Errorf("unknown media kind %q", kind)
+}
+
+// downloadProgress returns a progress callback that redraws a status line
+// on stderr at most a few times a second. It is silent when stderr is not
+// a terminal.
+func downloadProgress(label string) func(api.DownloadProgress) {
+	if !term.IsTerminal(int(os.Stderr.Fd())) {
+		return nil
+	}
+	var last time.Time
+	return func(p api.DownloadProgress) {
+		done := p.Total > 0 && p.Written >= p.Total
+		if !done && time.Since(last) < 200*time.Millisecond {
+			return
+		}
+		last = time.Now()
+		line := fmt.Sprintf("Downloading %s: %s", label, formatBytes(p.Written))
+		if p.Total > 0 {
+			line += fmt.Sprintf(" / %s (%d%%)", formatBytes(p.Total), p.Written*100/p.Total)
+		}
+		fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+		if done {
+			fmt.Fprintln(os.Stderr)
+		}
+	}
+}
+
+// formatBytes renders a byte count with a binary unit.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMediaDownloadKinds(t *testing.T) {
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"nb"}, []string{"audio", "video"}},
+		{[]string{"nb", "--all"}, []string{"audio", "video"}},
+		{[]string{"nb", "--audio"}, []string{"audio"}},
+		{[]string{"--video", "nb", "-d", "out"}, []string{"video"}},
+	}
+	for _, tt := range tests {
+		opts, pos, err := parseMediaDownloadFlags(tt.args)
+		if err != nil {
+			t.Fatalf("parseMediaDownloadFlags(%q) error: %v", tt.args, err)
+		}
+		if len(pos) != 1 || pos[0] != "nb" {
+			t.Errorf("parseMediaDownloadFlags(%q) positionals = %q", tt.args, pos)
+		}
+		if got := opts.kinds(); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseMediaDownloadFlags(%q) kinds = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:             "512 B",
+		1536:            "1.5 KiB",
+		5 * 1024 * 1024: "5.0 MiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,547 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+
+	"github.com/tmc/nlm/internal/api"
+	"gopkg.in/yaml.v3"
+)
+
+// noteFrontMatter is the YAML header of an exported note. Modified is the
+// server's last-modified time when the file was written; notes-import and
+// note-edit use it to detect edits made elsewhere in the meantime.
+type noteFrontMatter struct {
+	ID       string    `yaml:"id,omitempty"`
+	Title    string    `yaml:"title"`
+	Modified time.Time `yaml:"modified,omitempty"`
+	Exported time.Time `yaml:"exported,omitempty"`
+}
+
+// noteFile is a note stored as Markdown with YAML front matter.
+type noteFile struct {
+	noteFrontMatter
+	Body string
+}
+
+const frontMatterDelim = "---"
+
+// marshal renders f as front matter followed by the note body.
+func (f *noteFile) marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelim + "\n")
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&f.noteFrontMatter); err != nil {
+		return nil, fmt.Errorf("encode front matter: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("encode front matter: %w", err)
+	}
+	buf.WriteString(frontMatterDelim + "\n\n")
+	if body := normalizeNoteText(f.Body); body != "" {
+		buf.WriteString(body + "\n")
+	}
+	return buf.Bytes(), nil
+}
+
+// parseNoteFile parses a Markdown note. Files without front matter are
+// accepted as new notes; their title is taken from a leading "# " heading,
+// falling back to fallbackTitle.
+func parseNoteFile(data []byte, fallbackTitle string) (*noteFile, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+
+	f := &noteFile{}
+	if rest, ok := strings.CutPrefix(text, frontMatterDelim+"\n"); ok {
+		header, body, found := strings.Cut(rest, "\n"+frontMatterDelim+"\n")
+		if !found {
+			header, found = strings.CutSuffix(rest, "\n"+frontMatterDelim)
+		}
+		if !found {
+			return nil, fmt.Errorf("unterminated front matter")
+		}
+		if err := yaml.Unmarshal([]byte(header), &f.noteFrontMatter); err != nil {
+			return nil, fmt.Errorf("parse front matter: %w", err)
+		}
+		text = body
+	}
+	f.Body = normalizeNoteText(text)
+
+	if f.Title == "" {
+		if line, _, _ := strings.Cut(f.Body, "\n"); strings.HasPrefix(line, "# ") {
+			f.Title = strings.TrimSpace(strings.TrimPrefix(line, "# "))
+		} else {
+			f.Title = fallbackTitle
+		}
+	}
+	return f, nil
+}
+
+// normalizeNoteText trims the blank lines that separate the body from the
+// front matter and the end of the file, so round trips compare equal.
+func normalizeNoteText(s string) string {
+	return strings.TrimRight(strings.TrimLeft(s, "\n"), "\n")
+}
+
+// newNoteFile builds the file representation of a note.
+func newNoteFile(n *api.Note) *noteFile {
+	return &noteFile{
+		noteFrontMatter: noteFrontMatter{
+			ID:       noteIDOf(n),
+			Title:    n.GetTitle(),
+			Modified: noteModified(n),
+		},
+		Body: n.Content,
+	}
+}
+
+// noteIDOf extracts the note ID from its SourceId wrapper.
+func noteIDOf(n *api.Note) string {
+	if id := n.GetSourceId().GetSourceId(); id != "" {
+		return id
+	}
+	return n.GetSourceId().String()
+}
+
+// noteModified returns the server's last-modified time for n, or the zero
+// time if it is unknown.
+func noteModified(n *api.Note) time.Time {
+	if meta := n.GetMetadata(); meta != nil && meta.LastModifiedTime != nil {
+		return meta.LastModifiedTime.AsTime().UTC()
+	}
+	return time.Time{}
+}
+
+// findNote returns the note with the given ID.
+func findNote(notes []*api.Note, noteID string) *api.Note {
+	for _, n := range notes {
+		if noteIDOf(n) == noteID {
+			return n
+		}
+	}
+	return nil
+}
+
+// noteChanged reports whether the server copy of a note differs from the
+// copy a local edit started from.
+func noteChanged(base *noteFile, current *api.Note) bool {
+	if current == nil {
+		return true
+	}
+	if m := noteModified(current); !m.IsZero() && !base.Modified.IsZero() && !m.Equal(base.Modified) {
+		return true
+	}
+	return current.GetTitle() != base.Title || normalizeNoteText(current.Content) != normalizeNoteText(base.Body)
+}
+
+// slugify turns a title into a lowercase, dash-separated file name stem.
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 60 {
+			break
+		}
+	}
+	return strings.Trim(b.String(), "-")
+}
+
+// noteFileNames assigns a unique Markdown file name to each note, based
+// on its title. Notes with clashing or empty titles get their ID appended.
+func noteFileNames(notes []*api.Note) []string {
+	stems := make([]string, len(notes))
+	count := map[string]int{}
+	for i, n := range notes {
+		stems[i] = slugify(n.GetTitle())
+		count[stems[i]]++
+	}
+	names := make([]string, len(notes))
+	for i, n := range notes {
+		stem := stems[i]
+		switch {
+		case stem == "":
+			stem = noteIDOf(n)
+		case count[stem] > 1:
+			stem += "-" + noteIDOf(n)
+		}
+		names[i] = stem + ".md"
+	}
+	return names
+}
+
+// NoteEditOptions contains the options for note-edit.
+type NoteEditOptions struct {
+	Force bool
+}
+
+// parseNoteEditFlags parses `note-edit <notebook-id> <note-id> [--force]`.
+func parseNoteEditFlags(args []string) (*NoteEditOptions, []string, error) {
+	opts := &NoteEditOptions{}
+	fs := newCommandFlags("note-edit")
+	fs.BoolVar(&opts.Force, "force", false, "save even if the note changed on the server while editing")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateNoteEditArgs(args []string) error {
+	_, pos, err := parseNoteEditFlags(args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm note-edit <notebook-id> <note-id> [--force]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// editNote implements note-edit. The note is written to a temporary
+// Markdown file and opened in $VISUAL or $EDITOR. On exit the note is
+// re-fetched; if it changed on the server in the meantime the edit is not
+// saved unless --force is given, and the edited file is kept.
+func editNote(c *api.Client, args []string) error {
+	opts, pos, err := parseNoteEditFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID, noteID := pos[0], pos[1]
+
+	notes, err := c.GetNotes(notebookID)
+	if err != nil {
+		return fmt.Errorf("edit note: %w", err)
+	}
+	note := findNote(notes, noteID)
+	if note == nil {
+		return fmt.Errorf("edit note: note %s not found in notebook %s", noteID, notebookID)
+	}
+	base := newNoteFile(note)
+	data, err := base.marshal()
+	if err != nil {
+		return fmt.Errorf("edit note: %w", err)
+	}
+
+	dir, err := os.MkdirTemp("", "nlm-note-")
+	if err != nil {
+		return fmt.Errorf("edit note: %w", err)
+	}
+	path := filepath.Join(dir, noteFileNames([]*api.Note{note})[0])
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("edit note: %w", err)
+	}
+	keep := false
+	defer func() {
+		if !keep {
+			os.RemoveAll(dir)
+		}
+	}()
+
+	if err := runEditor(path); err != nil {
+		return fmt.Errorf("edit note: %w", err)
+	}
+	edited, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("edit note: %w", err)
+	}
+	if bytes.Equal(edited, data) {
+		fmt.Fprintf(os.Stderr, "No changes to note %s\n", noteID)
+		return nil
+	}
+	f, err := parseNoteFile(edited, base.Title)
+	if err != nil {
+		keep = true
+		return fmt.Errorf("edit note: %w (your edits are in %s)", err, path)
+	}
+	if f.Title == base.Title && f.Body == normalizeNoteText(base.Body) {
+		fmt.Fprintf(os.Stderr, "No changes to note %s\n", noteID)
+		return nil
+	}
+
+	if !opts.Force {
+		notes, err := c.GetNotes(notebookID)
+		if err != nil {
+			keep = true
+			return fmt.Errorf("edit note: check for conflicts: %w (your edits are in %s)", err, path)
+		}
+		if noteChanged(base, findNote(notes, noteID)) {
+			keep = true
+			return fmt.Errorf("edit note: note %s changed on the server while you were editing; your edits are in %s (rerun with --force to overwrite)", noteID, path)
+		}
+	}
+
+	if _, err := c.MutateNote(notebookID, noteID, f.Body, f.Title); err != nil {
+		keep = true
+		return fmt.Errorf("edit note: %w (your edits are in %s)", err, path)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Updated note: %s\n", f.Title)
+	return nil
+}
+
+// runEditor opens path in the user's editor and waits for it to exit.
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
+	}
+	// Editors are often configured with arguments, e.g. "code --wait".
+	fields := strings.Fields(editor)
+	cmd := exec.Command(fields[0], append(fields[1:], path)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("run editor %q: %w", editor, err)
+	}
+	return nil
+}
+
+// NotesExportOptions contains the options for notes-export.
+type NotesExportOptions struct {
+	Dir string
+}
+
+// parseNotesExportFlags parses `notes-export <notebook-id> [-o dir]`.
+func parseNotesExportFlags(args []string) (*NotesExportOptions, []string, error) {
+	opts := &NotesExportOptions{}
+	fs := newCommandFlags("notes-export")
+	fs.StringVar(&opts.Dir, "o", ".", "directory to write the notes into")
+	fs.StringVar(&opts.Dir, "output", ".", "directory to write the notes into")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateNotesExportArgs(args []string) error {
+	_, pos, err := parseNotesExportFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm notes-export <notebook-id> [-o dir]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// exportNotes implements notes-export, writing one Markdown file per note
+// and printing the path of each.
+func exportNotes(c *api.Client, args []string) error {
+	opts, pos, err := parseNotesExportFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+
+	notes, err := c.GetNotes(notebookID)
+	if err != nil {
+		return fmt.Errorf("export notes: %w", err)
+	}
+	if len(notes) == 0 {
+		fmt.Fprintln(os.Stderr, "No notes found in this notebook.")
+		return nil
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return fmt.Errorf("export notes: %w", err)
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, name := range noteFileNames(notes) {
+		f := newNoteFile(notes[i])
+		f.Exported = now
+		data, err := f.marshal()
+		if err != nil {
+			return fmt.Errorf("export notes: %w", err)
+		}
+		path := filepath.Join(opts.Dir, name)
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return fmt.Errorf("export notes: %w", err)
+		}
+		fmt.Println(path)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Exported %d notes to %s\n", len(notes), opts.Dir)
+	return nil
+}
+
+// NotesImportOptions contains the options for notes-import.
+type NotesImportOptions struct {
+	Force  bool
+	DryRun bool
+}
+
+// parseNotesImportFlags parses `notes-import <notebook-id> <dir> [--force] [--dry-run]`.
+func parseNotesImportFlags(args []string) (*NotesImportOptions, []string, error) {
+	opts := &NotesImportOptions{}
+	fs := newCommandFlags("notes-import")
+	fs.BoolVar(&opts.Force, "force", false, "overwrite notes that changed on the server since export")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "show what would change without saving")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateNotesImportArgs(args []string) error {
+	_, pos, err := parseNotesImportFlags(args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm notes-import <notebook-id> <dir> [--force] [--dry-run]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// Actions reported by notes-import.
+const (
+	noteCreated   = "created"
+	noteUpdated   = "updated"
+	noteUnchanged = "unchanged"
+	noteConflict  = "conflict"
+)
+
+// errNoteConflict reports that a note changed on the server after the
+// file being imported was exported.
+var errNoteConflict = errors.New("note changed on the server since export")
+
+// planNoteImport decides what importing f does, given the notebook's
+// current notes.
+func planNoteImport(f *noteFile, notes []*api.Note, force bool) string {
+	note := findNote(notes, f.ID)
+	if f.ID == "" || note == nil {
+		return noteCreated
+	}
+	if note.GetTitle() == f.Title && normalizeNoteText(note.Content) == f.Body {
+		return noteUnchanged
+	}
+	if !force {
+		if m := noteModified(note); !m.IsZero() && m.After(f.Modified) {
+			return noteConflict
+		}
+	}
+	return noteUpdated
+}
+
+// importNotes implements notes-import. Every *.md file in the directory
+// is created as a new note, or updates the note named by its front-matter
+// id. New IDs and modification times are written back into the files so
+// a later import updates instead of duplicating.
+func importNotes(c *api.Client, args []string) error {
+	opts, pos, err := parseNotesImportFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID, dir := pos[0], pos[1]
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+	if err != nil {
+		return fmt.Errorf("import notes: %w", err)
+	}
+	sort.Strings(paths)
+	if len(paths) == 0 {
+		return fmt.Errorf("import notes: no .md files in %s", dir)
+	}
+	notes, err := c.GetNotes(notebookID)
+	if err != nil {
+		return fmt.Errorf("import notes: %w", err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ACTION\tFILE\tNOTE ID")
+	var failed int
+	for _, path := range paths {
+		action, id, err := importNoteFile(c, notebookID, path, notes, opts)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "nlm: %s: %v\n", path, err)
+		}
+		if action != "" {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", action, path, id)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("import notes: %d of %d files failed", failed, len(paths))
+	}
+	return nil
+}
+
+// importNoteFile imports one file and returns the action taken and the
+// note ID.
+func importNoteFile(c *api.Client, notebookID, path string, notes []*api.Note, opts *NotesImportOptions) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", err
+	}
+	title := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	f, err := parseNoteFile(data, title)
+	if err != nil {
+		return "", "", err
+	}
+
+	action := planNoteImport(f, notes, opts.Force)
+	switch {
+	case action == noteConflict:
+		return action, f.ID, fmt.Errorf("%w (rerun with --force to overwrite)", errNoteConflict)
+	case action == noteUnchanged || opts.DryRun:
+		return action, f.ID, nil
+	}
+
+	var note *api.Note
+	if action == noteCreated {
+		note, err = c.CreateNote(notebookID, f.Title, f.Body)
+	} else {
+		note, err = c.MutateNote(notebookID, f.ID, f.Body, f.Title)
+	}
+	if err != nil {
+		return "", f.ID, err
+	}
+
+	if id := note.GetSourceId().GetSourceId(); id != "" {
+		f.ID = id
+	}
+	f.Modified = noteModified(note)
+	if f.Modified.IsZero() {
+		f.Modified = time.Now().UTC().Truncate(time.Second)
+	}
+	out, err := f.marshal()
+	if err == nil {
+		err = os.WriteFile(path, out, 0o644)
+	}
+	if err != nil {
+		return action, f.ID, fmt.Errorf("record note id: %w", err)
+	}
+	return action, f.ID, nil
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testNote(id, title, content string, modified time.Time) *api.Note {
+	return &pb.Source{
+		SourceId: &pb.SourceId{SourceId: id},
+		Title:    title,
+		Content:  content,
+		Metadata: &pb.SourceMetadata{LastModifiedTime: timestamppb.New(modified)},
+	}
+}
+
+func TestNoteFileRoundTrip(t *testing.T) {
+	modified := time.Date(2025, 3, 4, 5, 6, 7, 0, time.UTC)
+	f := newNoteFile(testNote("note1", "Ideas: part 2", "# Ideas\n\n- one\n- two\n", modified))
+	data, err := f.marshal()
+	if err != nil {
+		t.Fatalf("marshal() error: %v", err)
+	}
+	text := string(data)
+	for _, want := range []string{"---\nid: note1\n", "title: 'Ideas: part 2'\n", "modified: 2025-03-04T05:06:07Z\n", "---\n\n# Ideas\n"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("marshal() output missing %q:\n%s", want, text)
+		}
+	}
+	if strings.Contains(text, "exported:") {
+		t.Errorf("zero exported time should be omitted:\n%s", text)
+	}
+
+	got, err := parseNoteFile(data, "fallback")
+	if err != nil {
+		t.Fatalf("parseNoteFile() error: %v", err)
+	}
+	if got.ID != "note1" || got.Title != "Ideas: part 2" || !got.Modified.Equal(modified) {
+		t.Errorf("front matter = %+v", got.noteFrontMatter)
+	}
+	if got.Body != "# Ideas\n\n- one\n- two" {
+		t.Errorf("body = %q", got.Body)
+	}
+}
+
+func TestParseNoteFileWithoutFrontMatter(t *testing.T) {
+	f, err := parseNoteFile([]byte("# Meeting notes\r\n\r\nagenda\r\n"), "meeting")
+	if err != nil {
+		t.Fatalf("parseNoteFile() error: %v", err)
+	}
+	if f.ID != "" || f.Title != "Meeting notes" || f.Body != "# Meeting notes\n\nagenda" {
+		t.Errorf("parseNoteFile() = %+v", f)
+	}
+
+	f, err = parseNoteFile([]byte("plain text"), "todo")
+	if err != nil {
+		t.Fatalf("parseNoteFile() error: %v", err)
+	}
+	if f.Title != "todo" {
+		t.Errorf("title = %q, want fallback", f.Title)
+	}
+
+	if _, err := parseNoteFile([]byte("---\nid: x\nbody"), "x"); err == nil {
+		t.Error("parseNoteFile() with unterminated front matter succeeded")
+	}
+}
+
+func TestNoteFileNames(t *testing.T) {
+	notes := []*api.Note{
+		testNote("a1", "Reading List", "", time.Time{}),
+		testNote("b2", "reading list!", "", time.Time{}),
+		testNote("c3", "", "", time.Time{}),
+		testNote("d4", "Q&A / Überblick", "", time.Time{}),
+	}
+	got := noteFileNames(notes)
+	want := []string{"reading-list-a1.md", "reading-list-b2.md", "c3.md", "q-a-überblick.md"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("noteFileNames()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestPlanNoteImport(t *testing.T) {
+	exported := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	notes := []*api.Note{
+		testNote("same", "Same", "body", exported),
+		testNote("edited", "Edited", "old", exported),
+		testNote("remote", "Remote", "changed remotely", exported.Add(time.Hour)),
+	}
+	file := func(id, title, body string) *noteFile {
+		return &noteFile{noteFrontMatter: noteFrontMatter{ID: id, Title: title, Modified: exported}, Body: body}
+	}
+	tests := []struct {
+		name  string
+		f     *noteFile
+		force bool
+		want  string
+	}{
+		{"new", file("", "New", "x"), false, noteCreated},
+		{"deleted on server", file("gone", "Gone", "x"), false, noteCreated},
+		{"unchanged", file("same", "Same", "body"), false, noteUnchanged},
+		{"local edit", file("edited", "Edited", "new"), false, noteUpdated},
+		{"both edited", file("remote", "Remote", "local"), false, noteConflict},
+		{"both edited forced", file("remote", "Remote", "local"), true, noteUpdated},
+	}
+	for _, tt := range tests {
+		if got := planNoteImport(tt.f, notes, tt.force); got != tt.want {
+			t.Errorf("%s: planNoteImport() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestNoteChanged(t *testing.T) {
+	modified := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	base := newNoteFile(testNote("n", "T", "body\n", modified))
+	if noteChanged(base, testNote("n", "T", "body", modified)) {
+		t.Error("identical note reported as changed")
+	}
+	if !noteChanged(base, testNote("n", "T", "body", modified.Add(time.Second))) {
+		t.Error("newer modification time not reported")
+	}
+	if !noteChanged(base, testNote("n", "T2", "body", modified)) {
+		t.Error("title change not reported")
+	}
+	if !noteChanged(base, nil) {
+		t.Error("deleted note not reported")
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/repo"
+	"github.com/tmc/nlm/internal/split"
+)
+
+// RepoOptions contains the options for add-repo.
+type RepoOptions struct {
+	Ref           string
+	Include       stringList
+	MaxSourceSize int64
+	StatePath     string
+	Limit         int
+	DryRun        bool
+}
+
+// parseRepoFlags parses `add-repo <notebook-id> <path-or-git-url> [--ref main]
+// [--include '*.go'] [--max-source-size 20MB] [--state file] [--limit N] [--dry-run]`.
+func parseRepoFlags(args []string) (*RepoOptions, []string, error) {
+	opts := &RepoOptions{}
+	var maxSize string
+	fs := newCommandFlags("add-repo")
+	fs.StringVar(&opts.Ref, "ref", "", "branch, tag or commit to read instead of the work tree")
+	fs.Var(&opts.Include, "include", "only add files matching these patterns, e.g. '*.go' (repeatable)")
+	fs.StringVar(&maxSize, "max-source-size", "200MB", "largest source; bigger directories are split and bigger files skipped")
+	fs.StringVar(&opts.StatePath, "state", "", "state file (default ~/.nlm/repos/<notebook-id>-<repo>.json)")
+	fs.IntVar(&opts.Limit, "limit", defaultSourceLimit, "the notebook's source limit")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "list the sources that would change without changing them")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.MaxSourceSize, err = parseByteSize(maxSize); err != nil {
+		return nil, nil, fmt.Errorf("--max-source-size: %w", err)
+	}
+	for _, pat := range opts.Include {
+		if _, err := filepath.Match(pat, ""); err != nil {
+			return nil, nil, fmt.Errorf("--include %q: %w", pat, err)
+		}
+	}
+	if opts.Limit < 1 {
+		return nil, nil, fmt.Errorf("--limit must be positive")
+	}
+	return opts, pos, nil
+}
+
+func validateRepoArgs(args []string) error {
+	_, pos, err := parseRepoFlags(args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm add-repo <notebook-id> <path-or-git-url> [--ref main] [--include '*.go'] [--max-source-size 20MB] [--state file] [--limit N] [--dry-run]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// parseByteSize parses sizes such as "512KB", "20MB", "1GB" or a plain
+// number of bytes. Units are powers of 1024.
+func parseByteSize(s string) (int64, error) {
+	t := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}, {"B", 1}} {
+		if strings.HasSuffix(t, u.suffix) {
+			t, mult = strings.TrimSpace(strings.TrimSuffix(t, u.suffix)), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(t, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q: use e.g. 512KB, 20MB or 1GB", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// repoStatePath returns where the state of repository id in a notebook is
+// kept by default.
+func repoStatePath(notebookID, id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return filepath.Join(nlmDir(), "repos", notebookID+"-"+hex.EncodeToString(sum[:6])+".json")
+}
+
+// addRepo implements add-repo: it bundles a repository into Markdown
+// sources and adds those that are new or changed since the last run.
+func addRepo(c *api.Client, args []string) error {
+	opts, pos, err := parseRepoFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID, target := pos[0], pos[1]
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if repo.IsRemote(target) {
+		fmt.Fprintf(os.Stderr, "Cloning %s...\n", target)
+	}
+	co, err := repo.Open(ctx, target, opts.Ref)
+	if err != nil {
+		return fmt.Errorf("open repository: %w", err)
+	}
+	defer co.Close()
+
+	statePath := opts.StatePath
+	if statePath == "" {
+		statePath = repoStatePath(notebookID, co.ID)
+	}
+	st, err := repo.LoadState(statePath, notebookID, co.ID)
+	if err != nil {
+		return err
+	}
+
+	// Sources that are not this repository's limit how many bundles fit.
+	// One slot is kept free so that a changed bundle can be added before
+	// its previous version is deleted.
+	p, err := c.GetProject(notebookID)
+	if err != nil {
+		return fmt.Errorf("get notebook: %w", err)
+	}
+	ours, others := st.SourceIDs(), 0
+	for _, src := range p.GetSources() {
+		if !ours[src.GetSourceId().GetSourceId()] {
+			others++
+		}
+	}
+	room := opts.Limit - others - 1
+	if room < 1 {
+		return fmt.Errorf("notebook has %d of %d sources; no room for the repository", others, opts.Limit)
+	}
+
+	files, err := co.Files(ctx, opts.MaxSourceSize)
+	if err != nil {
+		return fmt.Errorf("list files: %w", err)
+	}
+	commits, err := co.CommitSummary(ctx, repo.DefaultCommits)
+	if err != nil {
+		return fmt.Errorf("summarize commits: %w", err)
+	}
+	bundles, skipped := repo.Bundles(co.Name, files, commits, repo.Options{
+		Include:    opts.Include,
+		Limits:     split.Limits{MaxWords: split.DefaultLimits.MaxWords, MaxBytes: opts.MaxSourceSize},
+		MaxBundles: room,
+	})
+	if len(skipped) > 0 {
+		fmt.Fprintf(os.Stderr, "Skipped %d files: %s.\n", len(skipped), repo.Describe(skipped))
+	}
+	if len(bundles) == 0 {
+		return fmt.Errorf("no files to add from %s", target)
+	}
+	if len(bundles) > room {
+		return fmt.Errorf("repository needs %d sources but the notebook has room for %d; narrow it with --include or raise --max-source-size", len(bundles), room)
+	}
+
+	counts := map[repo.Action]int{}
+	if opts.DryRun {
+		for _, ch := range repo.Plan(st, bundles) {
+			counts[ch.Action]++
+			if ch.Action != repo.Unchanged {
+				fmt.Printf("%s\t%s\n", ch.Action, ch.Title)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Dry run: %s.\n", describeRepoChanges(counts))
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Syncing %d files from %s as %d sources...\n", len(files)-len(skipped), co.Name, len(bundles))
+	syncErr := repo.Sync(c, notebookID, st, bundles, func(ch repo.Change) {
+		counts[ch.Action]++
+		fmt.Printf("%s\t%s\t%s\n", ch.Action, ch.Title, ch.SourceID)
+	})
+	// Save what succeeded even if some changes failed.
+	if err := st.Save(statePath); err != nil {
+		return err
+	}
+	if syncErr != nil {
+		return fmt.Errorf("sync repository: %w", syncErr)
+	}
+	counts[repo.Unchanged] = len(bundles) - counts[repo.Added] - counts[repo.Updated]
+	fmt.Fprintf(os.Stderr, "✅ %s: %s.\n", co.Name, describeRepoChanges(counts))
+	return nil
+}
+
+// describeRepoChanges summarizes counts, e.g. "2 added, 1 updated,
+// 0 removed, 14 unchanged".
+func describeRepoChanges(counts map[repo.Action]int) string {
+	var parts []string
+	for _, a := range []repo.Action{repo.Added, repo.Updated, repo.Removed, repo.Unchanged} {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[a], a))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/tmc/nlm/internal/repo"
+)
+
+func TestParseRepoFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		want    RepoOptions
+		wantErr bool
+	}{
+		{[]string{"nb", "."}, RepoOptions{MaxSourceSize: 200 << 20, Limit: defaultSourceLimit}, false},
+		{[]string{"nb", "https://github.com/tmc/nlm", "--ref", "main", "--include", "*.go,*.md", "--include", "Makefile", "--max-source-size", "20MB", "--dry-run"},
+			RepoOptions{Ref: "main", Include: stringList{"*.go", "*.md", "Makefile"}, MaxSourceSize: 20 << 20, Limit: defaultSourceLimit, DryRun: true}, false},
+		{[]string{"nb", ".", "--include", "[a-"}, RepoOptions{}, true},
+		{[]string{"nb", ".", "--max-source-size", "0"}, RepoOptions{}, true},
+		{[]string{"nb", ".", "--limit", "0"}, RepoOptions{}, true},
+	}
+	for _, tt := range tests {
+		opts, _, err := parseRepoFlags(tt.args)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseRepoFlags(%v) error = %v", tt.args, err)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(*opts, tt.want) {
+			t.Errorf("parseRepoFlags(%v) = %+v, want %+v", tt.args, *opts, tt.want)
+		}
+	}
+}
+
+func TestParseByteSize(t *testing.T) {
+	for s, want := range map[string]int64{
+		"1024": 1024, "512KB": 512 << 10, "20mb": 20 << 20, "1.5G": 3 << 29, "2 MB": 2 << 20,
+	} {
+		if got, err := parseByteSize(s); err != nil || got != want {
+			t.Errorf("parseByteSize(%q) = %d, %v, want %d", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "MB", "-1KB", "lots"} {
+		if _, err := parseByteSize(s); err == nil {
+			t.Errorf("parseByteSize(%q) succeeded", s)
+		}
+	}
+}
+
+func TestDescribeRepoChanges(t *testing.T) {
+	got := describeRepoChanges(map[repo.Action]int{repo.Added: 2, repo.Unchanged: 14})
+	if want := "2 added, 0 updated, 0 removed, 14 unchanged"; got != want {
+		t.Errorf("describeRepoChanges = %q, want %q", got, want)
+	}
+}
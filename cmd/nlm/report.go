@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+// reportSuggest implements report-suggest, printing the numbered report
+// suggestions that report-create --suggestion accepts.
+func reportSuggest(c *api.Client, notebookID string) error {
+	fmt.Fprintf(os.Stderr, "Generating report suggestions...\n")
+	suggestions, err := reportSuggestions(c, notebookID)
+	if err != nil {
+		return err
+	}
+	if len(suggestions) == 0 {
+		fmt.Println("No report suggestions for this notebook.")
+		return nil
+	}
+	for i, s := range suggestions {
+		fmt.Printf("%d. %s\n", i+1, s)
+	}
+	return nil
+}
+
+func reportSuggestions(c *api.Client, notebookID string) ([]string, error) {
+	resp, err := c.GenerateReportSuggestions(notebookID)
+	if err != nil {
+		return nil, err
+	}
+	var suggestions []string
+	for _, s := range resp.GetSuggestions() {
+		if s = strings.TrimSpace(s); s != "" {
+			suggestions = append(suggestions, s)
+		}
+	}
+	return suggestions, nil
+}
+
+// ReportCreateOptions contains the options for report-create.
+type ReportCreateOptions struct {
+	Suggestion int
+	Prompt     string
+	Sources    stringList
+	WaitFlags
+}
+
+// parseReportCreateFlags parses
+// `report-create <notebook-id> --suggestion N|--prompt text [--sources id,...] [wait flags]`.
+func parseReportCreateFlags(args []string) (*ReportCreateOptions, []string, error) {
+	opts := &ReportCreateOptions{}
+	fs := newCommandFlags("report-create")
+	fs.IntVar(&opts.Suggestion, "suggestion", 0, "create the Nth suggestion from report-suggest")
+	fs.StringVar(&opts.Prompt, "prompt", "", "describe the report to create")
+	fs.Var(&opts.Sources, "sources", "base the report on these source IDs only (repeatable)")
+	opts.WaitFlags.register(fs)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case opts.Suggestion < 0:
+		return nil, nil, fmt.Errorf("--suggestion must be 1 or more")
+	case opts.Suggestion > 0 && opts.Prompt != "":
+		return nil, nil, fmt.Errorf("--suggestion and --prompt cannot be combined")
+	case opts.Suggestion == 0 && strings.TrimSpace(opts.Prompt) == "":
+		return nil, nil, fmt.Errorf("give the report to create with --suggestion N or --prompt text")
+	case opts.Timeout <= 0:
+		return nil, nil, fmt.Errorf("--timeout must be positive")
+	}
+	return opts, pos, nil
+}
+
+func validateReportCreateArgs(args []string) error {
+	_, pos, err := parseReportCreateFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm report-create <notebook-id> --suggestion N|--prompt text [--sources id,...] [--wait] [--timeout 20m] [--then-download file]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// reportCreate implements report-create. With --then-download the finished
+// report is exported to the file, in the format its extension names.
+func reportCreate(c *api.Client, args []string) error {
+	opts, pos, err := parseReportCreateFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID := pos[0]
+
+	ro := api.ReportOptions{Prompt: opts.Prompt, SourceIDs: opts.Sources}
+	if opts.Suggestion > 0 {
+		suggestions, err := reportSuggestions(c, notebookID)
+		if err != nil {
+			return fmt.Errorf("create report: %w", err)
+		}
+		if opts.Suggestion > len(suggestions) {
+			return fmt.Errorf("create report: suggestion %d out of range (notebook has %d)", opts.Suggestion, len(suggestions))
+		}
+		ro.Title = suggestions[opts.Suggestion-1]
+	}
+
+	label := ro.Title
+	if label == "" {
+		label = "report"
+	}
+	fmt.Fprintf(os.Stderr, "Creating %s in notebook %s...\n", label, notebookID)
+	artifact, err := c.CreateReport(notebookID, ro)
+	if err != nil {
+		return err
+	}
+	if opts.waiting() && artifact.GetState() != pb.ArtifactState_ARTIFACT_STATE_READY {
+		s := startSpinner("Waiting for report " + artifact.GetArtifactId())
+		artifact, err = c.WaitForArtifact(artifact.GetArtifactId(), opts.options(s))
+		s.stop()
+		if err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✅ Created report: %s\n", artifact.GetArtifactId())
+	if title := artifact.GetTailoredReport().GetTitle(); title != "" {
+		fmt.Printf("  Title: %s\n", title)
+	}
+	fmt.Printf("  State: %s\n", artifact.GetState())
+
+	if opts.ThenDownload != "" {
+		return writeArtifactExport(c, artifact, &ArtifactExportOptions{
+			Format: formatForPath(opts.ThenDownload),
+			Output: opts.ThenDownload,
+		})
+	}
+	return nil
+}
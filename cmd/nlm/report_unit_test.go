@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseReportCreateFlags(t *testing.T) {
+	opts, pos, err := parseReportCreateFlags([]string{"nb", "--suggestion", "2", "--sources", "s1,s2", "--then-download", "r.html"})
+	if err != nil {
+		t.Fatalf("parseReportCreateFlags() error: %v", err)
+	}
+	if len(pos) != 1 || opts.Suggestion != 2 || !reflect.DeepEqual([]string(opts.Sources), []string{"s1", "s2"}) {
+		t.Errorf("opts = %+v, pos = %q", opts, pos)
+	}
+	if !opts.waiting() || opts.Timeout != 20*time.Minute {
+		t.Errorf("--then-download should imply waiting with the default timeout: %+v", opts.WaitFlags)
+	}
+
+	for _, args := range [][]string{
+		{"nb", "--suggestion", "1", "--prompt", "x"},
+		{"nb", "--suggestion", "-1"},
+		{"nb", "--prompt", "x", "--timeout", "0s"},
+		{"nb"},
+		{"nb", "--prompt", " "},
+	} {
+		if _, _, err := parseReportCreateFlags(args); err == nil {
+			t.Errorf("parseReportCreateFlags(%q) succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+// SourceGetOptions contains the options for source-get.
+type SourceGetOptions struct {
+	Content bool
+	Output  string
+}
+
+// parseSourceGetFlags parses `source-get <source-id> [--content] [-o file]`.
+func parseSourceGetFlags(args []string) (*SourceGetOptions, []string, error) {
+	opts := &SourceGetOptions{}
+	fs := newCommandFlags("source-get")
+	fs.BoolVar(&opts.Content, "content", false, "print the extracted text")
+	fs.StringVar(&opts.Output, "o", "", "write the extracted text to this file")
+	fs.StringVar(&opts.Output, "output", "", "write the extracted text to this file")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateSourceGetArgs(args []string) error {
+	_, pos, err := parseSourceGetFlags(args)
+	if err == nil && len(pos) == 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm source-get <source-id> [--content] [-o file]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// getSource implements source-get. Metadata goes to stdout unless the
+// extracted text is being written there, in which case it moves to stderr.
+func getSource(c *api.Client, args []string) error {
+	opts, pos, err := parseSourceGetFlags(args)
+	if err != nil {
+		return err
+	}
+	sourceID := pos[0]
+
+	fmt.Fprintf(os.Stderr, "Loading source %s...\n", sourceID)
+	sc, err := c.LoadSourceContent(sourceID)
+	if err != nil {
+		return fmt.Errorf("get source: %w", err)
+	}
+
+	toStdout := opts.Content && opts.Output == ""
+	meta := io.Writer(os.Stdout)
+	if toStdout {
+		meta = os.Stderr
+	}
+	if err := printSourceDetails(meta, sc); err != nil {
+		return err
+	}
+
+	switch {
+	case opts.Output != "":
+		if err := os.WriteFile(opts.Output, []byte(sc.Text), 0644); err != nil {
+			return fmt.Errorf("write content: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "✅ Wrote %d bytes of extracted text to %s\n", len(sc.Text), opts.Output)
+	case toStdout:
+		fmt.Print(sc.Text)
+		if sc.Text != "" && !strings.HasSuffix(sc.Text, "\n") {
+			fmt.Println()
+		}
+	}
+	if (opts.Content || opts.Output != "") && sc.Text == "" {
+		fmt.Fprintf(os.Stderr, "nlm: source %s has no extracted text\n", sourceID)
+	}
+	return nil
+}
+
+// printSourceDetails writes a source's metadata as aligned key/value lines.
+func printSourceDetails(out io.Writer, sc *api.SourceContent) error {
+	src := sc.Source
+	md := src.GetMetadata()
+	w := tabwriter.NewWriter(out, 0, 0, 1, ' ', 0)
+	field := func(k, v string) {
+		if v != "" {
+			_, _ = fmt.Fprintf(w, "%s:\t%s\n", k, v)
+		}
+	}
+
+	field("ID", src.GetSourceId().GetSourceId())
+	field("Title", strings.TrimSpace(src.GetTitle()))
+	field("Type", md.GetSourceType().String())
+	field("Status", api.SourceStatus(src).String())
+	var issues []string
+	for _, r := range api.SourceIssueReasons(src) {
+		issues = append(issues, r.String())
+	}
+	field("Issues", strings.Join(issues, ", "))
+	field("Document ID", md.GetGoogleDocs().GetDocumentId())
+	field("YouTube URL", md.GetYoutube().GetYoutubeUrl())
+	if modified, ok := api.SourceModifiedTime(src); ok {
+		field("Last Modified", modified.Format(time.RFC3339))
+	} else {
+		field("Last Modified", unknownValue)
+	}
+	field("Content", fmt.Sprintf("%d characters", utf8.RuneCountInString(sc.Text)))
+	return w.Flush()
+}
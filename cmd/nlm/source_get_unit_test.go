@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParseSourceGetFlags(t *testing.T) {
+	opts, pos, err := parseSourceGetFlags([]string{"src1", "--content", "-o", "out.txt"})
+	if err != nil {
+		t.Fatalf("parseSourceGetFlags() error: %v", err)
+	}
+	if len(pos) != 1 || pos[0] != "src1" {
+		t.Errorf("positionals = %q, want [src1]", pos)
+	}
+	if !opts.Content || opts.Output != "out.txt" {
+		t.Errorf("opts = %+v, want content and output out.txt", opts)
+	}
+}
+
+func TestPrintSourceDetails(t *testing.T) {
+	sc := &api.SourceContent{
+		Source: &pb.Source{
+			SourceId: &pb.SourceId{SourceId: "src1"},
+			Title:    "Talk",
+			Metadata: &pb.SourceMetadata{
+				SourceType: pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO,
+				Status:     pb.SourceSettings_SOURCE_STATUS_ERROR,
+				MetadataType: &pb.SourceMetadata_Youtube{Youtube: &pb.YoutubeSourceMetadata{
+					YoutubeUrl: "https://www.youtube.com/watch?v=abc",
+				}},
+			},
+			Warnings: []*wrapperspb.Int32Value{wrapperspb.Int32(int32(pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE))},
+		},
+		Text: "héllo",
+	}
+	var buf bytes.Buffer
+	if err := printSourceDetails(&buf, sc); err != nil {
+		t.Fatalf("printSourceDetails() error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"ID:",
+		"src1",
+		"SOURCE_TYPE_YOUTUBE_VIDEO",
+		"SOURCE_STATUS_ERROR",
+		"REASON_YOUTUBE_ERROR_PRIVATE",
+		"https://www.youtube.com/watch?v=abc",
+		"Last Modified: unknown",
+		"5 characters",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Document ID") {
+		t.Errorf("output has empty Document ID line:\n%s", out)
+	}
+}
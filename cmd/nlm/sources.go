@@ -0,0 +1,274 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+)
+
+// BulkSourceOptions contains the options shared by filter-driven source
+// commands such as rm-source and sources-toggle.
+type BulkSourceOptions struct {
+	Match     string
+	Types     stringList
+	Statuses  stringList
+	OlderThan string
+	DryRun    bool
+	Yes       bool
+
+	// sources-toggle only
+	Enable  bool
+	Disable bool
+
+	// source-to-note only
+	Delete bool
+}
+
+func (o *BulkSourceOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.Match, "match", "", "select sources whose title matches this glob")
+	fs.Var(&o.Types, "type", "select sources of this type, e.g. YOUTUBE_VIDEO (repeatable)")
+	fs.Var(&o.Statuses, "status", "select sources with this status: ENABLED, DISABLED, ERROR (repeatable)")
+	fs.StringVar(&o.OlderThan, "older-than", "", "select sources last modified before this age, e.g. 30d, 2w, 12h")
+	fs.BoolVar(&o.DryRun, "dry-run", false, "show the selected sources without changing anything")
+	fs.BoolVar(&o.Yes, "y", false, "skip the confirmation prompt")
+	fs.BoolVar(&o.Yes, "yes", false, "skip the confirmation prompt")
+}
+
+// Filter builds the api.SourceFilter described by the options.
+func (o *BulkSourceOptions) Filter() (api.SourceFilter, error) {
+	f := api.SourceFilter{Match: o.Match}
+	for _, t := range o.Types {
+		typ, err := api.ParseSourceType(t)
+		if err != nil {
+			return f, err
+		}
+		f.Types = append(f.Types, typ)
+	}
+	for _, s := range o.Statuses {
+		status, err := api.ParseSourceStatus(s)
+		if err != nil {
+			return f, err
+		}
+		f.Statuses = append(f.Statuses, status)
+	}
+	if o.OlderThan != "" {
+		age, err := api.ParseAge(o.OlderThan)
+		if err != nil {
+			return f, err
+		}
+		f.OlderThan = age
+	}
+	return f, nil
+}
+
+// parseRmSourceFlags parses `rm-source <notebook-id> [source-id...] [filters]`.
+func parseRmSourceFlags(args []string) (*BulkSourceOptions, []string, error) {
+	opts := &BulkSourceOptions{}
+	fs := newCommandFlags("rm-source")
+	opts.register(fs)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, err := opts.Filter(); err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+// parseSourcesToggleFlags parses
+// `sources-toggle <notebook-id> [source-id...] --enable|--disable [filters]`.
+func parseSourcesToggleFlags(args []string) (*BulkSourceOptions, []string, error) {
+	opts := &BulkSourceOptions{}
+	fs := newCommandFlags("sources-toggle")
+	opts.register(fs)
+	fs.BoolVar(&opts.Enable, "enable", false, "enable the selected sources")
+	fs.BoolVar(&opts.Disable, "disable", false, "disable the selected sources")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if opts.Enable == opts.Disable {
+		return nil, nil, fmt.Errorf("exactly one of --enable or --disable is required")
+	}
+	if _, err := opts.Filter(); err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateRmSourceArgs(args []string) error {
+	opts, pos, err := parseRmSourceFlags(args)
+	if err == nil {
+		filter, _ := opts.Filter()
+		switch {
+		case len(pos) == 2 && filter.IsZero():
+			return nil
+		case len(pos) >= 1 && !filter.IsZero():
+			return nil
+		}
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm rm-source <notebook-id> <source-id>\n")
+	fmt.Fprintf(os.Stderr, "       nlm rm-source <notebook-id> [source-id...] [--match glob] [--type T] [--status S] [--older-than 30d] [--dry-run] [-y]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+func validateSourcesToggleArgs(args []string) error {
+	_, pos, err := parseSourcesToggleFlags(args)
+	if err == nil && len(pos) >= 1 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm sources-toggle <notebook-id> [source-id...] --enable|--disable [--match glob] [--type T] [--status S] [--older-than 30d] [--dry-run] [-y]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// selectSources returns the notebook sources named by ids (all sources when
+// ids is empty) that also match filter.
+func selectSources(c *api.Client, notebookID string, ids []string, filter api.SourceFilter) ([]*pb.Source, error) {
+	p, err := c.GetProject(notebookID)
+	if err != nil {
+		return nil, fmt.Errorf("get sources: %w", err)
+	}
+	candidates := p.Sources
+	if len(ids) > 0 {
+		byID := make(map[string]*pb.Source, len(p.Sources))
+		for _, src := range p.Sources {
+			byID[src.GetSourceId().GetSourceId()] = src
+		}
+		candidates = nil
+		for _, id := range ids {
+			src, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("source %s not found in notebook %s", id, notebookID)
+			}
+			candidates = append(candidates, src)
+		}
+	}
+	return filter.Filter(candidates), nil
+}
+
+// confirmBulk previews sources and asks once for confirmation. It returns
+// false without error for dry runs and empty selections.
+func confirmBulk(opts *BulkSourceOptions, sources []*pb.Source, verb string) (bool, error) {
+	if len(sources) == 0 {
+		fmt.Fprintf(os.Stderr, "No sources match.\n")
+		return false, nil
+	}
+	if err := printSourceTable(sources); err != nil {
+		return false, err
+	}
+	if opts.DryRun {
+		fmt.Fprintf(os.Stderr, "Dry run: %d source(s) would be %s.\n", len(sources), verb)
+		return false, nil
+	}
+	if opts.Yes {
+		return true, nil
+	}
+	fmt.Printf("Are you sure you want to %s %d source(s)? [y/N] ", strings.TrimSuffix(verb, "d"), len(sources))
+	var response string
+	_, _ = fmt.Scanln(&response)
+	if !strings.HasPrefix(strings.ToLower(response), "y") {
+		return false, fmt.Errorf("operation cancelled")
+	}
+	return true, nil
+}
+
+func sourceIDs(sources []*pb.Source) []string {
+	ids := make([]string, 0, len(sources))
+	for _, src := range sources {
+		ids = append(ids, src.GetSourceId().GetSourceId())
+	}
+	return ids
+}
+
+// removeSources implements rm-source for both the single-source form and
+// the filter-driven bulk form.
+func removeSources(c *api.Client, args []string) error {
+	opts, pos, err := parseRmSourceFlags(args)
+	if err != nil {
+		return err
+	}
+	filter, err := opts.Filter()
+	if err != nil {
+		return err
+	}
+	notebookID, ids := pos[0], pos[1:]
+	if filter.IsZero() && len(ids) == 1 && !opts.DryRun && !opts.Yes {
+		return removeSource(c, notebookID, ids[0])
+	}
+
+	sources, err := selectSources(c, notebookID, ids, filter)
+	if err != nil {
+		return fmt.Errorf("remove sources: %w", err)
+	}
+	ok, err := confirmBulk(opts, sources, "removed")
+	if !ok || err != nil {
+		return err
+	}
+	if err := c.DeleteSources(notebookID, sourceIDs(sources)); err != nil {
+		return fmt.Errorf("remove sources: %w", err)
+	}
+	fmt.Printf("✅ Removed %d source(s) from notebook %s\n", len(sources), notebookID)
+	return nil
+}
+
+// toggleSources implements sources-toggle.
+func toggleSources(c *api.Client, args []string) error {
+	opts, pos, err := parseSourcesToggleFlags(args)
+	if err != nil {
+		return err
+	}
+	filter, err := opts.Filter()
+	if err != nil {
+		return err
+	}
+	notebookID, ids := pos[0], pos[1:]
+
+	target, verb := pb.SourceSettings_SOURCE_STATUS_ENABLED, "enabled"
+	if opts.Disable {
+		target, verb = pb.SourceSettings_SOURCE_STATUS_DISABLED, "disabled"
+	}
+
+	selected, err := selectSources(c, notebookID, ids, filter)
+	if err != nil {
+		return fmt.Errorf("toggle sources: %w", err)
+	}
+	// Sources already in the target state need no update.
+	var sources []*pb.Source
+	for _, src := range selected {
+		if api.SourceStatus(src) != target {
+			sources = append(sources, src)
+		}
+	}
+	if skipped := len(selected) - len(sources); skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d source(s) already %s.\n", skipped, verb)
+	}
+	ok, err := confirmBulk(opts, sources, verb)
+	if !ok || err != nil {
+		return err
+	}
+
+	var failed int
+	for _, src := range sources {
+		id := src.GetSourceId().GetSourceId()
+		if _, err := c.SetSourceStatus(id, target); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "nlm: %s: %v\n", id, err)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("toggle sources: %d of %d updates failed", failed, len(sources))
+	}
+	fmt.Printf("✅ %s %d source(s)\n", strings.ToUpper(verb[:1])+verb[1:], len(sources))
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+func TestParseInterspersed(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		wantPos   []string
+		wantMatch string
+		wantDry   bool
+	}{
+		{name: "flags after positionals", args: []string{"nb", "--match", "draft*", "--dry-run"}, wantPos: []string{"nb"}, wantMatch: "draft*", wantDry: true},
+		{name: "flags between positionals", args: []string{"nb", "--dry-run", "src1", "src2"}, wantPos: []string{"nb", "src1", "src2"}, wantDry: true},
+		{name: "terminator", args: []string{"nb", "--", "--dry-run"}, wantPos: []string{"nb", "--dry-run"}},
+		{name: "no flags", args: []string{"nb", "src"}, wantPos: []string{"nb", "src"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, pos, err := parseRmSourceFlags(tt.args)
+			if err != nil {
+				t.Fatalf("parseRmSourceFlags(%q) error: %v", tt.args, err)
+			}
+			if !reflect.DeepEqual(pos, tt.wantPos) {
+				t.Errorf("positionals = %q, want %q", pos, tt.wantPos)
+			}
+			if opts.Match != tt.wantMatch || opts.DryRun != tt.wantDry {
+				t.Errorf("opts = %+v, want match %q dry-run %v", opts, tt.wantMatch, tt.wantDry)
+			}
+		})
+	}
+}
+
+func TestBulkSourceOptionsFilter(t *testing.T) {
+	opts, _, err := parseSourcesToggleFlags([]string{"nb", "--disable", "--type", "YOUTUBE_VIDEO,web_page", "--status", "ERROR", "--older-than", "2w"})
+	if err != nil {
+		t.Fatalf("parseSourcesToggleFlags error: %v", err)
+	}
+	filter, err := opts.Filter()
+	if err != nil {
+		t.Fatalf("Filter error: %v", err)
+	}
+	wantTypes := []pb.SourceType{pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO, pb.SourceType_SOURCE_TYPE_WEB_PAGE}
+	if !reflect.DeepEqual(filter.Types, wantTypes) {
+		t.Errorf("Types = %v, want %v", filter.Types, wantTypes)
+	}
+	if len(filter.Statuses) != 1 || filter.Statuses[0] != pb.SourceSettings_SOURCE_STATUS_ERROR {
+		t.Errorf("Statuses = %v, want [ERROR]", filter.Statuses)
+	}
+	if filter.OlderThan != 14*24*time.Hour {
+		t.Errorf("OlderThan = %v, want 336h", filter.OlderThan)
+	}
+}
+
+func TestParseSourcesToggleFlagsRequiresDirection(t *testing.T) {
+	for _, args := range [][]string{{"nb"}, {"nb", "--enable", "--disable"}} {
+		if _, _, err := parseSourcesToggleFlags(args); err == nil {
+			t.Errorf("parseSourcesToggleFlags(%q) succeeded, want error", args)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+	"golang.org/x/term"
+)
+
+// WaitFlags contains the options shared by commands that start long-running
+// generation: audio-create, video-create and create-artifact.
+type WaitFlags struct {
+	Wait         bool
+	Timeout      time.Duration
+	ThenDownload string
+}
+
+func (w *WaitFlags) register(fs *flag.FlagSet) {
+	fs.BoolVar(&w.Wait, "wait", false, "wait until generation finishes")
+	fs.DurationVar(&w.Timeout, "timeout", 20*time.Minute, "give up waiting after this long")
+	fs.StringVar(&w.ThenDownload, "then-download", "", "once ready, download the result to this file (implies --wait)")
+}
+
+// waiting reports whether the command should block until generation ends.
+func (w *WaitFlags) waiting() bool {
+	return w.Wait || w.ThenDownload != ""
+}
+
+// parseWaitFlags parses `<cmd> <notebook-id> <arg> [--wait] [--timeout 20m] [--then-download path]`.
+func parseWaitFlags(cmd string, args []string) (*WaitFlags, []string, error) {
+	w := &WaitFlags{}
+	fs := newCommandFlags(cmd)
+	w.register(fs)
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	if w.Timeout <= 0 {
+		return nil, nil, fmt.Errorf("--timeout must be positive")
+	}
+	return w, pos, nil
+}
+
+func validateWaitArgs(cmd, usage string, args []string) error {
+	_, pos, err := parseWaitFlags(cmd, args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm %s %s [--wait] [--timeout 20m] [--then-download path]\n", cmd, usage)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// options returns the api.WaitOptions for w, reporting progress to s.
+func (w *WaitFlags) options(s *spinner) api.WaitOptions {
+	return api.WaitOptions{Timeout: w.Timeout, Progress: s.update}
+}
+
+// spinner shows progress for a long wait on stderr. On a terminal it
+// animates in place; otherwise it prints one line per poll.
+type spinner struct {
+	out   io.Writer
+	label string
+	tty   bool
+	start time.Time
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	mu    sync.Mutex
+	state string
+}
+
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+func startSpinner(label string) *spinner {
+	s := &spinner{
+		out:   os.Stderr,
+		label: label,
+		tty:   term.IsTerminal(int(os.Stderr.Fd())),
+		start: time.Now(),
+		done:  make(chan struct{}),
+	}
+	if s.tty {
+		s.wg.Add(1)
+		go s.animate()
+	}
+	return s
+}
+
+func (s *spinner) animate() {
+	defer s.wg.Done()
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		select {
+		case <-s.done:
+			fmt.Fprintf(s.out, "\r\033[K")
+			return
+		case <-ticker.C:
+			fmt.Fprintf(s.out, "\r\033[K%s %s", spinnerFrames[i%len(spinnerFrames)], s.line())
+		}
+	}
+}
+
+func (s *spinner) line() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	line := fmt.Sprintf("%s (%s", s.label, time.Since(s.start).Round(time.Second))
+	if s.state != "" {
+		line += ", " + strings.ToLower(strings.TrimPrefix(s.state, "ARTIFACT_STATE_"))
+	}
+	return line + ")"
+}
+
+// update records the outcome of a poll.
+func (s *spinner) update(st api.WaitStatus) {
+	s.mu.Lock()
+	if st.State != "" {
+		s.state = st.State
+	}
+	s.mu.Unlock()
+	if s.tty {
+		return
+	}
+	if st.Err != nil {
+		fmt.Fprintf(s.out, "%s: poll %d failed, retrying: %v\n", s.label, st.Attempt, st.Err)
+		return
+	}
+	fmt.Fprintf(s.out, "%s\n", s.line())
+}
+
+// stop clears the spinner.
+func (s *spinner) stop() {
+	close(s.done)
+	s.wg.Wait()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+)
+
+func TestParseWaitFlags(t *testing.T) {
+	w, pos, err := parseWaitFlags("audio-create", []string{"nb", "--then-download", "out.wav", "make it short", "--timeout", "5m"})
+	if err != nil {
+		t.Fatalf("parseWaitFlags() error: %v", err)
+	}
+	if len(pos) != 2 || pos[0] != "nb" || pos[1] != "make it short" {
+		t.Errorf("positionals = %q", pos)
+	}
+	if !w.waiting() || w.Wait || w.Timeout != 5*time.Minute || w.ThenDownload != "out.wav" {
+		t.Errorf("flags = %+v, want implied wait with 5m timeout", w)
+	}
+
+	w, _, err = parseWaitFlags("audio-create", []string{"nb", "x"})
+	if err != nil {
+		t.Fatalf("parseWaitFlags() error: %v", err)
+	}
+	if w.waiting() || w.Timeout != 20*time.Minute {
+		t.Errorf("defaults = %+v", w)
+	}
+}
+
+func TestSpinnerNonTTY(t *testing.T) {
+	var buf bytes.Buffer
+	s := &spinner{out: &buf, label: "Waiting for artifact", start: time.Now(), done: make(chan struct{})}
+	s.update(api.WaitStatus{Attempt: 1, State: "ARTIFACT_STATE_CREATING"})
+	s.update(api.WaitStatus{Attempt: 2, Err: errors.New("503")})
+	s.stop()
+
+	out := buf.String()
+	if !strings.Contains(out, "Waiting for artifact (0s, creating)") {
+		t.Errorf("missing progress line:\n%s", out)
+	}
+	if !strings.Contains(out, "poll 2 failed, retrying: 503") {
+		t.Errorf("missing retry line:\n%s", out)
+	}
+}
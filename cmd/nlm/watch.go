@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/watch"
+)
+
+// WatchOptions contains the options for watch.
+type WatchOptions struct {
+	StatePath string
+	Debounce  time.Duration
+	Poll      time.Duration
+}
+
+// parseWatchFlags parses `watch <notebook-id> <dir> [--state file] [--debounce 2s] [--poll 5s]`.
+func parseWatchFlags(args []string) (*WatchOptions, []string, error) {
+	opts := &WatchOptions{}
+	fs := newCommandFlags("watch")
+	fs.StringVar(&opts.StatePath, "state", "", "state file (default <dir>/"+watch.DefaultStateFile+")")
+	fs.DurationVar(&opts.Debounce, "debounce", watch.DefaultDebounce, "wait this long for changes to settle before uploading")
+	fs.DurationVar(&opts.Poll, "poll", 0, "poll for changes at this interval instead of using filesystem notifications")
+	pos, err := parseInterspersed(fs, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	return opts, pos, nil
+}
+
+func validateWatchArgs(args []string) error {
+	_, pos, err := parseWatchFlags(args)
+	if err == nil && len(pos) == 2 {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "usage: nlm watch <notebook-id> <dir> [--state file] [--debounce 2s] [--poll 5s]\n")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	return fmt.Errorf("invalid arguments")
+}
+
+// watchDir implements watch: it syncs dir into the notebook once, then
+// keeps applying changes until interrupted.
+func watchDir(c *api.Client, args []string) error {
+	opts, pos, err := parseWatchFlags(args)
+	if err != nil {
+		return err
+	}
+	notebookID, dir := pos[0], pos[1]
+	if info, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("watch: %w", err)
+	} else if !info.IsDir() {
+		return fmt.Errorf("watch: %s is not a directory", dir)
+	}
+
+	s, err := watch.NewSyncer(c, notebookID, dir, opts.StatePath)
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	s.Debounce = opts.Debounce
+	// Sync failures must be visible even without -log-level.
+	s.Logger = logger
+	if s.Logger == nil {
+		s.Logger, _ = newLogger("warn")
+	}
+	s.OnChange = func(ch watch.Change) {
+		fmt.Printf("%s\t%s\t%s\n", ch.Action, ch.Path, ch.SourceID)
+	}
+
+	var n watch.Notifier
+	if opts.Poll > 0 {
+		n, err = watch.NewPoller(dir, opts.Poll)
+	} else {
+		n, err = watch.NewNotifier(dir)
+	}
+	if err != nil {
+		return fmt.Errorf("watch: %w", err)
+	}
+	defer n.Close()
+
+	fmt.Fprintf(os.Stderr, "Syncing %s into notebook %s...\n", dir, notebookID)
+	if err := s.Scan(); err != nil {
+		// Keep watching; failed files are retried on their next change.
+		fmt.Fprintf(os.Stderr, "nlm: %v\n", err)
+	}
+	fmt.Fprintf(os.Stderr, "Watching %s (%d files tracked in %s). Press Ctrl-C to stop.\n", dir, len(s.State().Files), s.StatePath)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return s.Run(ctx, n)
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/youtube"
+)
+
+// youtubeLister expands playlist and channel URLs for nlm add.
+var youtubeLister = &youtube.Lister{Client: &http.Client{Timeout: 30 * time.Second}}
+
+// videoResult is the outcome of adding one video of a playlist or channel.
+type videoResult struct {
+	Video    youtube.Video
+	SourceID string
+	Issue    pb.SourceIssue_Reason // zero if the video was added cleanly
+	Err      error
+}
+
+// addYouTubeCollection adds each video of a playlist or channel as its own
+// source and reports the videos that could not be added, and why.
+func addYouTubeCollection(c *api.Client, notebookID, rawURL string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Listing videos of %s...\n", rawURL)
+	videos, err := youtubeLister.Videos(ctx, rawURL)
+	if err != nil {
+		return fmt.Errorf("list videos: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Found %d video(s).\n", len(videos))
+
+	existing, err := c.SourceURLs(notebookID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: could not check existing sources, duplicates may be added: %v\n", err)
+	}
+	videos = newVideos(videos, existing)
+
+	var results []videoResult
+	for i, v := range videos {
+		if ctx.Err() != nil {
+			break
+		}
+		r := videoResult{Video: v, Issue: v.Issue}
+		if r.Issue == 0 {
+			fmt.Fprintf(os.Stderr, "Adding %d of %d: %s\n", i+1, len(videos), videoLabel(v))
+			r.SourceID, r.Err = c.AddYouTubeSource(notebookID, v.ID)
+			if r.Err != nil {
+				r.Issue = youtube.ReasonFromError(r.Err)
+			}
+		}
+		results = append(results, r)
+	}
+	checkVideoSources(c, notebookID, results)
+	return reportVideoResults(results)
+}
+
+// newVideos drops the videos already in the notebook, whose URLs are
+// given by existing.
+func newVideos(videos []youtube.Video, existing map[string]string) []youtube.Video {
+	have := map[string]bool{}
+	for u := range existing {
+		if id := videoID(u); id != "" {
+			have[id] = true
+		}
+	}
+	var fresh []youtube.Video
+	for _, v := range videos {
+		if have[v.ID] {
+			fmt.Fprintf(os.Stderr, "Skipping %s: already in the notebook\n", videoLabel(v))
+			continue
+		}
+		fresh = append(fresh, v)
+	}
+	return fresh
+}
+
+// videoID returns the ID of a youtube.com/watch or youtu.be video URL.
+func videoID(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	switch {
+	case host == "youtu.be":
+		return strings.Trim(u.Path, "/")
+	case strings.HasSuffix(host, "youtube.com") && u.Path == "/watch":
+		return u.Query().Get("v")
+	}
+	return ""
+}
+
+// checkVideoSources looks up the sources just added and records the
+// YouTube issues NotebookLM attached to them, such as a video that needs
+// a login.
+func checkVideoSources(c *api.Client, notebookID string, results []videoResult) {
+	added := 0
+	for _, r := range results {
+		if r.SourceID != "" {
+			added++
+		}
+	}
+	if added == 0 {
+		return
+	}
+	p, err := c.GetProject(notebookID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nlm: could not check the added videos: %v\n", err)
+		return
+	}
+	issues := map[string]pb.SourceIssue_Reason{}
+	for _, src := range p.GetSources() {
+		for _, reason := range api.SourceIssueReasons(src) {
+			if isYouTubeIssue(reason) {
+				issues[src.GetSourceId().GetSourceId()] = reason
+			}
+		}
+	}
+	for i := range results {
+		if reason, ok := issues[results[i].SourceID]; ok {
+			results[i].Issue = reason
+		}
+	}
+}
+
+func isYouTubeIssue(r pb.SourceIssue_Reason) bool {
+	return r >= pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC && r <= pb.SourceIssue_REASON_YOUTUBE_ERROR_LOGIN_REQUIRED
+}
+
+// reportVideoResults prints the IDs of the added sources to stdout and the
+// failures to stderr. It fails only if no video could be added.
+func reportVideoResults(results []videoResult) error {
+	added, failed := 0, 0
+	for _, r := range results {
+		if r.SourceID != "" && r.Issue == 0 {
+			added++
+			fmt.Println(r.SourceID)
+			continue
+		}
+		failed++
+		msg := youtube.ReasonText(r.Issue)
+		if r.SourceID != "" {
+			msg = "added as " + r.SourceID + " but " + msg
+		}
+		if r.Err != nil {
+			msg += fmt.Sprintf(" (%v)", r.Err)
+		}
+		fmt.Fprintf(os.Stderr, "❌ %s: %s\n", videoLabel(r.Video), msg)
+	}
+	if added == 0 && failed > 0 {
+		return fmt.Errorf("none of %d video(s) could be added", failed)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Added %d video(s)", added)
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, ", %d failed", failed)
+	}
+	fmt.Fprintln(os.Stderr)
+	return nil
+}
+
+func videoLabel(v youtube.Video) string {
+	if v.Title == "" {
+		return v.ID
+	}
+	return fmt.Sprintf("%s (%s)", v.ID, v.Title)
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/youtube"
+)
+
+func TestVideoID(t *testing.T) {
+	tests := map[string]string{
+		"https://www.youtube.com/watch?v=aaaaaaaaaaa":         "aaaaaaaaaaa",
+		"https://youtu.be/bbbbbbbbbbb":                        "bbbbbbbbbbb",
+		"https://m.youtube.com/watch?v=ccccccccccc&t=1":       "ccccccccccc",
+		"https://www.youtube.com/playlist?list=PL123":         "",
+		"https://example.com/watch?v=aaaaaaaaaaa&notyoutube=": "",
+	}
+	for u, want := range tests {
+		if got := videoID(u); got != want {
+			t.Errorf("videoID(%q) = %q, want %q", u, got, want)
+		}
+	}
+}
+
+func TestNewVideos(t *testing.T) {
+	videos := []youtube.Video{{ID: "aaaaaaaaaaa"}, {ID: "bbbbbbbbbbb"}, {ID: "ccccccccccc"}}
+	existing := map[string]string{
+		"https://youtu.be/aaaaaaaaaaa": "src-1",
+		"https://go.dev/":              "src-2",
+	}
+	got := newVideos(videos, existing)
+	if len(got) != 2 || got[0].ID != "bbbbbbbbbbb" || got[1].ID != "ccccccccccc" {
+		t.Errorf("newVideos = %+v", got)
+	}
+}
+
+func TestReportVideoResults(t *testing.T) {
+	ok := videoResult{Video: youtube.Video{ID: "aaaaaaaaaaa"}, SourceID: "src-1"}
+	private := videoResult{Video: youtube.Video{ID: "bbbbbbbbbbb"}, Issue: pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE}
+	failed := videoResult{Video: youtube.Video{ID: "ccccccccccc"}, Issue: pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC, Err: errors.New("boom")}
+	if err := reportVideoResults([]videoResult{ok, private, failed}); err != nil {
+		t.Errorf("reportVideoResults with one success: %v", err)
+	}
+	if err := reportVideoResults([]videoResult{private, failed}); err == nil {
+		t.Error("reportVideoResults with no success succeeded")
+	}
+}
+
+func TestIsYouTubeIssue(t *testing.T) {
+	for r, want := range map[pb.SourceIssue_Reason]bool{
+		pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC:        true,
+		pb.SourceIssue_REASON_YOUTUBE_ERROR_LOGIN_REQUIRED: true,
+		pb.SourceIssue_REASON_UNSPECIFIED:                  false,
+	} {
+		if got := isYouTubeIssue(r); got != want {
+			t.Errorf("isYouTubeIssue(%v) = %v, want %v", r, got, want)
+		}
+	}
+}
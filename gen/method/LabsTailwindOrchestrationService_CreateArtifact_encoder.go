@@ -2,7 +2,6 @@ package method
 
 import (
 	notebooklmv1alpha1 "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
-	"github.com/tmc/nlm/internal/rpc/argbuilder"
 )
 
 // GENERATION_BEHAVIOR: append
@@ -11,12 +10,15 @@ import (
 // RPC ID: xpWGLf
 // Argument format: [%context%, %project_id%, %artifact%]
 func EncodeCreateArtifactArgs(req *notebooklmv1alpha1.CreateArtifactRequest) []interface{} {
-	// Using generalized argument encoder
-	args, err := argbuilder.EncodeRPCArgs(req, "[%context%, %project_id%, %artifact%]")
-	if err != nil {
-		// Log error and return empty args as fallback
-		// In production, this should be handled better
-		return []interface{}{}
+	// Encode by hand: the generic encoder turns the context and artifact
+	// into maps, which the server ignores, so the selected sources and the
+	// report title and prompt would never arrive.
+	var context, artifact interface{}
+	if req.GetContext() != nil {
+		context = encodeMessage(req.GetContext())
 	}
-	return args
+	if req.GetArtifact() != nil {
+		artifact = encodeMessage(req.GetArtifact())
+	}
+	return []interface{}{context, req.GetProjectId(), artifact}
 }
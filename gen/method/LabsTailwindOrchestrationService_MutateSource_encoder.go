@@ -2,7 +2,6 @@ package method
 
 import (
 	notebooklmv1alpha1 "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
-	"github.com/tmc/nlm/internal/rpc/argbuilder"
 )
 
 // GENERATION_BEHAVIOR: append
@@ -11,12 +10,11 @@ import (
 // RPC ID: b7Wfje
 // Argument format: [%source_id%, %updates%]
 func EncodeMutateSourceArgs(req *notebooklmv1alpha1.MutateSourceRequest) []interface{} {
-	// Using generalized argument encoder
-	args, err := argbuilder.EncodeRPCArgs(req, "[%source_id%, %updates%]")
-	if err != nil {
-		// Log error and return empty args as fallback
-		// In production, this should be handled better
-		return []interface{}{}
+	// Encode by hand: the generic encoder turns the Source into a map, but
+	// it must be sent as a positional array.
+	var updates interface{}
+	if req.GetUpdates() != nil {
+		updates = encodeMessage(req.GetUpdates())
 	}
-	return args
+	return []interface{}{req.GetSourceId(), updates}
 }
@@ -2,7 +2,6 @@ package method
 
 import (
 	notebooklmv1alpha1 "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
-	"github.com/tmc/nlm/internal/rpc/argbuilder"
 )
 
 // GENERATION_BEHAVIOR: append
@@ -11,12 +10,17 @@ import (
 // RPC ID: DJezBc
 // Argument format: [%artifact%, %update_mask%]
 func EncodeUpdateArtifactArgs(req *notebooklmv1alpha1.UpdateArtifactRequest) []interface{} {
-	// Using generalized argument encoder
-	args, err := argbuilder.EncodeRPCArgs(req, "[%artifact%, %update_mask%]")
-	if err != nil {
-		// Log error and return empty args as fallback
-		// In production, this should be handled better
-		return []interface{}{}
+	// Encode by hand: the generic encoder turns nested messages into maps,
+	// but the artifact and mask must be sent as positional arrays. Unlike
+	// the bare path list encodeFieldMask produces for other RPCs, the mask
+	// here is a FieldMask message like the artifact beside it, so its
+	// paths are nested one level deeper: [["title"]].
+	var artifact, mask interface{}
+	if req.GetArtifact() != nil {
+		artifact = encodeMessage(req.GetArtifact())
 	}
-	return args
+	if req.GetUpdateMask() != nil {
+		mask = encodeMessage(req.GetUpdateMask())
+	}
+	return []interface{}{artifact, mask}
 }
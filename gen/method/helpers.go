@@ -2,7 +2,11 @@
 package method
 
 import (
+	"encoding/json"
+
 	notebooklmv1alpha1 "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/beprotojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
@@ -178,3 +182,16 @@ func encodeFieldMask(mask *fieldmaskpb.FieldMask) interface{} {
 	}
 	return mask.GetPaths()
 }
+
+// encodeMessage encodes m as a positional array indexed by field number.
+func encodeMessage(m proto.Message) interface{} {
+	b, err := beprotojson.Marshal(m)
+	if err != nil {
+		return nil
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil
+	}
+	return v
+}
@@ -1,6 +1,7 @@
 package method
 
 import (
+	"encoding/json"
 	"testing"
 
 	notebooklmv1alpha1 "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
@@ -114,6 +115,151 @@ func TestEncodeProjectUpdates(t *testing.T) {
 	}
 }
 
+func TestEncodeMutateSourceArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *notebooklmv1alpha1.MutateSourceRequest
+		want string
+	}{
+		{
+			name: "rename",
+			req: &notebooklmv1alpha1.MutateSourceRequest{
+				SourceId: "src1",
+				Updates:  &notebooklmv1alpha1.Source{Title: "Renamed"},
+			},
+			want: `["src1",[[],"Renamed",[],[],[]]]`,
+		},
+		{
+			name: "disable",
+			req: &notebooklmv1alpha1.MutateSourceRequest{
+				SourceId: "src1",
+				Updates: &notebooklmv1alpha1.Source{
+					Settings: &notebooklmv1alpha1.SourceSettings{
+						Status: notebooklmv1alpha1.SourceSettings_SOURCE_STATUS_DISABLED,
+					},
+				},
+			},
+			want: `["src1",[[],null,[],[null,2],[]]]`,
+		},
+		{
+			name: "no updates",
+			req:  &notebooklmv1alpha1.MutateSourceRequest{SourceId: "src1"},
+			want: `["src1",null]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(EncodeMutateSourceArgs(tt.req))
+			if err != nil {
+				t.Fatalf("marshal args: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeMutateSourceArgs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeUpdateArtifactArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *notebooklmv1alpha1.UpdateArtifactRequest
+		want string
+	}{
+		{
+			name: "report content",
+			req: &notebooklmv1alpha1.UpdateArtifactRequest{
+				Artifact: &notebooklmv1alpha1.Artifact{
+					ArtifactId:     "art1",
+					TailoredReport: &notebooklmv1alpha1.Report{Title: "Q3", Content: "Body"},
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"tailored_report.title", "tailored_report.content"}},
+			},
+			want: `[["art1",null,null,[],null,null,[],[],["Q3","Body",[]],[]],[["tailored_report.title","tailored_report.content"]]]`,
+		},
+		{
+			name: "sources",
+			req: &notebooklmv1alpha1.UpdateArtifactRequest{
+				Artifact: &notebooklmv1alpha1.Artifact{
+					ArtifactId: "art1",
+					Sources: []*notebooklmv1alpha1.ArtifactSource{
+						{SourceId: &notebooklmv1alpha1.SourceId{SourceId: "s1"}},
+					},
+				},
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"sources"}},
+			},
+			want: `[["art1",null,null,[[["s1"],[]]],null,null,[],[],[],[]],[["sources"]]]`,
+		},
+		{
+			name: "empty request",
+			req:  &notebooklmv1alpha1.UpdateArtifactRequest{},
+			want: `[null,null]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(EncodeUpdateArtifactArgs(tt.req))
+			if err != nil {
+				t.Fatalf("marshal args: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeUpdateArtifactArgs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEncodeCreateArtifactArgs(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *notebooklmv1alpha1.CreateArtifactRequest
+		want string
+	}{
+		{
+			name: "report",
+			req: &notebooklmv1alpha1.CreateArtifactRequest{
+				Context: &notebooklmv1alpha1.Context{
+					ProjectId: "p",
+					SourceIds: []string{"s1", "s2"},
+				},
+				ProjectId: "p",
+				Artifact: &notebooklmv1alpha1.Artifact{
+					ProjectId: "p",
+					Type:      notebooklmv1alpha1.ArtifactType_ARTIFACT_TYPE_REPORT,
+					State:     notebooklmv1alpha1.ArtifactState_ARTIFACT_STATE_CREATING,
+					TailoredReport: &notebooklmv1alpha1.Report{
+						Title:   "Pricing",
+						Content: "Compare the pricing models",
+					},
+				},
+			},
+			want: `[["p",["s1","s2"]],"p",[null,"p",3,[],1,null,[],[],["Pricing","Compare the pricing models",[]],[]]]`,
+		},
+		{
+			name: "no context",
+			req: &notebooklmv1alpha1.CreateArtifactRequest{
+				ProjectId: "p",
+				Artifact: &notebooklmv1alpha1.Artifact{
+					ProjectId: "p",
+					Type:      notebooklmv1alpha1.ArtifactType_ARTIFACT_TYPE_NOTE,
+				},
+			},
+			want: `[null,"p",[null,"p",1,[],null,null,[],[],[],[]]]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(EncodeCreateArtifactArgs(tt.req))
+			if err != nil {
+				t.Fatalf("marshal args: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("EncodeCreateArtifactArgs() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestEncodeShareSettings(t *testing.T) {
 	tests := []struct {
 		name     string
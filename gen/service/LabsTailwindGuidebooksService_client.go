@@ -37,7 +37,7 @@ func (c *LabsTailwindGuidebooksServiceClient) DeleteGuidebook(ctx context.Contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DeleteGuidebook: %w", err)
 	}
@@ -60,7 +60,7 @@ func (c *LabsTailwindGuidebooksServiceClient) GetGuidebook(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetGuidebook: %w", err)
 	}
@@ -83,7 +83,7 @@ func (c *LabsTailwindGuidebooksServiceClient) ListRecentlyViewedGuidebooks(ctx c
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ListRecentlyViewedGuidebooks: %w", err)
 	}
@@ -106,7 +106,7 @@ func (c *LabsTailwindGuidebooksServiceClient) PublishGuidebook(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("PublishGuidebook: %w", err)
 	}
@@ -129,7 +129,7 @@ func (c *LabsTailwindGuidebooksServiceClient) GetGuidebookDetails(ctx context.Co
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetGuidebookDetails: %w", err)
 	}
@@ -152,7 +152,7 @@ func (c *LabsTailwindGuidebooksServiceClient) ShareGuidebook(ctx context.Context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ShareGuidebook: %w", err)
 	}
@@ -175,7 +175,7 @@ func (c *LabsTailwindGuidebooksServiceClient) GuidebookGenerateAnswer(ctx contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GuidebookGenerateAnswer: %w", err)
 	}
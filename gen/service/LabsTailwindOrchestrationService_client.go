@@ -40,7 +40,7 @@ func (c *LabsTailwindOrchestrationServiceClient) CreateArtifact(ctx context.Cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("CreateArtifact: %w", err)
 	}
@@ -63,7 +63,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GetArtifact(ctx context.Context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetArtifact: %w", err)
 	}
@@ -86,7 +86,7 @@ func (c *LabsTailwindOrchestrationServiceClient) UpdateArtifact(ctx context.Cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("UpdateArtifact: %w", err)
 	}
@@ -109,7 +109,7 @@ func (c *LabsTailwindOrchestrationServiceClient) RenameArtifact(ctx context.Cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("RenameArtifact: %w", err)
 	}
@@ -132,7 +132,7 @@ func (c *LabsTailwindOrchestrationServiceClient) DeleteArtifact(ctx context.Cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DeleteArtifact: %w", err)
 	}
@@ -155,7 +155,7 @@ func (c *LabsTailwindOrchestrationServiceClient) ListArtifacts(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ListArtifacts: %w", err)
 	}
@@ -179,7 +179,7 @@ func (c *LabsTailwindOrchestrationServiceClient) ActOnSources(ctx context.Contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ActOnSources: %w", err)
 	}
@@ -202,7 +202,7 @@ func (c *LabsTailwindOrchestrationServiceClient) AddSources(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("AddSources: %w", err)
 	}
@@ -225,7 +225,7 @@ func (c *LabsTailwindOrchestrationServiceClient) CheckSourceFreshness(ctx contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("CheckSourceFreshness: %w", err)
 	}
@@ -248,7 +248,7 @@ func (c *LabsTailwindOrchestrationServiceClient) DeleteSources(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DeleteSources: %w", err)
 	}
@@ -271,7 +271,7 @@ func (c *LabsTailwindOrchestrationServiceClient) DiscoverSources(ctx context.Con
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DiscoverSources: %w", err)
 	}
@@ -294,7 +294,7 @@ func (c *LabsTailwindOrchestrationServiceClient) LoadSource(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("LoadSource: %w", err)
 	}
@@ -317,7 +317,7 @@ func (c *LabsTailwindOrchestrationServiceClient) MutateSource(ctx context.Contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("MutateSource: %w", err)
 	}
@@ -340,7 +340,7 @@ func (c *LabsTailwindOrchestrationServiceClient) RefreshSource(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("RefreshSource: %w", err)
 	}
@@ -363,7 +363,7 @@ func (c *LabsTailwindOrchestrationServiceClient) CreateAudioOverview(ctx context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("CreateAudioOverview: %w", err)
 	}
@@ -386,7 +386,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GetAudioOverview(ctx context.Co
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetAudioOverview: %w", err)
 	}
@@ -409,7 +409,7 @@ func (c *LabsTailwindOrchestrationServiceClient) DeleteAudioOverview(ctx context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DeleteAudioOverview: %w", err)
 	}
@@ -432,7 +432,7 @@ func (c *LabsTailwindOrchestrationServiceClient) CreateNote(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("CreateNote: %w", err)
 	}
@@ -455,7 +455,7 @@ func (c *LabsTailwindOrchestrationServiceClient) DeleteNotes(ctx context.Context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DeleteNotes: %w", err)
 	}
@@ -478,7 +478,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GetNotes(ctx context.Context, r
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetNotes: %w", err)
 	}
@@ -599,7 +599,7 @@ func (c *LabsTailwindOrchestrationServiceClient) MutateNote(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("MutateNote: %w", err)
 	}
@@ -622,7 +622,7 @@ func (c *LabsTailwindOrchestrationServiceClient) CreateProject(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("CreateProject: %w", err)
 	}
@@ -645,7 +645,7 @@ func (c *LabsTailwindOrchestrationServiceClient) DeleteProjects(ctx context.Cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("DeleteProjects: %w", err)
 	}
@@ -668,7 +668,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GetProject(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetProject: %w", err)
 	}
@@ -691,7 +691,7 @@ func (c *LabsTailwindOrchestrationServiceClient) ListFeaturedProjects(ctx contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ListFeaturedProjects: %w", err)
 	}
@@ -714,7 +714,7 @@ func (c *LabsTailwindOrchestrationServiceClient) ListRecentlyViewedProjects(ctx
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ListRecentlyViewedProjects: %w", err)
 	}
@@ -742,7 +742,7 @@ func (c *LabsTailwindOrchestrationServiceClient) MutateProject(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("MutateProject: %w", err)
 	}
@@ -765,7 +765,7 @@ func (c *LabsTailwindOrchestrationServiceClient) RemoveRecentlyViewedProject(ctx
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("RemoveRecentlyViewedProject: %w", err)
 	}
@@ -788,7 +788,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateDocumentGuides(ctx cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateDocumentGuides: %w", err)
 	}
@@ -817,8 +817,14 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateFreeFormStreamed(ctx co
 		Body:     requestBody,
 	}
 
-	// Execute the gRPC request
-	resp, err := grpcClient.Execute(grpcReq)
+	// Execute the gRPC request through the RPC client's interceptors
+	call := rpc.Call{
+		ID:   rpc.RPCGenerateFreeFormStreamed,
+		Args: []interface{}{requestBody},
+	}
+	resp, err := c.rpcClient.Invoke(ctx, call, func(ctx context.Context, call rpc.Call) (json.RawMessage, error) {
+		return grpcClient.Execute(grpcReq)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("GenerateFreeFormStreamed: %w", err)
 	}
@@ -860,7 +866,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateNotebookGuide(ctx conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateNotebookGuide: %w", err)
 	}
@@ -883,7 +889,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateOutline(ctx context.Con
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateOutline: %w", err)
 	}
@@ -906,7 +912,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateReportSuggestions(ctx c
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateReportSuggestions: %w", err)
 	}
@@ -929,7 +935,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateSection(ctx context.Con
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateSection: %w", err)
 	}
@@ -952,7 +958,7 @@ func (c *LabsTailwindOrchestrationServiceClient) StartDraft(ctx context.Context,
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("StartDraft: %w", err)
 	}
@@ -975,7 +981,7 @@ func (c *LabsTailwindOrchestrationServiceClient) StartSection(ctx context.Contex
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("StartSection: %w", err)
 	}
@@ -998,7 +1004,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GenerateMagicView(ctx context.C
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GenerateMagicView: %w", err)
 	}
@@ -1021,7 +1027,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GetProjectAnalytics(ctx context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetProjectAnalytics: %w", err)
 	}
@@ -1044,7 +1050,7 @@ func (c *LabsTailwindOrchestrationServiceClient) SubmitFeedback(ctx context.Cont
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("SubmitFeedback: %w", err)
 	}
@@ -1067,7 +1073,7 @@ func (c *LabsTailwindOrchestrationServiceClient) GetOrCreateAccount(ctx context.
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetOrCreateAccount: %w", err)
 	}
@@ -1090,7 +1096,7 @@ func (c *LabsTailwindOrchestrationServiceClient) MutateAccount(ctx context.Conte
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("MutateAccount: %w", err)
 	}
@@ -36,7 +36,7 @@ func (c *LabsTailwindSharingServiceClient) ShareAudio(ctx context.Context, req *
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ShareAudio: %w", err)
 	}
@@ -59,7 +59,7 @@ func (c *LabsTailwindSharingServiceClient) GetProjectDetails(ctx context.Context
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("GetProjectDetails: %w", err)
 	}
@@ -82,7 +82,7 @@ func (c *LabsTailwindSharingServiceClient) ShareProject(ctx context.Context, req
 	}
 
 	// Execute the RPC
-	resp, err := c.rpcClient.Do(call)
+	resp, err := c.rpcClient.DoContext(ctx, call)
 	if err != nil {
 		return nil, fmt.Errorf("ShareProject: %w", err)
 	}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/tmc/nlm/internal/rpc"
+	"github.com/tmc/nlm/internal/rpc/grpcendpoint"
+)
+
+// ChatResponse is a chat answer together with the grounding the server
+// returned for it.
+type ChatResponse struct {
+	Text      string         `json:"text"`
+	Citations []ChatCitation `json:"citations,omitempty"`
+}
+
+// ChatCitation ties parts of an answer to a passage of one source. Number
+// is the citation's 1-based position in the response, suitable for use as
+// a footnote number.
+type ChatCitation struct {
+	Number    int           `json:"number"`
+	PassageID string        `json:"passage_id,omitempty"`
+	SourceID  string        `json:"source_id"`
+	Spans     []ChatSpan    `json:"spans,omitempty"`
+	Passages  []ChatPassage `json:"passages,omitempty"`
+}
+
+// ChatSpan is a range of the answer text, in runes, that a citation
+// supports.
+type ChatSpan struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// ChatPassage is a quoted passage of a source. Start and End are the
+// passage's character offsets in the source text as reported by the server.
+type ChatPassage struct {
+	Text  string `json:"text"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// chat sends prompt to the GenerateFreeFormStreamed endpoint and decodes
+// the answer with its citations.
+func (c *Client) chat(ctx context.Context, sourceIDs []string, prompt string) (*ChatResponse, error) {
+	body := grpcendpoint.BuildChatRequest(sourceIDs, prompt)
+	grpcClient := grpcendpoint.NewClient(c.rpc.Config.AuthToken, c.rpc.Config.Cookies)
+	call := rpc.Call{
+		ID:   rpc.RPCGenerateFreeFormStreamed,
+		Args: []interface{}{body},
+	}
+	resp, err := c.rpc.Invoke(ctx, call, func(ctx context.Context, call rpc.Call) (json.RawMessage, error) {
+		return grpcClient.Execute(grpcendpoint.Request{
+			Endpoint: "/google.internal.labs.tailwind.orchestration.v1.LabsTailwindOrchestrationService/GenerateFreeFormStreamed",
+			Body:     body,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decodeChatResponse(resp)
+}
+
+// streamChunks splits an answer into words, each with the whitespace that
+// precedes it.
+var streamChunks = regexp.MustCompile(`\s*\S+|\s+$`)
+
+// decodeChatResponse decodes a GenerateFreeFormStreamed response. The
+// answer arrives as
+//
+//	[[text, null, [conversationID, ...], null, grounding, ...]]
+//
+// where grounding, when present, is [spans, null, null, citations]:
+//
+//	spans:     [[null, start, end, [[citationIndex], ...]], ...]
+//	citations: [[[passageID], [null, null, score, null, passages, [[[sourceID]]]]], ...]
+//	passages:  [[start, end, [[...text...]]], ...]
+//
+// Grounding is decoded on a best effort basis: parts that do not have the
+// expected shape are skipped rather than failing the answer.
+func decodeChatResponse(data []byte) (*ChatResponse, error) {
+	var outer []interface{}
+	if err := json.Unmarshal(data, &outer); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	text, ok := jsonAt(outer, 0, 0).(string)
+	if !ok {
+		return nil, fmt.Errorf("parse response: answer text not found")
+	}
+	resp := &ChatResponse{Text: text}
+
+	grounding := jsonAt(outer, 0, 4)
+	for _, entry := range jsonList(jsonAt(grounding, 3)) {
+		cite := ChatCitation{Number: len(resp.Citations) + 1}
+		cite.PassageID, _ = jsonAt(entry, 0, 0).(string)
+		var ids []string
+		collectText(jsonAt(entry, 1, 5), &ids)
+		if len(ids) > 0 {
+			cite.SourceID = ids[0]
+		}
+		for _, p := range jsonList(jsonAt(entry, 1, 4)) {
+			var texts []string
+			collectText(jsonAt(p, 2), &texts)
+			cite.Passages = append(cite.Passages, ChatPassage{
+				Text:  strings.Join(texts, ""),
+				Start: jsonInt(jsonAt(p, 0)),
+				End:   jsonInt(jsonAt(p, 1)),
+			})
+		}
+		resp.Citations = append(resp.Citations, cite)
+	}
+
+	n := len([]rune(text))
+	for _, s := range jsonList(jsonAt(grounding, 0)) {
+		span := ChatSpan{Start: jsonInt(jsonAt(s, 1)), End: jsonInt(jsonAt(s, 2))}
+		if span.Start < 0 || span.End <= span.Start || span.End > n {
+			continue
+		}
+		for _, ref := range jsonList(jsonAt(s, 3)) {
+			i := jsonInt(jsonAt(ref, 0))
+			if i >= 0 && i < len(resp.Citations) {
+				resp.Citations[i].Spans = append(resp.Citations[i].Spans, span)
+			}
+		}
+	}
+	return resp, nil
+}
+
+// jsonAt returns the element of a decoded JSON array found by following
+// path, or nil if any step is missing.
+func jsonAt(v interface{}, path ...int) interface{} {
+	for _, i := range path {
+		arr, ok := v.([]interface{})
+		if !ok || i < 0 || i >= len(arr) {
+			return nil
+		}
+		v = arr[i]
+	}
+	return v
+}
+
+// jsonList returns v as an array, or nil if it is not one.
+func jsonList(v interface{}) []interface{} {
+	arr, _ := v.([]interface{})
+	return arr
+}
+
+// jsonInt returns v as an int, or -1 if it is not a number.
+func jsonInt(v interface{}) int {
+	f, ok := v.(float64)
+	if !ok {
+		return -1
+	}
+	return int(f)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecodeChatResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    *ChatResponse
+		wantErr bool
+	}{
+		{
+			name: "text only",
+			data: `[["The answer.", null, ["conv-1", "msg-1"]]]`,
+			want: &ChatResponse{Text: "The answer."},
+		},
+		{
+			name: "with grounding",
+			data: `[["Go is fast. It is simple.", null, ["conv-1"], null, [
+				[[null, 0, 11, [[0]]], [null, 12, 25, [[1], [0]]]],
+				null, null,
+				[
+					[["p-1"], [null, null, 0.9, null, [[100, 140, [[[100, 140, "Go compiles quickly."]]]]], [[["src-a"]]]]],
+					[["p-2"], [null, null, 0.7, null, [[5, 20, [["Simplicity ", "matters."]]]], [[["src-b"]]]]]
+				]
+			]]]`,
+			want: &ChatResponse{
+				Text: "Go is fast. It is simple.",
+				Citations: []ChatCitation{
+					{
+						Number: 1, PassageID: "p-1", SourceID: "src-a",
+						Spans:    []ChatSpan{{0, 11}, {12, 25}},
+						Passages: []ChatPassage{{Text: "Go compiles quickly.", Start: 100, End: 140}},
+					},
+					{
+						Number: 2, PassageID: "p-2", SourceID: "src-b",
+						Spans:    []ChatSpan{{12, 25}},
+						Passages: []ChatPassage{{Text: "Simplicity matters.", Start: 5, End: 20}},
+					},
+				},
+			},
+		},
+		{
+			name: "malformed grounding is skipped",
+			data: `[["Short.", null, null, null, [[[null, 0, 99, [[0]]], "junk", [null, 3, 1, [[0]]]], null, null, [["not a citation"]]]]]`,
+			want: &ChatResponse{
+				Text:      "Short.",
+				Citations: []ChatCitation{{Number: 1}},
+			},
+		},
+		{
+			name:    "missing text",
+			data:    `[[null]]`,
+			wantErr: true,
+		},
+		{
+			name:    "not JSON",
+			data:    `oops`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeChatResponse([]byte(tt.data))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeChatResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("decodeChatResponse() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStreamChunksPreserveText(t *testing.T) {
+	for _, text := range []string{
+		"one two  three",
+		"# Heading\n\n- item one\n- item two\n",
+		"  leading and trailing  ",
+	} {
+		if got := strings.Join(streamChunks.FindAllString(text, -1), ""); got != text {
+			t.Errorf("chunks of %q rejoin to %q", text, got)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"regexp"
 	"strings"
 
 	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/batchexecute"
 	"github.com/tmc/nlm/internal/beprotojson"
 )
 
@@ -17,6 +19,7 @@ const defaultProjectEmoji = "📄"
 type ChunkedResponseParser struct {
 	Raw         string
 	Debug       bool
+	Logger      *slog.Logger // Receives debug output; defaults to stderr when Debug is set
 	rawChunks   []string
 	cleanedData string
 }
@@ -35,11 +38,22 @@ func (p *ChunkedResponseParser) WithDebug(debug bool) *ChunkedResponseParser {
 	return p
 }
 
-// logDebug logs a message if debug mode is enabled
+// WithLogger sets the logger that receives debug output for this parser
+func (p *ChunkedResponseParser) WithLogger(logger *slog.Logger) *ChunkedResponseParser {
+	p.Logger = logger
+	return p
+}
+
+// logDebug logs a message if debug mode is enabled or a logger is set
 func (p *ChunkedResponseParser) logDebug(format string, args ...interface{}) {
-	if p.Debug {
-		fmt.Printf("[ChunkedParser] "+format+"\n", args...)
+	logger := p.Logger
+	if logger == nil {
+		if !p.Debug {
+			return
+		}
+		logger = batchexecute.DefaultLogger(true)
 	}
+	logger.Debug(fmt.Sprintf(format, args...), "component", "chunked_parser")
 }
 
 // ParseListProjectsResponse extracts projects from the raw response with fallback mechanisms
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"mime"
 	"net/http"
 	"net/url"
@@ -20,7 +21,9 @@ import (
 	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
 	"github.com/tmc/nlm/gen/service"
 	"github.com/tmc/nlm/internal/batchexecute"
+	"github.com/tmc/nlm/internal/drive"
 	"github.com/tmc/nlm/internal/rpc"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 type (
@@ -40,6 +43,7 @@ type Client struct {
 	orchestrationService *service.LabsTailwindOrchestrationServiceClient
 	sharingService       *service.LabsTailwindSharingServiceClient
 	guidebooksService    *service.LabsTailwindGuidebooksServiceClient
+	logger               *slog.Logger
 	config               struct {
 		Debug        bool
 		UseDirectRPC bool // Use direct RPC calls instead of orchestration service
@@ -68,10 +72,20 @@ func New(authToken, cookies string, opts ...batchexecute.Option) *Client {
 
 	// Get debug setting from environment for consistency
 	client.config.Debug = os.Getenv("NLM_DEBUG") == envTrue
+	client.logger = client.rpc.Logger()
 
 	return client
 }
 
+// log returns the client's logger, discarding output for clients that were
+// not built with New.
+func (c *Client) log() *slog.Logger {
+	if c.logger == nil {
+		return batchexecute.DefaultLogger(false)
+	}
+	return c.logger
+}
+
 // SetUseDirectRPC configures whether to use direct RPC calls
 func (c *Client) SetUseDirectRPC(use bool) {
 	c.config.UseDirectRPC = use
@@ -114,8 +128,8 @@ func (c *Client) GetProject(projectID string) (*Notebook, error) {
 		return nil, fmt.Errorf("get project: %w", err)
 	}
 
-	if c.config.Debug && project.Sources != nil {
-		fmt.Printf("DEBUG: Successfully parsed project with %d sources\n", len(project.Sources))
+	if project.Sources != nil {
+		c.log().Debug("parsed project", "project", projectID, "sources", len(project.Sources))
 	}
 	return project, nil
 }
@@ -197,6 +211,17 @@ func (c *Client) MutateSource(sourceID string, updates *pb.Source) (*pb.Source,
 	return source, nil
 }
 
+// SetSourceStatus enables or disables a source by updating its settings.
+func (c *Client) SetSourceStatus(sourceID string, status pb.SourceSettings_SourceStatus) (*pb.Source, error) {
+	source, err := c.MutateSource(sourceID, &pb.Source{
+		Settings: &pb.SourceSettings{Status: status},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set source status: %w", err)
+	}
+	return source, nil
+}
+
 func (c *Client) RefreshSource(sourceID string) (*pb.Source, error) {
 	req := &pb.RefreshSourceRequest{
 		SourceId: sourceID,
@@ -413,7 +438,12 @@ func (c *Client) AddSourceFromFile(projectID, filepath string, contentType ...st
 }
 
 func (c *Client) AddSourceFromURL(projectID, url string) (string, error) {
-	// Check if it's a YouTube URL first
+	// Google Docs, Slides and Sheets are added as Drive sources.
+	if f, ok := drive.ParseURL(url); ok {
+		return c.AddDriveSource(projectID, f, "")
+	}
+
+	// Check if it's a YouTube URL
 	if isYouTubeURL(url) {
 		videoID, err := extractYouTubeVideoID(url)
 		if err != nil {
@@ -450,11 +480,7 @@ func (c *Client) AddSourceFromURL(projectID, url string) (string, error) {
 }
 
 func (c *Client) AddYouTubeSource(projectID, videoID string) (string, error) {
-	if c.rpc.Config.Debug {
-		fmt.Printf("=== AddYouTubeSource ===\n")
-		fmt.Printf("Project ID: %s\n", projectID)
-		fmt.Printf("Video ID: %s\n", videoID)
-	}
+	c.log().Debug("adding YouTube source", "project", projectID, "video", videoID)
 
 	// Modified payload structure for YouTube
 	payload := []interface{}{
@@ -470,10 +496,6 @@ func (c *Client) AddYouTubeSource(projectID, videoID string) (string, error) {
 		projectID,
 	}
 
-	if c.rpc.Config.Debug {
-		fmt.Printf("\nPayload Structure:\n")
-	}
-
 	resp, err := c.rpc.Do(rpc.Call{
 		ID:         rpc.RPCAddSources,
 		NotebookID: projectID,
@@ -483,9 +505,7 @@ func (c *Client) AddYouTubeSource(projectID, videoID string) (string, error) {
 		return "", fmt.Errorf("add YouTube source: %w", err)
 	}
 
-	if c.rpc.Config.Debug {
-		fmt.Printf("\nRaw Response:\n%s\n", string(resp))
-	}
+	c.log().Debug("add YouTube source response", "bytes", len(resp), "body", string(resp))
 
 	if len(resp) == 0 {
 		return "", fmt.Errorf("empty response from server (check debug output for request details)")
@@ -823,10 +843,7 @@ func (c *Client) createAudioOverviewDirectRPC(projectID, instructions string) (*
 			if len(audioData) > 2 {
 				if id, ok := audioData[2].(string); ok {
 					result.AudioID = id
-					// Log for debugging
-					if c.config.Debug {
-						fmt.Printf("Audio creation initiated with ID: %s\n", id)
-					}
+					c.log().Debug("audio creation initiated", "audio", id)
 				}
 			}
 		}
@@ -1041,9 +1058,7 @@ func (c *Client) CreateVideoOverview(projectID, instructions string) (*VideoOver
 			// First element is video ID
 			if id, ok := videoData[0].(string); ok {
 				result.VideoID = id
-				if c.config.Debug {
-					fmt.Printf("Video creation initiated with ID: %s\n", id)
-				}
+				c.log().Debug("video creation initiated", "video", id)
 			}
 			// Second element is title
 			if len(videoData) > 1 {
@@ -1074,29 +1089,22 @@ func (c *Client) DownloadAudioOverview(projectID string) (*AudioOverviewResult,
 	requestTypes := []int{0, 1, 2, 3, 4, 5}
 
 	for _, requestType := range requestTypes {
-		if c.config.Debug {
-			fmt.Printf("Trying request_type=%d for audio download...\n", requestType)
-		}
+		log := c.log().With("request_type", requestType)
+		log.Debug("trying audio download")
 
 		result, err := c.getAudioOverviewDirectRPCWithType(projectID, requestType)
 		if err != nil {
-			if c.config.Debug {
-				fmt.Printf("Request type %d failed: %v\n", requestType, err)
-			}
+			log.Debug("audio download request failed", "error", err)
 			continue
 		}
 
 		// Check if this request type returned audio data
 		if result.AudioData != "" {
-			if c.config.Debug {
-				fmt.Printf("Found audio data with request_type=%d (data length: %d)\n", requestType, len(result.AudioData))
-			}
+			log.Debug("found audio data", "bytes", len(result.AudioData))
 			return result, nil
 		}
 
-		if c.config.Debug {
-			fmt.Printf("Request type %d returned no audio data\n", requestType)
-		}
+		log.Debug("audio download returned no data")
 	}
 
 	return nil, fmt.Errorf("no request type returned audio data - the audio may not be ready yet")
@@ -1108,16 +1116,11 @@ func (r *AudioOverviewResult) SaveAudioToFile(filename string) error {
 		return fmt.Errorf("no audio data to save")
 	}
 
-	audioBytes, err := r.GetAudioBytes()
-	if err != nil {
-		return fmt.Errorf("decode audio data: %w", err)
-	}
-
-	//nolint:gosec // user-requested output file should be readable
-	if err := os.WriteFile(filename, audioBytes, 0o644); err != nil {
+	// Decode while writing rather than holding a second copy in memory.
+	dec := base64.NewDecoder(base64.StdEncoding, strings.NewReader(r.AudioData))
+	if _, err := writeMedia(dec, "", filename, DownloadOptions{}); err != nil {
 		return fmt.Errorf("write audio file: %w", err)
 	}
-
 	return nil
 }
 
@@ -1132,10 +1135,8 @@ func (c *Client) ListAudioOverviews(projectID string) ([]*AudioOverviewResult, e
 			// No audio overview exists
 			return []*AudioOverviewResult{}, nil
 		}
-		// For other errors, still return empty list but log if debug
-		if c.config.Debug {
-			fmt.Printf("Error getting audio overview: %v\n", err)
-		}
+		// For other errors, still return empty list but log the failure
+		c.log().Debug("get audio overview failed", "project", projectID, "error", err)
 		return []*AudioOverviewResult{}, nil
 	}
 
@@ -1165,9 +1166,7 @@ func (c *Client) ListVideoOverviews(projectID string) ([]*VideoOverviewResult, e
 	if project != nil && project.Metadata != nil {
 		// Look for video-related metadata (this is speculative)
 		// Will need to be updated when we discover the actual structure
-		if c.config.Debug {
-			fmt.Printf("Project %s metadata: %+v\n", projectID, project.Metadata)
-		}
+		c.log().Debug("project metadata", "project", projectID, "metadata", project.Metadata)
 	}
 
 	return results, nil
@@ -1200,21 +1199,16 @@ func (c *Client) getVideoOverviewAlternative(projectID string) (*VideoOverviewRe
 	}
 
 	for i, approach := range approaches {
-		if c.config.Debug {
-			fmt.Printf("Trying video overview approach %d...\n", i+1)
-		}
+		log := c.log().With("approach", i+1)
+		log.Debug("trying video overview approach")
 
 		result, err := approach(projectID)
 		if err == nil && result != nil {
-			if c.config.Debug {
-				fmt.Printf("Video overview approach %d succeeded\n", i+1)
-			}
+			log.Debug("video overview approach succeeded")
 			return result, nil
 		}
 
-		if c.config.Debug {
-			fmt.Printf("Video overview approach %d failed: %v\n", i+1, err)
-		}
+		log.Debug("video overview approach failed", "error", err)
 	}
 
 	_ = project // Use project to avoid unused variable warning
@@ -1391,8 +1385,8 @@ func (c *Client) tryGetVideoDownloadURL(result *VideoOverviewResult) error {
 	if videoUrl, err := c.getVideoURLFromAPI(result.ProjectID, result.VideoID); err == nil {
 		result.VideoData = videoUrl
 		return nil
-	} else if c.config.Debug {
-		fmt.Printf("API video URL lookup failed: %v\n", err)
+	} else {
+		c.log().Debug("API video URL lookup failed", "error", err)
 	}
 
 	// Method 2: Check if the video ID itself is a URL or contains URL components
@@ -1414,8 +1408,8 @@ func (c *Client) getVideoURLFromAPI(projectID, videoID string) (string, error) {
 	}
 
 	// Look for video metadata in project that might contain URLs
-	if project.Metadata != nil && c.config.Debug {
-		fmt.Printf("Project metadata: %+v\n", project.Metadata)
+	if project.Metadata != nil {
+		c.log().Debug("project metadata", "project", projectID, "metadata", project.Metadata)
 	}
 
 	// Try to use the CreateVideoOverview with different parameters to get existing video data
@@ -1503,9 +1497,7 @@ func (c *Client) findVideoURL(item interface{}) string {
 	case string:
 		// Check if this string is a video URL
 		if strings.Contains(v, "googleusercontent.com") && (strings.Contains(v, "notebooklm") || strings.Contains(v, "rd-notebooklm")) {
-			if c.config.Debug {
-				fmt.Printf("Found potential video URL: %s\n", v)
-			}
+			c.log().Debug("found potential video URL", "url", v)
 			return v
 		}
 	case []interface{}:
@@ -1613,90 +1605,26 @@ func (r *VideoOverviewResult) saveBase64VideoToFile(base64Data, filename string)
 	return nil
 }
 
-// DownloadVideoWithAuth downloads a video using the client's authentication
+// DownloadVideoWithAuth downloads a video using the client's authentication.
+// Interrupted downloads resume on the next call; see DownloadMedia.
 func (c *Client) DownloadVideoWithAuth(videoURL, filename string) error {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 300 * time.Second, // 5 minute timeout for large video downloads
-	}
-
-	// Create request
-	req, err := http.NewRequest("GET", videoURL, nil)
-	if err != nil {
-		return fmt.Errorf("create video download request: %w", err)
-	}
-
-	// Add browser-like headers
-	req.Header.Set("Accept", "*/*")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.6")
-	req.Header.Set("Range", "bytes=0-")
-	req.Header.Set("Referer", "https://notebooklm.google.com/")
-	req.Header.Set("Sec-Fetch-Dest", "video")
-	req.Header.Set("Sec-Fetch-Mode", "no-cors")
-	req.Header.Set("Sec-Fetch-Site", "cross-site")
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/140.0.0.0 Safari/537.36")
-
-	// Get cookies from environment (same as nlm client uses)
-	cookies := os.Getenv("NLM_COOKIES")
-	if cookies != "" {
-		req.Header.Set("Cookie", cookies)
-	}
-
-	// Get auth token and add as query parameter if needed
-	authToken := os.Getenv("NLM_AUTH_TOKEN")
-	if authToken != "" && !strings.Contains(videoURL, "authuser=") {
-		// Add authuser=0 parameter if not present
-		separator := "?"
-		if strings.Contains(videoURL, "?") {
-			separator = "&"
-		}
-		req.URL, _ = url.Parse(videoURL + separator + "authuser=0")
-	}
-
-	if c.config.Debug {
-		fmt.Printf("Downloading video from: %s\n", req.URL.String())
-		fmt.Printf("Using cookies: %v\n", cookies != "")
-	}
-
-	// Make the request
-	resp, err := client.Do(req)
-	if err != nil {
+	if _, err := c.DownloadMedia(videoURL, filename, DownloadOptions{}); err != nil {
 		return fmt.Errorf("download video: %w", err)
 	}
-	defer func() {
-		_ = resp.Body.Close()
-	}()
-
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
-		return fmt.Errorf("download failed with status: %s (check authentication)", resp.Status)
-	}
-
-	// Create output file
-	//nolint:gosec // file path is user-provided output
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("create video file: %w", err)
-	}
-	defer func() {
-		_ = file.Close()
-	}()
+	return nil
+}
 
-	// Copy with progress if debug enabled
-	if c.config.Debug {
-		// Get content length for progress
-		contentLength := resp.ContentLength
-		if contentLength > 0 {
-			fmt.Printf("Video size: %.2f MB\n", float64(contentLength)/(1024*1024))
-		}
+// GetArtifact returns a single artifact, including its current state.
+func (c *Client) GetArtifact(artifactID string) (*pb.Artifact, error) {
+	req := &pb.GetArtifactRequest{
+		ArtifactId: artifactID,
 	}
-
-	// Copy the video data
-	_, err = io.Copy(file, resp.Body)
+	ctx := context.Background()
+	artifact, err := c.orchestrationService.GetArtifact(ctx, req)
 	if err != nil {
-		return fmt.Errorf("write video file: %w", err)
+		return nil, fmt.Errorf("get artifact: %w", err)
 	}
-
-	return nil
+	return artifact, nil
 }
 
 // ListArtifacts returns artifacts for a project using direct RPC
@@ -1719,9 +1647,7 @@ func (c *Client) ListArtifacts(projectID string) ([]*pb.Artifact, error) {
 		return nil, fmt.Errorf("parse artifacts response: %w", err)
 	}
 
-	if c.config.Debug {
-		fmt.Printf("Artifacts response: %+v\n", responseData)
-	}
+	c.log().Debug("list artifacts response", "project", projectID, "response", responseData)
 
 	// Convert response to artifacts
 	var artifacts []*pb.Artifact
@@ -1769,9 +1695,7 @@ func (c *Client) RenameArtifact(artifactID, newTitle string) (*pb.Artifact, erro
 		return nil, fmt.Errorf("parse rename response: %w", err)
 	}
 
-	if c.config.Debug {
-		fmt.Printf("Rename artifact response: %+v\n", responseData)
-	}
+	c.log().Debug("rename artifact response", "artifact", artifactID, "response", responseData)
 
 	// The response should contain the updated artifact data
 	if len(responseData) > 0 {
@@ -1783,6 +1707,32 @@ func (c *Client) RenameArtifact(artifactID, newTitle string) (*pb.Artifact, erro
 	return nil, fmt.Errorf("failed to parse renamed artifact from response")
 }
 
+// UpdateArtifact updates the fields of artifact named by paths, such as
+// "tailored_report.content", "note.title" or "sources". Paths are proto
+// field names relative to pb.Artifact; unlisted fields are left unchanged.
+func (c *Client) UpdateArtifact(ctx context.Context, artifact *pb.Artifact, paths ...string) (*pb.Artifact, error) {
+	if artifact.GetArtifactId() == "" {
+		return nil, fmt.Errorf("update artifact: artifact ID required")
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("update artifact: no fields to update")
+	}
+	mask, err := fieldmaskpb.New(artifact, paths...)
+	if err != nil {
+		return nil, fmt.Errorf("update artifact: %w", err)
+	}
+	mask.Normalize()
+	req := &pb.UpdateArtifactRequest{
+		Artifact:   artifact,
+		UpdateMask: mask,
+	}
+	updated, err := c.orchestrationService.UpdateArtifact(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("update artifact: %w", err)
+	}
+	return updated, nil
+}
+
 // parseArtifactFromResponse parses an artifact from RPC response data
 func (c *Client) parseArtifactFromResponse(data interface{}) *pb.Artifact {
 	artifactData, ok := data.([]interface{})
@@ -1920,125 +1870,78 @@ func (c *Client) StartSection(projectID string) (*pb.StartSectionResponse, error
 	return section, nil
 }
 
-func (c *Client) GenerateFreeFormStreamed(projectID, prompt string, sourceIDs []string) (*pb.GenerateFreeFormStreamedResponse, error) {
-	// Check if we should skip sources (useful for testing or when project is inaccessible)
-	skipSources := os.Getenv("NLM_SKIP_SOURCES") == envTrue
-
-	// If no source IDs provided and not skipping, try to get all sources from the project
-	if len(sourceIDs) == 0 && !skipSources {
-		// Create a timeout context for getting project
-		getProjectCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-
-		project, err := c.GetProjectWithContext(getProjectCtx, projectID)
-		if err != nil {
-			// If getting project fails, try without sources as fallback
-			if c.config.Debug {
-				fmt.Printf("DEBUG: Failed to get project sources, continuing without: %v\n", err)
-			}
-			// Continue without sources rather than failing completely
-		} else {
-			// Extract all source IDs from the project
-			for _, source := range project.Sources {
-				if source.SourceId != nil {
-					sourceIDs = append(sourceIDs, source.SourceId.SourceId)
-				}
-			}
-
-			if c.config.Debug {
-				fmt.Printf("DEBUG: Using %d sources for chat\n", len(sourceIDs))
-			}
-		}
-	}
-
-	req := &pb.GenerateFreeFormStreamedRequest{
-		ProjectId: projectID,
-		Prompt:    prompt,
-		SourceIds: sourceIDs,
-	}
+// GenerateFreeFormStreamed asks prompt against the given sources of
+// projectID, or all of its sources if sourceIDs is empty, and returns the
+// answer with its citations.
+func (c *Client) GenerateFreeFormStreamed(projectID, prompt string, sourceIDs []string) (*ChatResponse, error) {
+	sourceIDs = c.chatSourceIDs(projectID, sourceIDs)
 
 	// Use a timeout context for the chat request
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	response, err := c.orchestrationService.GenerateFreeFormStreamed(ctx, req)
+	response, err := c.chat(ctx, sourceIDs, prompt)
 	if err != nil {
 		return nil, fmt.Errorf("generate free form streamed: %w", err)
 	}
 	return response, nil
 }
 
-// GenerateFreeFormStreamedWithCallback streams the response and calls the callback for each chunk
-func (c *Client) GenerateFreeFormStreamedWithCallback(projectID, prompt string, sourceIDs []string, callback func(chunk string) bool) error {
-	// Check if we should skip sources (useful for testing or when project is inaccessible)
-	skipSources := os.Getenv("NLM_SKIP_SOURCES") == envTrue
+// GenerateFreeFormStreamedWithCallback streams the response and calls the
+// callback for each chunk. Once streaming finishes, it returns the complete
+// response, including citations.
+func (c *Client) GenerateFreeFormStreamedWithCallback(projectID, prompt string, sourceIDs []string, callback func(chunk string) bool) (*ChatResponse, error) {
+	sourceIDs = c.chatSourceIDs(projectID, sourceIDs)
 
-	// If no source IDs provided and not skipping, try to get all sources from the project
-	if len(sourceIDs) == 0 && !skipSources {
-		// Create a timeout context for getting project
-		getProjectCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	// For now, we'll simulate streaming by calling the regular API and breaking the response into chunks
+	// In a real implementation, this would use server-sent events or similar streaming protocol
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-		project, err := c.GetProjectWithContext(getProjectCtx, projectID)
-		if err != nil {
-			// If getting project fails, try without sources as fallback
-			if c.config.Debug {
-				fmt.Printf("DEBUG: Failed to get project sources, continuing without: %v\n", err)
-			}
-			// Continue without sources rather than failing completely
-		} else {
-			// Extract all source IDs from the project
-			for _, source := range project.Sources {
-				if source.SourceId != nil {
-					sourceIDs = append(sourceIDs, source.SourceId.SourceId)
-				}
-			}
+	response, err := c.chat(ctx, sourceIDs, prompt)
+	if err != nil {
+		return nil, fmt.Errorf("generate free form streamed: %w", err)
+	}
 
-			if c.config.Debug {
-				fmt.Printf("DEBUG: Using %d sources for chat\n", len(sourceIDs))
-			}
+	// Simulate streaming by sending a word at a time, keeping the
+	// whitespace so that the chunks add up to response.Text.
+	for _, chunk := range streamChunks.FindAllString(response.Text, -1) {
+		if !callback(chunk) {
+			break // Stop if callback returns false
 		}
+
+		// Add a small delay to simulate streaming
+		time.Sleep(75 * time.Millisecond)
 	}
 
-	req := &pb.GenerateFreeFormStreamedRequest{
-		ProjectId: projectID,
-		Prompt:    prompt,
-		SourceIds: sourceIDs,
+	return response, nil
+}
+
+// chatSourceIDs returns sourceIDs, or when it is empty, every source in
+// projectID. Failing to list the project's sources is not fatal; the chat
+// then proceeds without sources.
+func (c *Client) chatSourceIDs(projectID string, sourceIDs []string) []string {
+	// Check if we should skip sources (useful for testing or when project is inaccessible)
+	if len(sourceIDs) > 0 || os.Getenv("NLM_SKIP_SOURCES") == envTrue {
+		return sourceIDs
 	}
 
-	// For now, we'll simulate streaming by calling the regular API and breaking the response into chunks
-	// In a real implementation, this would use server-sent events or similar streaming protocol
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// Create a timeout context for getting project
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	response, err := c.orchestrationService.GenerateFreeFormStreamed(ctx, req)
+	project, err := c.GetProjectWithContext(ctx, projectID)
 	if err != nil {
-		return fmt.Errorf("generate free form streamed: %w", err)
+		c.log().Debug("get project sources failed, continuing without", "project", projectID, "error", err)
+		return sourceIDs
 	}
-
-	if response != nil && response.Chunk != "" {
-		// Simulate streaming by sending words gradually
-		words := strings.Fields(response.Chunk)
-		for i, word := range words {
-			// Create a chunk with a few words at a time
-			var chunk string
-			if i == 0 {
-				chunk = word
-			} else {
-				chunk = " " + word
-			}
-
-			// Call the callback with the chunk
-			if !callback(chunk) {
-				break // Stop if callback returns false
-			}
-
-			// Add a small delay to simulate streaming
-			time.Sleep(75 * time.Millisecond)
+	for _, source := range project.Sources {
+		if source.SourceId != nil {
+			sourceIDs = append(sourceIDs, source.SourceId.SourceId)
 		}
 	}
-
-	return nil
+	c.log().Debug("using sources for chat", "project", projectID, "sources", len(sourceIDs))
+	return sourceIDs
 }
 
 // GetProjectWithContext is like GetProject but accepts a context for cancellation
@@ -2052,8 +1955,8 @@ func (c *Client) GetProjectWithContext(ctx context.Context, projectID string) (*
 		return nil, fmt.Errorf("get project: %w", err)
 	}
 
-	if c.config.Debug && project.Sources != nil {
-		fmt.Printf("DEBUG: Successfully parsed project with %d sources\n", len(project.Sources))
+	if project.Sources != nil {
+		c.log().Debug("parsed project", "project", projectID, "sources", len(project.Sources))
 	}
 	return project, nil
 }
@@ -1,8 +1,11 @@
 package api
 
 import (
+	"context"
 	"strings"
 	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
 )
 
 func TestDetectMIMEType(t *testing.T) {
@@ -45,3 +48,24 @@ func TestDetectMIMEType(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateArtifactValidatesMask(t *testing.T) {
+	c := &Client{}
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		artifact *pb.Artifact
+		paths    []string
+		want     string
+	}{
+		{"missing ID", &pb.Artifact{}, []string{"sources"}, "artifact ID required"},
+		{"no paths", &pb.Artifact{ArtifactId: "a"}, nil, "no fields to update"},
+		{"unknown path", &pb.Artifact{ArtifactId: "a"}, []string{"tailored_report.body"}, "tailored_report.body"},
+	}
+	for _, tt := range tests {
+		_, err := c.UpdateArtifact(ctx, tt.artifact, tt.paths...)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: UpdateArtifact() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
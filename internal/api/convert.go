@@ -0,0 +1,241 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// A Converter turns a document that NotebookLM ingests poorly into
+// Markdown or plain text. The name is the document's file name or path; it
+// is used for headings and to pick a language for code.
+type Converter interface {
+	Convert(name string, data []byte) (string, error)
+}
+
+// ConverterFunc adapts an ordinary function to the Converter interface.
+type ConverterFunc func(name string, data []byte) (string, error)
+
+// Convert calls f(name, data).
+func (f ConverterFunc) Convert(name string, data []byte) (string, error) {
+	return f(name, data)
+}
+
+// Converters is a registry of converters keyed by MIME type. It is safe
+// for concurrent use.
+type Converters struct {
+	mu     sync.RWMutex
+	byType map[string]Converter
+}
+
+// NewConverters returns an empty registry.
+func NewConverters() *Converters {
+	return &Converters{byType: map[string]Converter{}}
+}
+
+// Register sets the converter for a MIME type, replacing any previous one.
+func (r *Converters) Register(mimeType string, c Converter) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byType[mimeType] = c
+}
+
+// Lookup returns the converter for a MIME type.
+func (r *Converters) Lookup(mimeType string) (Converter, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.byType[mimeType]
+	return c, ok
+}
+
+// Convert converts data if the registry has a converter for its MIME type,
+// as found by DocumentMIMEType. It returns ok == false, and no error, if
+// there is none.
+func (r *Converters) Convert(name string, data []byte) (text string, ok bool, err error) {
+	mimeType := DocumentMIMEType(name, data)
+	c, ok := r.Lookup(mimeType)
+	if !ok {
+		return "", false, nil
+	}
+	text, err = c.Convert(name, data)
+	if err != nil {
+		return "", false, fmt.Errorf("convert %s (%s): %w", name, mimeType, err)
+	}
+	return text, true, nil
+}
+
+// MIME types of the documents the built-in converters handle.
+const (
+	MIMETypeHTML    = "text/html"
+	MIMETypeDOCX    = "application/vnd.openxmlformats-officedocument.wordprocessingml.document"
+	MIMETypeEPUB    = "application/epub+zip"
+	MIMETypeJupyter = "application/x-ipynb+json"
+)
+
+// codeLanguages maps source file extensions to the language named in
+// Markdown code fences. Code files get the MIME type "text/x-<language>".
+var codeLanguages = map[string]string{
+	".go": "go", ".py": "python", ".js": "javascript", ".mjs": "javascript", ".jsx": "jsx",
+	".ts": "typescript", ".tsx": "tsx", ".java": "java", ".kt": "kotlin", ".scala": "scala",
+	".c": "c", ".h": "c", ".cc": "cpp", ".cpp": "cpp", ".hpp": "cpp", ".cs": "csharp",
+	".rs": "rust", ".rb": "ruby", ".php": "php", ".swift": "swift", ".m": "objectivec",
+	".sh": "bash", ".bash": "bash", ".zsh": "bash", ".ps1": "powershell",
+	".sql": "sql", ".proto": "protobuf", ".lua": "lua", ".r": "r", ".dart": "dart",
+	".ex": "elixir", ".exs": "elixir", ".erl": "erlang", ".hs": "haskell", ".clj": "clojure",
+	".vue": "vue", ".svelte": "svelte", ".zig": "zig", ".nim": "nim", ".pl": "perl",
+}
+
+// documentTypes maps extensions to the MIME types of convertible documents,
+// since the system MIME table often lacks them.
+var documentTypes = map[string]string{
+	".html": MIMETypeHTML, ".htm": MIMETypeHTML, ".xhtml": MIMETypeHTML,
+	".docx": MIMETypeDOCX, ".epub": MIMETypeEPUB, ".ipynb": MIMETypeJupyter,
+}
+
+// DocumentMIMEType returns the MIME type of a document, without
+// parameters, from its name and, failing that, its content.
+func DocumentMIMEType(name string, data []byte) string {
+	ext := strings.ToLower(filepath.Ext(name))
+	if t, ok := documentTypes[ext]; ok {
+		return t
+	}
+	if lang, ok := codeLanguages[ext]; ok {
+		return "text/x-" + lang
+	}
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		if t := zipDocumentType(data); t != "" {
+			return t
+		}
+	}
+	t := detectMIMEType(data, name, "")
+	if mt, _, err := mime.ParseMediaType(t); err == nil {
+		t = mt
+	}
+	return t
+}
+
+// zipDocumentType tells DOCX and EPUB files apart from other zip archives.
+func zipDocumentType(data []byte) string {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return ""
+	}
+	for _, f := range zr.File {
+		switch f.Name {
+		case "word/document.xml":
+			return MIMETypeDOCX
+		case "META-INF/container.xml":
+			return MIMETypeEPUB
+		}
+	}
+	return ""
+}
+
+// DefaultConverters holds the built-in converters: HTML to Markdown with
+// the page's main content extracted, DOCX and EPUB to text, Jupyter
+// notebooks to Markdown, and source code to fenced Markdown.
+var DefaultConverters = newDefaultConverters()
+
+func newDefaultConverters() *Converters {
+	r := NewConverters()
+	r.Register(MIMETypeHTML, ConverterFunc(convertHTML))
+	r.Register(MIMETypeDOCX, ConverterFunc(convertDOCX))
+	r.Register(MIMETypeEPUB, ConverterFunc(convertEPUB))
+	r.Register(MIMETypeJupyter, ConverterFunc(convertJupyter))
+	for _, lang := range codeLanguages {
+		r.Register("text/x-"+lang, ConverterFunc(CodeSection))
+	}
+	return r
+}
+
+// CodeSection renders a source file as a Markdown section headed by its
+// path, with the code fenced in the language its extension names.
+func CodeSection(name string, data []byte) (string, error) {
+	if !utf8.Valid(data) {
+		return "", errors.New("not UTF-8 text")
+	}
+	lang := codeLanguages[strings.ToLower(filepath.Ext(name))]
+	return fmt.Sprintf("## %s\n\n%s\n", filepath.ToSlash(name), fenced(lang, string(data))), nil
+}
+
+// fenced wraps text in a Markdown code fence longer than any backtick run
+// inside it.
+func fenced(lang, text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + lang + "\n" + strings.TrimRight(text, "\n") + "\n" + fence
+}
+
+// maxTreeFile is the largest file ConvertTree includes.
+const maxTreeFile = 1 << 20
+
+// skipDirs are directories ConvertTree does not enter.
+var skipDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "testdata": true, "dist": true, "build": true,
+	"target": true, "__pycache__": true,
+}
+
+// ConvertTree renders the source files under root as one Markdown
+// document, each file under a heading with its path relative to root.
+// Hidden and dependency directories, files in languages it does not know
+// and files over 1 MB are skipped.
+func ConvertTree(root string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p != root && (strings.HasPrefix(name, ".") || skipDirs[name]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if _, ok := codeLanguages[strings.ToLower(path.Ext(name))]; !ok || strings.HasPrefix(name, ".") {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxTreeFile {
+			return nil
+		}
+		files = append(files, p)
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("walk %s: %w", root, err)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no source files found in %s", root)
+	}
+	sort.Strings(files)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", filepath.Base(filepath.Clean(root)))
+	for _, p := range files {
+		//nolint:gosec // files found under a user-provided directory
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return "", err
+		}
+		rel, _ := filepath.Rel(root, p)
+		section, err := CodeSection(rel, data)
+		if err != nil {
+			continue // binary file with a source extension
+		}
+		b.WriteString(section)
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxZipEntry bounds how much of one archive entry is read.
+const maxZipEntry = 50 << 20
+
+func readZipFile(zr *zip.Reader, name string) ([]byte, error) {
+	f, err := zr.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, maxZipEntry))
+}
+
+// convertDOCX extracts the text of a Word document, keeping headings and
+// list items as Markdown.
+func convertDOCX(name string, data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	doc, err := readZipFile(zr, "word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("read document: %w", err)
+	}
+
+	var b, para strings.Builder
+	prefix := ""
+	dec := xml.NewDecoder(bytes.NewReader(doc))
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("parse document: %w", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				prefix = ""
+			case "pStyle":
+				prefix = headingPrefix(xmlAttr(t, "val"))
+			case "numPr":
+				if prefix == "" {
+					prefix = "- "
+				}
+			case "t":
+				var s string
+				if err := dec.DecodeElement(&s, &t); err != nil {
+					return "", fmt.Errorf("parse document: %w", err)
+				}
+				para.WriteString(s)
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			}
+		case xml.EndElement:
+			if t.Name.Local == "p" {
+				if text := strings.TrimSpace(para.String()); text != "" {
+					b.WriteString(prefix + text + "\n\n")
+				}
+			}
+		}
+	}
+	if b.Len() == 0 {
+		return "", errors.New("document has no text")
+	}
+	return b.String(), nil
+}
+
+func xmlAttr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// headingPrefix maps Word paragraph styles such as "Heading2" or "Title"
+// to Markdown heading markers.
+func headingPrefix(style string) string {
+	s := strings.ToLower(strings.ReplaceAll(style, " ", ""))
+	switch {
+	case s == "title":
+		return "# "
+	case strings.HasPrefix(s, "heading") && len(s) == len("heading")+1:
+		if level := s[len(s)-1]; level >= '1' && level <= '5' {
+			// Title takes level 1, so headings start at level 2.
+			return strings.Repeat("#", int(level-'0')+1) + " "
+		}
+	}
+	return ""
+}
+
+// convertEPUB renders the chapters of an EPUB book, in reading order, as
+// Markdown.
+func convertEPUB(name string, data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	container, err := readZipFile(zr, "META-INF/container.xml")
+	if err != nil {
+		return "", fmt.Errorf("read container: %w", err)
+	}
+	var c struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err := xml.Unmarshal(container, &c); err != nil || len(c.Rootfiles) == 0 {
+		return "", fmt.Errorf("read container: no package document")
+	}
+	opfPath := c.Rootfiles[0].FullPath
+	opfData, err := readZipFile(zr, opfPath)
+	if err != nil {
+		return "", fmt.Errorf("read package document: %w", err)
+	}
+	var opf struct {
+		Title    string `xml:"metadata>title"`
+		Manifest []struct {
+			ID        string `xml:"id,attr"`
+			Href      string `xml:"href,attr"`
+			MediaType string `xml:"media-type,attr"`
+		} `xml:"manifest>item"`
+		Spine []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err := xml.Unmarshal(opfData, &opf); err != nil {
+		return "", fmt.Errorf("read package document: %w", err)
+	}
+	hrefs := map[string]string{}
+	for _, it := range opf.Manifest {
+		if strings.Contains(it.MediaType, "html") {
+			hrefs[it.ID] = it.Href
+		}
+	}
+
+	var b strings.Builder
+	if title := strings.TrimSpace(opf.Title); title != "" {
+		b.WriteString("# " + title + "\n\n")
+	}
+	dir := path.Dir(opfPath)
+	for _, ref := range opf.Spine {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		chapter, err := readZipFile(zr, path.Join(dir, href))
+		if err != nil {
+			return "", fmt.Errorf("read %s: %w", href, err)
+		}
+		md, err := htmlToMarkdown(chapter)
+		if err != nil {
+			return "", fmt.Errorf("convert %s: %w", href, err)
+		}
+		if strings.TrimSpace(md) != "" {
+			b.WriteString(md + "\n")
+		}
+	}
+	if b.Len() == 0 {
+		return "", errors.New("book has no chapters")
+	}
+	return b.String(), nil
+}
+
+// notebookText is a Jupyter string field, which is either a string or a
+// list of lines.
+type notebookText string
+
+func (t *notebookText) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*t = notebookText(strings.Join(lines, ""))
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	*t = notebookText(s)
+	return nil
+}
+
+// convertJupyter renders a Jupyter notebook as Markdown: Markdown cells as
+// they are, code cells fenced in the kernel's language, followed by their
+// text output.
+func convertJupyter(name string, data []byte) (string, error) {
+	var nb struct {
+		Cells []struct {
+			CellType string       `json:"cell_type"`
+			Source   notebookText `json:"source"`
+			Outputs  []struct {
+				Text notebookText `json:"text"`
+				Data struct {
+					Plain notebookText `json:"text/plain"`
+				} `json:"data"`
+				EName  string `json:"ename"`
+				EValue string `json:"evalue"`
+			} `json:"outputs"`
+		} `json:"cells"`
+		Metadata struct {
+			LanguageInfo struct {
+				Name string `json:"name"`
+			} `json:"language_info"`
+			KernelSpec struct {
+				Language string `json:"language"`
+			} `json:"kernelspec"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &nb); err != nil {
+		return "", fmt.Errorf("parse notebook: %w", err)
+	}
+	lang := nb.Metadata.LanguageInfo.Name
+	if lang == "" {
+		lang = nb.Metadata.KernelSpec.Language
+	}
+
+	var b strings.Builder
+	for _, cell := range nb.Cells {
+		src := strings.TrimSpace(string(cell.Source))
+		if src == "" {
+			continue
+		}
+		switch cell.CellType {
+		case "code":
+			b.WriteString(fenced(lang, src) + "\n\n")
+			for _, out := range cell.Outputs {
+				text := string(out.Text)
+				if text == "" {
+					text = string(out.Data.Plain)
+				}
+				if text == "" && out.EName != "" {
+					text = out.EName + ": " + out.EValue
+				}
+				if strings.TrimSpace(text) != "" {
+					b.WriteString("Output:\n\n" + fenced("text", text) + "\n\n")
+				}
+			}
+		default:
+			b.WriteString(src + "\n\n")
+		}
+	}
+	if b.Len() == 0 {
+		return "", errors.New("notebook has no cells")
+	}
+	return b.String(), nil
+}
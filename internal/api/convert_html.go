@@ -0,0 +1,333 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// convertHTML extracts the main content of a web page, the way reader
+// modes do, and renders it as Markdown under the page's title.
+func convertHTML(name string, data []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	title := strings.TrimSpace(textOf(find(doc, atom.Title)))
+	removeBoilerplate(doc)
+	content := mainContent(doc)
+	if content == nil {
+		return "", fmt.Errorf("no content found")
+	}
+	md := renderMarkdown(content)
+	if title != "" && !strings.HasPrefix(md, "# ") {
+		md = "# " + title + "\n\n" + md
+	}
+	return md, nil
+}
+
+// htmlToMarkdown renders a whole HTML document, or its body, as Markdown,
+// without looking for the main content.
+func htmlToMarkdown(data []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	removeNodes(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Head, atom.Noscript:
+			return true
+		}
+		return false
+	})
+	root := find(doc, atom.Body)
+	if root == nil {
+		root = doc
+	}
+	return renderMarkdown(root), nil
+}
+
+func find(n *html.Node, a atom.Atom) *html.Node {
+	if n.Type == html.ElementNode && n.DataAtom == a {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if f := find(c, a); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func textOf(n *html.Node) string {
+	if n == nil {
+		return ""
+	}
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// removeNodes deletes the nodes for which drop reports true, with their
+// children.
+func removeNodes(n *html.Node, drop func(*html.Node) bool) {
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		if c.Type == html.CommentNode || (c.Type == html.ElementNode && drop(c)) {
+			n.RemoveChild(c)
+		} else {
+			removeNodes(c, drop)
+		}
+		c = next
+	}
+}
+
+var (
+	unlikelyContent = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|cookie|disqus|footer|header|menu|modal|nav|popup|promo|related|share|sidebar|social|sponsor|subscribe|advert|\bad-`)
+	likelyContent   = regexp.MustCompile(`(?i)article|body|content|main|post|story|entry`)
+)
+
+// removeBoilerplate drops scripts, navigation, forms and elements whose
+// class or id marks them as page furniture rather than content.
+func removeBoilerplate(doc *html.Node) {
+	removeNodes(doc, func(n *html.Node) bool {
+		switch n.DataAtom {
+		case atom.Script, atom.Style, atom.Noscript, atom.Nav, atom.Header, atom.Footer,
+			atom.Aside, atom.Form, atom.Iframe, atom.Svg, atom.Button, atom.Select, atom.Template:
+			return true
+		case atom.Body, atom.Html, atom.Article, atom.Main:
+			return false
+		}
+		if attr(n, "hidden") != "" || attr(n, "aria-hidden") == "true" || attr(n, "role") == "navigation" {
+			return true
+		}
+		marks := attr(n, "class") + " " + attr(n, "id")
+		return unlikelyContent.MatchString(marks) && !likelyContent.MatchString(marks)
+	})
+}
+
+// mainContent returns the element holding the page's main text: the
+// <article> or <main> with the most text if there is one, and otherwise
+// the element whose paragraphs score highest, as in Readability.
+func mainContent(doc *html.Node) *html.Node {
+	var best *html.Node
+	bestLen := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.Article || n.DataAtom == atom.Main) {
+			if l := len(strings.TrimSpace(textOf(n))); l > bestLen {
+				best, bestLen = n, l
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if best != nil && bestLen >= 200 {
+		return best
+	}
+
+	scores := map[*html.Node]float64{}
+	var order []*html.Node // scored nodes in document order, to break ties
+	add := func(n *html.Node, s float64) {
+		if _, ok := scores[n]; !ok {
+			order = append(order, n)
+		}
+		scores[n] += s
+	}
+	var score func(*html.Node)
+	score = func(n *html.Node) {
+		if n.Type == html.ElementNode && (n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Td) {
+			text := strings.TrimSpace(textOf(n))
+			if len(text) >= 25 {
+				s := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text))/100, 3)
+				if p := n.Parent; p != nil {
+					add(p, s)
+					if gp := p.Parent; gp != nil {
+						add(gp, s/2)
+					}
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			score(c)
+		}
+	}
+	score(doc)
+	var top *html.Node
+	for _, n := range order {
+		if top == nil || scores[n] > scores[top] {
+			top = n
+		}
+	}
+	if top != nil {
+		return top
+	}
+	if best != nil {
+		return best
+	}
+	return find(doc, atom.Body)
+}
+
+// renderMarkdown renders the subtree rooted at n as Markdown.
+func renderMarkdown(n *html.Node) string {
+	r := &mdRenderer{}
+	r.children(n)
+	out := strings.TrimSpace(blankLines.ReplaceAllString(r.b.String(), "\n\n"))
+	return out + "\n"
+}
+
+var (
+	blankLines = regexp.MustCompile(`\n[ \t]*(\n[ \t]*)+`)
+	spaceRun   = regexp.MustCompile(`\s+`)
+)
+
+type mdRenderer struct {
+	b      strings.Builder
+	lists  []listState
+	prefix string // line prefix, e.g. "> " inside blockquotes
+}
+
+type listState struct {
+	ordered bool
+	n       int
+}
+
+func (r *mdRenderer) write(s string) {
+	if r.prefix != "" {
+		s = strings.ReplaceAll(s, "\n", "\n"+r.prefix)
+	}
+	r.b.WriteString(s)
+}
+
+func (r *mdRenderer) block() {
+	r.write("\n\n")
+}
+
+func (r *mdRenderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.node(c)
+	}
+}
+
+func (r *mdRenderer) node(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		r.write(spaceRun.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+	default:
+		r.children(n)
+		return
+	}
+
+	switch n.DataAtom {
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level := int(n.Data[1] - '0')
+		r.block()
+		r.write(strings.Repeat("#", level) + " " + strings.TrimSpace(spaceRun.ReplaceAllString(textOf(n), " ")))
+		r.block()
+	case atom.P, atom.Div, atom.Section, atom.Article, atom.Main, atom.Figure, atom.Table:
+		r.block()
+		r.children(n)
+		r.block()
+	case atom.Tr:
+		r.write("\n|")
+		r.children(n)
+	case atom.Td, atom.Th:
+		r.write(" " + strings.TrimSpace(spaceRun.ReplaceAllString(textOf(n), " ")) + " |")
+	case atom.Br:
+		r.write("\n")
+	case atom.Hr:
+		r.block()
+		r.write("---")
+		r.block()
+	case atom.Pre:
+		r.block()
+		r.write(fenced("", textOf(n)))
+		r.block()
+	case atom.Code:
+		r.write("`" + textOf(n) + "`")
+	case atom.Strong, atom.B:
+		r.wrap(n, "**")
+	case atom.Em, atom.I:
+		r.wrap(n, "*")
+	case atom.A:
+		text := strings.TrimSpace(spaceRun.ReplaceAllString(textOf(n), " "))
+		href := attr(n, "href")
+		if text == "" {
+			return
+		}
+		if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(href, "javascript:") {
+			r.write(text)
+			return
+		}
+		r.write("[" + text + "](" + href + ")")
+	case atom.Img:
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.write("[Image: " + alt + "]")
+		}
+	case atom.Ul, atom.Ol:
+		r.lists = append(r.lists, listState{ordered: n.DataAtom == atom.Ol})
+		r.block()
+		r.children(n)
+		r.lists = r.lists[:len(r.lists)-1]
+		r.block()
+	case atom.Li:
+		indent := ""
+		marker := "- "
+		if depth := len(r.lists); depth > 0 {
+			indent = strings.Repeat("  ", depth-1)
+			l := &r.lists[depth-1]
+			if l.ordered {
+				l.n++
+				marker = fmt.Sprintf("%d. ", l.n)
+			}
+		}
+		r.write("\n" + indent + marker)
+		r.children(n)
+	case atom.Blockquote:
+		r.block()
+		saved := r.prefix
+		r.prefix += "> "
+		r.write("> ")
+		r.children(n)
+		r.prefix = saved
+		r.block()
+	default:
+		r.children(n)
+	}
+}
+
+// wrap renders n's children between marks, unless they are empty.
+func (r *mdRenderer) wrap(n *html.Node, mark string) {
+	text := strings.TrimSpace(spaceRun.ReplaceAllString(textOf(n), " "))
+	if text == "" {
+		return
+	}
+	r.write(mark + text + mark)
+}
@@ -0,0 +1,245 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// zipOf builds a zip archive from name, content pairs.
+func zipOf(t *testing.T, files ...string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for i := 0; i < len(files); i += 2 {
+		w, err := zw.Create(files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(files[i+1])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const testDocx = `<?xml version="1.0"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>Report</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading1"/></w:pPr><w:r><w:t>Summary</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Sales rose </w:t></w:r><w:r><w:t>12%.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>First point</w:t></w:r></w:p>
+<w:p></w:p>
+</w:body></w:document>`
+
+func TestDocumentMIMEType(t *testing.T) {
+	docx := zipOf(t, "word/document.xml", testDocx)
+	epub := zipOf(t, "mimetype", "application/epub+zip", "META-INF/container.xml", "<container/>")
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"page.HTML", nil, MIMETypeHTML},
+		{"stdin", []byte("<!DOCTYPE html><html><body>x</body></html>"), MIMETypeHTML},
+		{"report.docx", nil, MIMETypeDOCX},
+		{"stdin", docx, MIMETypeDOCX},
+		{"stdin", epub, MIMETypeEPUB},
+		{"analysis.ipynb", nil, MIMETypeJupyter},
+		{"main.go", nil, "text/x-go"},
+		{"notes.txt", []byte("plain words"), "text/plain"},
+	}
+	for _, tt := range tests {
+		if got := DocumentMIMEType(tt.name, tt.data); got != tt.want {
+			t.Errorf("DocumentMIMEType(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestConvertersRegistry(t *testing.T) {
+	r := NewConverters()
+	if _, ok, err := r.Convert("notes.txt", []byte("text")); ok || err != nil {
+		t.Errorf("empty registry converted: %v, %v", ok, err)
+	}
+	r.Register("text/plain", ConverterFunc(func(name string, data []byte) (string, error) {
+		return strings.ToUpper(string(data)), nil
+	}))
+	got, ok, err := r.Convert("notes.txt", []byte("text"))
+	if err != nil || !ok || got != "TEXT" {
+		t.Errorf("Convert = %q, %v, %v", got, ok, err)
+	}
+	for _, mt := range []string{MIMETypeHTML, MIMETypeDOCX, MIMETypeEPUB, MIMETypeJupyter, "text/x-python"} {
+		if _, ok := DefaultConverters.Lookup(mt); !ok {
+			t.Errorf("DefaultConverters has no converter for %s", mt)
+		}
+	}
+	if _, ok := DefaultConverters.Lookup("text/plain"); ok {
+		t.Error("DefaultConverters converts plain text")
+	}
+}
+
+func TestConvertHTML(t *testing.T) {
+	page := `<html><head><title>Go Memory Model</title><style>p{}</style></head><body>
+<nav><a href="/">Home</a> <a href="/doc">Docs</a></nav>
+<div class="sidebar-menu">Related links and other furniture</div>
+<article>
+<h1>The Go Memory Model</h1>
+<p>The Go memory model specifies the conditions under which reads of a variable in one goroutine
+can be guaranteed to observe values produced by writes to the same variable in a different goroutine.</p>
+<h2>Advice</h2>
+<p>Programs that modify data being simultaneously accessed by multiple goroutines must <em>serialize</em>
+such access, using <a href="https://go.dev/pkg/sync">sync</a> or <code>chan</code>.</p>
+<ul><li>Use channels</li><li>Use <strong>mutexes</strong></li></ul>
+<pre>x := 1
+y := 2</pre>
+</article>
+<footer>Copyright</footer><script>track()</script>
+</body></html>`
+	got, err := convertHTML("mem.html", []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# The Go Memory Model\n\nThe Go memory model specifies",
+		"## Advice",
+		"must *serialize* such access, using [sync](https://go.dev/pkg/sync) or `chan`.",
+		"- Use channels\n- Use **mutexes**",
+		"```\nx := 1\ny := 2\n```",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("convertHTML output lacks %q:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"Home", "furniture", "Copyright", "track()", "p{}"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("convertHTML output contains boilerplate %q:\n%s", unwanted, got)
+		}
+	}
+}
+
+func TestConvertHTMLWithoutArticle(t *testing.T) {
+	page := `<html><head><title>Blog</title></head><body>
+<div id="header-links"><a href="/">Home</a></div>
+<div class="post-body"><p>First paragraph of the post, which is long enough to count.</p>
+<p>Second paragraph, with commas, clauses, and more words.</p></div>
+<div class="comments"><p>Nice post, thanks for writing it up!</p></div>
+</body></html>`
+	got, err := convertHTML("blog.html", []byte(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(got, "# Blog\n\nFirst paragraph") || strings.Contains(got, "Nice post") || strings.Contains(got, "Home") {
+		t.Errorf("convertHTML =\n%s", got)
+	}
+}
+
+func TestConvertDOCX(t *testing.T) {
+	got, err := convertDOCX("report.docx", zipOf(t, "word/document.xml", testDocx))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Report\n\n## Summary\n\nSales rose 12%.\n\n- First point\n\n"
+	if got != want {
+		t.Errorf("convertDOCX = %q, want %q", got, want)
+	}
+	if _, err := convertDOCX("x.docx", zipOf(t, "other.xml", "")); err == nil {
+		t.Error("convertDOCX without word/document.xml succeeded")
+	}
+}
+
+func TestConvertEPUB(t *testing.T) {
+	book := zipOf(t,
+		"mimetype", "application/epub+zip",
+		"META-INF/container.xml", `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf", `<package xmlns:dc="http://purl.org/dc/elements/1.1/"><metadata><dc:title>A Book</dc:title></metadata>
+<manifest><item id="c2" href="text/two.xhtml" media-type="application/xhtml+xml"/>
+<item id="c1" href="text/one.xhtml" media-type="application/xhtml+xml"/>
+<item id="css" href="style.css" media-type="text/css"/></manifest>
+<spine><itemref idref="c1"/><itemref idref="css"/><itemref idref="c2"/></spine></package>`,
+		"OEBPS/text/one.xhtml", `<html><head><title>One</title></head><body><h1>Chapter 1</h1><p>It begins.</p></body></html>`,
+		"OEBPS/text/two.xhtml", `<html><body><h1>Chapter 2</h1><p>It ends.</p></body></html>`,
+	)
+	got, err := convertEPUB("book.epub", book)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# A Book\n\n# Chapter 1\n\nIt begins.\n\n# Chapter 2\n\nIt ends.\n\n"
+	if got != want {
+		t.Errorf("convertEPUB = %q, want %q", got, want)
+	}
+}
+
+func TestConvertJupyter(t *testing.T) {
+	nb := `{"metadata": {"language_info": {"name": "python"}}, "cells": [
+		{"cell_type": "markdown", "source": ["# Analysis\n", "Load the data."]},
+		{"cell_type": "code", "source": "print(1 + 1)", "outputs": [{"output_type": "stream", "text": ["2\n"]}]},
+		{"cell_type": "code", "source": ["df.shape"], "outputs": [{"output_type": "execute_result", "data": {"text/plain": ["(3, 2)"], "image/png": "..."}}]},
+		{"cell_type": "code", "source": [], "outputs": []}
+	]}`
+	got, err := convertJupyter("a.ipynb", []byte(nb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Analysis\nLoad the data.\n\n" +
+		"```python\nprint(1 + 1)\n```\n\nOutput:\n\n```text\n2\n```\n\n" +
+		"```python\ndf.shape\n```\n\nOutput:\n\n```text\n(3, 2)\n```\n\n"
+	if got != want {
+		t.Errorf("convertJupyter =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestConvertCodeAndTree(t *testing.T) {
+	got, err := CodeSection("cmd/main.go", []byte("// ```quoted```\npackage main\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "## cmd/main.go\n\n````go\n// ```quoted```\npackage main\n````\n"; got != want {
+		t.Errorf("CodeSection = %q, want %q", got, want)
+	}
+
+	root := filepath.Join(t.TempDir(), "proj")
+	for name, content := range map[string]string{
+		"main.go":               "package main\n",
+		"lib/util.py":           "def f(): pass\n",
+		"README.md":             "# not code\n",
+		".git/config.go":        "hidden\n",
+		"node_modules/x/a.js":   "dependency\n",
+		"lib/.hidden.py":        "hidden\n",
+		"lib/nested/helper.rs":  "fn main() {}\n",
+		"lib/nested/image.png":  "\x89PNG",
+		"lib/nested/binary.go":  "\xff\xfe",
+		"vendor/pkg/vendored.c": "int x;\n",
+	} {
+		p := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err = ConvertTree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# proj\n\n" +
+		"## lib/nested/helper.rs\n\n```rust\nfn main() {}\n```\n\n" +
+		"## lib/util.py\n\n```python\ndef f(): pass\n```\n\n" +
+		"## main.go\n\n```go\npackage main\n```\n\n"
+	if got != want {
+		t.Errorf("ConvertTree =\n%s\nwant\n%s", got, want)
+	}
+	docs := t.TempDir()
+	if err := os.WriteFile(filepath.Join(docs, "README.md"), []byte("# docs\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ConvertTree(docs); err == nil {
+		t.Error("ConvertTree of a directory without code succeeded")
+	}
+}
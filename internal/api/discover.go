@@ -0,0 +1,242 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/nlm/gen/method"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/rpc"
+)
+
+// DiscoveredSource is a web page or video that DiscoverSources suggests
+// adding to a notebook.
+type DiscoveredSource struct {
+	Title       string        `json:"title"`
+	URL         string        `json:"url"`
+	Description string        `json:"description,omitempty"`
+	Type        pb.SourceType `json:"type"`
+}
+
+// DiscoverSources asks NotebookLM for sources on query that could be added
+// to projectID. The results are in the order the server ranks them.
+func (c *Client) DiscoverSources(projectID, query string) ([]DiscoveredSource, error) {
+	resp, err := c.rpc.Do(rpc.Call{
+		ID:         rpc.RPCDiscoverSources,
+		NotebookID: projectID,
+		Args: method.EncodeDiscoverSourcesArgs(&pb.DiscoverSourcesRequest{
+			ProjectId: projectID,
+			Query:     query,
+		}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discover sources: %w", err)
+	}
+	found, err := decodeDiscoveredSources(resp)
+	if err != nil {
+		return nil, fmt.Errorf("discover sources: %w", err)
+	}
+	c.log().Debug("discovered sources", "project", projectID, "count", len(found))
+	return found, nil
+}
+
+// decodeDiscoveredSources extracts the suggestions from a DiscoverSources
+// response. The layout is not documented, so every array holding a URL
+// among its elements is taken to be one suggestion: the first other
+// string is its title and the next one its description.
+func decodeDiscoveredSources(resp json.RawMessage) ([]DiscoveredSource, error) {
+	var data interface{}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	if s, ok := data.(string); ok {
+		if err := json.Unmarshal([]byte(s), &data); err != nil {
+			return nil, fmt.Errorf("parse response: %w", err)
+		}
+	}
+
+	var found []DiscoveredSource
+	seen := map[string]bool{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		list, ok := v.([]interface{})
+		if !ok {
+			return
+		}
+		var d DiscoveredSource
+		var texts []string
+		for _, e := range list {
+			s, ok := e.(string)
+			if !ok {
+				continue
+			}
+			if d.URL == "" && isWebURL(s) {
+				d.URL = s
+			} else if s = strings.TrimSpace(s); s != "" && !isWebURL(s) {
+				texts = append(texts, s)
+			}
+		}
+		if d.URL == "" {
+			for _, e := range list {
+				walk(e)
+			}
+			return
+		}
+		if seen[d.URL] {
+			return
+		}
+		seen[d.URL] = true
+		if len(texts) > 0 {
+			d.Title = texts[0]
+		}
+		if len(texts) > 1 {
+			d.Description = texts[1]
+		}
+		d.Type = urlSourceType(d.URL)
+		found = append(found, d)
+	}
+	walk(data)
+	return found, nil
+}
+
+func isWebURL(s string) bool {
+	return strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "http://")
+}
+
+// urlSourceType returns the type of source NotebookLM creates for url.
+func urlSourceType(url string) pb.SourceType {
+	if isYouTubeURL(url) {
+		return pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO
+	}
+	return pb.SourceType_SOURCE_TYPE_WEB_PAGE
+}
+
+// AddURLSources adds web pages and YouTube videos to projectID in a single
+// AddSources call and returns the IDs of the new sources, as far as the
+// response reports them.
+func (c *Client) AddURLSources(projectID string, urls []string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	var entries []interface{}
+	for _, u := range urls {
+		if isYouTubeURL(u) {
+			videoID, err := extractYouTubeVideoID(u)
+			if err != nil {
+				return nil, fmt.Errorf("invalid YouTube URL %s: %w", u, err)
+			}
+			entries = append(entries, []interface{}{nil, nil, videoID, nil, pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO})
+			continue
+		}
+		entries = append(entries, []interface{}{nil, nil, []string{u}})
+	}
+	resp, err := c.rpc.Do(rpc.Call{
+		ID:         rpc.RPCAddSources,
+		NotebookID: projectID,
+		Args:       []interface{}{entries, projectID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("add sources: %w", err)
+	}
+	return extractSourceIDs(resp), nil
+}
+
+// extractSourceIDs returns the IDs in an AddSources response, which lists
+// the new sources as [[[id], title, ...], ...].
+func extractSourceIDs(resp json.RawMessage) []string {
+	var data []interface{}
+	if err := json.Unmarshal(resp, &data); err != nil || len(data) == 0 {
+		return nil
+	}
+	var ids []string
+	if list, ok := data[0].([]interface{}); ok {
+		for _, e := range list {
+			src, ok := e.([]interface{})
+			if !ok || len(src) == 0 {
+				continue
+			}
+			if idList, ok := src[0].([]interface{}); ok && len(idList) > 0 {
+				if id, ok := idList[0].(string); ok {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	if len(ids) == 0 {
+		if id, err := extractSourceID(resp); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SourceURLs maps the URLs of projectID's web and video sources to their
+// source IDs. The typed Source message has no URL field, so the URLs are
+// read from the raw GetProject response: every URL found inside a source
+// entry is attributed to it.
+func (c *Client) SourceURLs(projectID string) (map[string]string, error) {
+	resp, err := c.rpc.Do(rpc.Call{
+		ID:         rpc.RPCGetProject,
+		NotebookID: projectID,
+		Args:       method.EncodeGetProjectArgs(&pb.GetProjectRequest{ProjectId: projectID}),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get source URLs: %w", err)
+	}
+	urls, err := decodeSourceURLs(resp)
+	if err != nil {
+		return nil, fmt.Errorf("get source URLs: %w", err)
+	}
+	return urls, nil
+}
+
+// decodeSourceURLs reads the source list of a GetProject response, which
+// is [[title, [source, ...], project-id, ...]] with each source starting
+// with [id].
+func decodeSourceURLs(resp json.RawMessage) (map[string]string, error) {
+	var data []interface{}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		return nil, fmt.Errorf("parse response: %w", err)
+	}
+	project := data
+	if len(data) > 0 {
+		if inner, ok := data[0].([]interface{}); ok {
+			project = inner
+		}
+	}
+	urls := map[string]string{}
+	if len(project) < 2 {
+		return urls, nil
+	}
+	sources, _ := project[1].([]interface{})
+	for _, s := range sources {
+		src, ok := s.([]interface{})
+		if !ok || len(src) == 0 {
+			continue
+		}
+		idList, ok := src[0].([]interface{})
+		if !ok || len(idList) == 0 {
+			continue
+		}
+		id, _ := idList[0].(string)
+		if id == "" {
+			continue
+		}
+		var walk func(v interface{})
+		walk = func(v interface{}) {
+			switch v := v.(type) {
+			case string:
+				if isWebURL(v) {
+					urls[v] = id
+				}
+			case []interface{}:
+				for _, e := range v {
+					walk(e)
+				}
+			}
+		}
+		walk(src[1:])
+	}
+	return urls, nil
+}
@@ -0,0 +1,64 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+func TestDecodeDiscoveredSources(t *testing.T) {
+	resp := `[[
+		[null, "Go Memory Model", "https://go.dev/ref/mem", "How goroutines share memory."],
+		[[null, ["Understanding Channels", null, "https://www.youtube.com/watch?v=abc123"]]],
+		[null, "Duplicate", "https://go.dev/ref/mem"],
+		[null, "no url here"]
+	]]`
+	got, err := decodeDiscoveredSources([]byte(resp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiscoveredSource{
+		{Title: "Go Memory Model", URL: "https://go.dev/ref/mem", Description: "How goroutines share memory.", Type: pb.SourceType_SOURCE_TYPE_WEB_PAGE},
+		{Title: "Understanding Channels", URL: "https://www.youtube.com/watch?v=abc123", Type: pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeDiscoveredSources =\n%+v\nwant\n%+v", got, want)
+	}
+
+	if got, err := decodeDiscoveredSources([]byte(`"[[null, \"T\", \"http://example.com\"]]"`)); err != nil || len(got) != 1 {
+		t.Errorf("string-wrapped response = %+v, %v", got, err)
+	}
+	if _, err := decodeDiscoveredSources([]byte(`oops`)); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestExtractSourceIDs(t *testing.T) {
+	got := extractSourceIDs([]byte(`[[[["src-1"], "One"], [["src-2"], "Two"]]]`))
+	if !reflect.DeepEqual(got, []string{"src-1", "src-2"}) {
+		t.Errorf("extractSourceIDs = %v", got)
+	}
+	if got := extractSourceIDs([]byte(`[["src-9"]]`)); !reflect.DeepEqual(got, []string{"src-9"}) {
+		t.Errorf("extractSourceIDs fallback = %v", got)
+	}
+}
+
+func TestDecodeSourceURLs(t *testing.T) {
+	resp := `[["Notebook", [
+		[["src-1"], "Go Memory Model", [null, 12, [1700000000], null, 5, null, null, ["https://go.dev/ref/mem"]]],
+		[["src-2"], "Notes", [null, 3]],
+		[["src-3"], "Video", [null, 1, null, null, 9, ["https://www.youtube.com/watch?v=abc123", "abc123"]]]
+	], "nb-1"]]`
+	got, err := decodeSourceURLs([]byte(resp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"https://go.dev/ref/mem":                 "src-1",
+		"https://www.youtube.com/watch?v=abc123": "src-3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeSourceURLs = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5" //nolint:gosec // md5 is only used to check x-goog-hash
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DownloadOptions controls media downloads.
+type DownloadOptions struct {
+	ExpectedSize   int64  // If positive, the final file must have this size
+	ExpectedSHA256 string // If set, the final file must have this hex SHA-256
+
+	// Progress, if set, is called as data is written.
+	Progress func(DownloadProgress)
+
+	// HTTPClient is used for URL downloads; defaults to a client without
+	// an overall timeout so large files can finish.
+	HTTPClient *http.Client
+}
+
+// DownloadProgress reports how much of a download has been written.
+// Total is zero when the size is unknown.
+type DownloadProgress struct {
+	Written int64
+	Total   int64
+}
+
+// DownloadResult describes a completed, verified download.
+type DownloadResult struct {
+	Path        string // Final path, including any extension added from the content type
+	Size        int64
+	SHA256      string
+	ContentType string
+	Resumed     bool // Continued from an earlier partial download
+}
+
+// ErrIntegrity is returned when a downloaded file fails size or hash checks.
+var ErrIntegrity = errors.New("download integrity check failed")
+
+// partSuffix is appended to a download's path while it is incomplete.
+const partSuffix = ".part"
+
+// DownloadMedia streams an authenticated media URL to path. An existing
+// path+".part" from an interrupted download is resumed with a Range
+// request. If path has no extension, one is chosen from the content.
+func (c *Client) DownloadMedia(mediaURL, path string, opts DownloadOptions) (*DownloadResult, error) {
+	header := http.Header{}
+	header.Set("Accept", "*/*")
+	header.Set("Accept-Language", "en-US,en;q=0.6")
+	header.Set("Referer", "https://notebooklm.google.com/")
+	header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/140.0.0.0 Safari/537.36")
+	cookies := os.Getenv("NLM_COOKIES")
+	if c.rpc != nil && c.rpc.Config.Cookies != "" {
+		cookies = c.rpc.Config.Cookies
+	}
+	if cookies != "" {
+		header.Set("Cookie", cookies)
+	}
+	if os.Getenv("NLM_AUTH_TOKEN") != "" && !strings.Contains(mediaURL, "authuser=") {
+		sep := "?"
+		if strings.Contains(mediaURL, "?") {
+			sep = "&"
+		}
+		mediaURL += sep + "authuser=0"
+	}
+	c.log().Debug("downloading media", "url", mediaURL, "path", path, "cookies", cookies != "")
+	return downloadURL(context.Background(), mediaURL, header, path, opts)
+}
+
+// SaveAudio writes an audio overview to path, downloading it if AudioData
+// is a URL and decoding it as it is written otherwise.
+func (c *Client) SaveAudio(r *AudioOverviewResult, path string, opts DownloadOptions) (*DownloadResult, error) {
+	if r.AudioData == "" {
+		return nil, fmt.Errorf("no audio data to save")
+	}
+	if isHTTPURL(r.AudioData) {
+		return c.DownloadMedia(r.AudioData, path, opts)
+	}
+	return writeMedia(base64.NewDecoder(base64.StdEncoding, strings.NewReader(r.AudioData)), "", path, opts)
+}
+
+// SaveVideo writes a video overview to path, downloading it if VideoData
+// is a URL and decoding it as it is written otherwise.
+func (c *Client) SaveVideo(r *VideoOverviewResult, path string, opts DownloadOptions) (*DownloadResult, error) {
+	if r.VideoData == "" {
+		return nil, fmt.Errorf("no video data to save")
+	}
+	if isHTTPURL(r.VideoData) {
+		return c.DownloadMedia(r.VideoData, path, opts)
+	}
+	return writeMedia(base64.NewDecoder(base64.StdEncoding, strings.NewReader(r.VideoData)), "", path, opts)
+}
+
+func isHTTPURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// downloadURL fetches mediaURL into path+".part", resuming from its current
+// size, then verifies and renames it.
+func downloadURL(ctx context.Context, mediaURL string, header http.Header, path string, opts DownloadOptions) (*DownloadResult, error) {
+	hc := opts.HTTPClient
+	if hc == nil {
+		hc = &http.Client{}
+	}
+	part := path + partSuffix
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create download request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	var total int64
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, size, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return nil, fmt.Errorf("download: unexpected Content-Range %q for offset %d", resp.Header.Get("Content-Range"), offset)
+		}
+		total = size
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// The server ignored the Range header; start over.
+		offset = 0
+		if resp.ContentLength > 0 {
+			total = resp.ContentLength
+		}
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		if offset == 0 {
+			return nil, fmt.Errorf("download failed with status: %s", resp.Status)
+		}
+		// The partial file already holds everything.
+		_, size, _ := parseContentRange(resp.Header.Get("Content-Range"))
+		return finishDownload(part, path, "", offset > 0, expectSize(opts, size), "", opts)
+	default:
+		return nil, fmt.Errorf("download failed with status: %s (check authentication)", resp.Status)
+	}
+
+	//nolint:gosec // user-requested output file should be readable
+	f, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create download file: %w", err)
+	}
+	w := &progressWriter{w: f, written: offset, total: total, progress: opts.Progress}
+	_, copyErr := io.Copy(w, resp.Body)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		// Keep the partial file so the next attempt can resume.
+		return nil, fmt.Errorf("download interrupted after %d bytes (rerun to resume): %w", w.written, copyErr)
+	}
+	return finishDownload(part, path, resp.Header.Get("Content-Type"), offset > 0,
+		expectSize(opts, total), googMD5(resp.Header), opts)
+}
+
+// writeMedia streams r to path, verifying and naming it like a download.
+func writeMedia(r io.Reader, contentType, path string, opts DownloadOptions) (*DownloadResult, error) {
+	part := path + partSuffix
+	//nolint:gosec // user-requested output file should be readable
+	f, err := os.OpenFile(part, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create media file: %w", err)
+	}
+	w := &progressWriter{w: f, progress: opts.Progress}
+	_, copyErr := io.Copy(w, r)
+	if err := f.Close(); err != nil && copyErr == nil {
+		copyErr = err
+	}
+	if copyErr != nil {
+		_ = os.Remove(part)
+		return nil, fmt.Errorf("write media file: %w", copyErr)
+	}
+	return finishDownload(part, path, contentType, false, opts.ExpectedSize, "", opts)
+}
+
+// finishDownload hashes and checks the completed part file, then renames
+// it into place. Files that fail verification are removed so they are not
+// resumed.
+func finishDownload(part, path, contentType string, resumed bool, wantSize int64, wantMD5 string, opts DownloadOptions) (*DownloadResult, error) {
+	f, err := os.Open(part)
+	if err != nil {
+		return nil, fmt.Errorf("open download: %w", err)
+	}
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	head = head[:n]
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("read download: %w", err)
+	}
+	sh, mh := sha256.New(), md5.New() //nolint:gosec // see import
+	size, err := io.Copy(io.MultiWriter(sh, mh), f)
+	_ = f.Close()
+	if err != nil {
+		return nil, fmt.Errorf("hash download: %w", err)
+	}
+	sum := hex.EncodeToString(sh.Sum(nil))
+
+	var problem string
+	switch {
+	case wantSize > 0 && size != wantSize:
+		problem = fmt.Sprintf("size %d, want %d", size, wantSize)
+	case opts.ExpectedSHA256 != "" && !strings.EqualFold(sum, opts.ExpectedSHA256):
+		problem = fmt.Sprintf("sha256 %s, want %s", sum, opts.ExpectedSHA256)
+	case wantMD5 != "" && base64.StdEncoding.EncodeToString(mh.Sum(nil)) != wantMD5:
+		problem = "md5 does not match x-goog-hash"
+	}
+	if problem != "" {
+		_ = os.Remove(part)
+		return nil, fmt.Errorf("%w: %s", ErrIntegrity, problem)
+	}
+
+	if ct, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = ct
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = sniffMediaType(head)
+	}
+	if filepath.Ext(path) == "" {
+		path += MediaExtension(contentType, head)
+	}
+	if err := os.Rename(part, path); err != nil {
+		return nil, fmt.Errorf("save download: %w", err)
+	}
+	return &DownloadResult{Path: path, Size: size, SHA256: sum, ContentType: contentType, Resumed: resumed}, nil
+}
+
+func expectSize(opts DownloadOptions, serverSize int64) int64 {
+	if opts.ExpectedSize > 0 {
+		return opts.ExpectedSize
+	}
+	return serverSize
+}
+
+// MediaExtension chooses a file extension for media with the given content
+// type and leading bytes. The bytes win over the header, since media URLs
+// are often served as application/octet-stream.
+func MediaExtension(contentType string, head []byte) string {
+	switch {
+	case len(head) >= 12 && string(head[4:8]) == "ftyp":
+		brand := string(head[8:12])
+		if strings.HasPrefix(brand, "M4A") || strings.HasPrefix(brand, "M4B") || strings.HasPrefix(contentType, "audio/") {
+			return ".m4a"
+		}
+		return ".mp4"
+	case bytes.HasPrefix(head, []byte("ID3")), len(head) >= 2 && head[0] == 0xFF && head[1]&0xE0 == 0xE0:
+		return ".mp3"
+	case len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "WAVE":
+		return ".wav"
+	case bytes.HasPrefix(head, []byte("OggS")):
+		return ".ogg"
+	case bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return ".webm"
+	}
+	switch contentType {
+	case "audio/mpeg", "audio/mp3":
+		return ".mp3"
+	case "audio/mp4", "audio/x-m4a", "audio/aac":
+		return ".m4a"
+	case "video/mp4":
+		return ".mp4"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return ".wav"
+	case "audio/ogg":
+		return ".ogg"
+	case "video/webm", "audio/webm":
+		return ".webm"
+	}
+	return ".bin"
+}
+
+// sniffMediaType guesses a content type from leading bytes.
+func sniffMediaType(head []byte) string {
+	switch MediaExtension("", head) {
+	case ".m4a":
+		return "audio/mp4"
+	case ".mp4":
+		return "video/mp4"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".wav":
+		return "audio/wav"
+	case ".ogg":
+		return "audio/ogg"
+	case ".webm":
+		return "video/webm"
+	}
+	return http.DetectContentType(head)
+}
+
+// parseContentRange parses "bytes start-end/size". size is zero if unknown.
+func parseContentRange(s string) (start, size int64, ok bool) {
+	s, found := strings.CutPrefix(s, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	rng, total, found := strings.Cut(s, "/")
+	if !found {
+		return 0, 0, false
+	}
+	if total != "*" {
+		size, _ = strconv.ParseInt(total, 10, 64)
+	}
+	if first, _, found := strings.Cut(rng, "-"); found {
+		start, err := strconv.ParseInt(first, 10, 64)
+		return start, size, err == nil
+	}
+	// "bytes */size" as sent with 416 responses.
+	return 0, size, rng == "*"
+}
+
+// googMD5 returns the base64 MD5 from an x-goog-hash header, if any.
+func googMD5(h http.Header) string {
+	for _, v := range h.Values("X-Goog-Hash") {
+		for _, part := range strings.Split(v, ",") {
+			if sum, ok := strings.CutPrefix(strings.TrimSpace(part), "md5="); ok {
+				return sum
+			}
+		}
+	}
+	return ""
+}
+
+// progressWriter counts bytes written and reports them.
+type progressWriter struct {
+	w        io.Writer
+	written  int64
+	total    int64
+	progress func(DownloadProgress)
+}
+
+func (p *progressWriter) Write(b []byte) (int, error) {
+	n, err := p.w.Write(b)
+	p.written += int64(n)
+	if p.progress != nil {
+		p.progress(DownloadProgress{Written: p.written, Total: p.total})
+	}
+	return n, err
+}
@@ -0,0 +1,198 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// m4aData is a fake audio file with an M4A ftyp header.
+var m4aData = append([]byte("\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00"), bytes.Repeat([]byte("audio"), 1000)...)
+
+func serveMedia(t *testing.T, data []byte, contentType string, modify func(http.ResponseWriter)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		if modify != nil {
+			modify(w)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadURLChoosesExtension(t *testing.T) {
+	srv := serveMedia(t, m4aData, "application/octet-stream", nil)
+	path := filepath.Join(t.TempDir(), "overview")
+
+	var last DownloadProgress
+	res, err := downloadURL(context.Background(), srv.URL, nil, path, DownloadOptions{
+		Progress: func(p DownloadProgress) { last = p },
+	})
+	if err != nil {
+		t.Fatalf("downloadURL: %v", err)
+	}
+	if res.Path != path+".m4a" {
+		t.Errorf("path = %s, want %s.m4a", res.Path, path)
+	}
+	if res.Size != int64(len(m4aData)) || res.SHA256 != sha256Hex(m4aData) || res.Resumed {
+		t.Errorf("result = %+v", res)
+	}
+	if last.Written != int64(len(m4aData)) || last.Total != int64(len(m4aData)) {
+		t.Errorf("last progress = %+v", last)
+	}
+	if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+		t.Errorf("part file left behind: %v", err)
+	}
+}
+
+func TestDownloadURLResumes(t *testing.T) {
+	srv := serveMedia(t, m4aData, "audio/mp4", nil)
+	path := filepath.Join(t.TempDir(), "overview.m4a")
+	if err := os.WriteFile(path+partSuffix, m4aData[:1234], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := downloadURL(context.Background(), srv.URL, nil, path, DownloadOptions{ExpectedSHA256: sha256Hex(m4aData)})
+	if err != nil {
+		t.Fatalf("downloadURL: %v", err)
+	}
+	if !res.Resumed || res.Path != path {
+		t.Errorf("result = %+v, want resumed into %s", res, path)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, m4aData) {
+		t.Error("resumed file differs from source")
+	}
+}
+
+func TestDownloadURLRestartsWhenRangeIgnored(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(m4aData)
+	}))
+	defer srv.Close()
+	path := filepath.Join(t.TempDir(), "overview.m4a")
+	if err := os.WriteFile(path+partSuffix, []byte("stale partial data"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := downloadURL(context.Background(), srv.URL, nil, path, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("downloadURL: %v", err)
+	}
+	if res.Resumed || res.SHA256 != sha256Hex(m4aData) {
+		t.Errorf("result = %+v, want fresh download", res)
+	}
+}
+
+func TestDownloadURLIntegrity(t *testing.T) {
+	srv := serveMedia(t, m4aData, "audio/mp4", func(w http.ResponseWriter) {
+		w.Header().Set("X-Goog-Hash", "crc32c=AAAAAA==,md5=AAAAAAAAAAAAAAAAAAAAAA==")
+	})
+	path := filepath.Join(t.TempDir(), "overview.m4a")
+
+	_, err := downloadURL(context.Background(), srv.URL, nil, path, DownloadOptions{})
+	if !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("downloadURL error = %v, want ErrIntegrity", err)
+	}
+	for _, p := range []string{path, path + partSuffix} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s exists after failed verification", p)
+		}
+	}
+
+	_, err = downloadURL(context.Background(), serveMedia(t, m4aData, "", nil).URL, nil, path, DownloadOptions{ExpectedSize: 10})
+	if !errors.Is(err, ErrIntegrity) {
+		t.Fatalf("downloadURL with wrong size error = %v, want ErrIntegrity", err)
+	}
+}
+
+func TestDownloadURLHTTPError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+	_, err := downloadURL(context.Background(), srv.URL, nil, filepath.Join(t.TempDir(), "x"), DownloadOptions{})
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("downloadURL error = %v, want 403", err)
+	}
+}
+
+func TestSaveAudioDecodesBase64(t *testing.T) {
+	c := &Client{}
+	path := filepath.Join(t.TempDir(), "audio")
+	mp3 := append([]byte("ID3\x03"), bytes.Repeat([]byte{0}, 64)...)
+	res, err := c.SaveAudio(&AudioOverviewResult{AudioData: base64.StdEncoding.EncodeToString(mp3)}, path, DownloadOptions{})
+	if err != nil {
+		t.Fatalf("SaveAudio: %v", err)
+	}
+	if res.Path != path+".mp3" || res.ContentType != "audio/mpeg" {
+		t.Errorf("result = %+v, want mp3", res)
+	}
+	if res.SHA256 != sha256Hex(mp3) {
+		t.Errorf("sha256 = %s, want %s", res.SHA256, sha256Hex(mp3))
+	}
+}
+
+func TestMediaExtension(t *testing.T) {
+	tests := []struct {
+		contentType string
+		head        string
+		want        string
+	}{
+		{"", "\x00\x00\x00\x20ftypM4A ", ".m4a"},
+		{"", "\x00\x00\x00\x20ftypisom", ".mp4"},
+		{"audio/mp4", "\x00\x00\x00\x20ftypisom", ".m4a"},
+		{"", "ID3\x03", ".mp3"},
+		{"", "\xff\xfb\x90", ".mp3"},
+		{"", "RIFF\x00\x00\x00\x00WAVE", ".wav"},
+		{"audio/mpeg", "", ".mp3"},
+		{"video/mp4", "", ".mp4"},
+		{"application/octet-stream", "", ".bin"},
+	}
+	for _, tt := range tests {
+		if got := MediaExtension(tt.contentType, []byte(tt.head)); got != tt.want {
+			t.Errorf("MediaExtension(%q, %q) = %s, want %s", tt.contentType, tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		in          string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 100-199/200", 100, 200, true},
+		{"bytes 0-99/*", 0, 0, true},
+		{"bytes */500", 0, 500, true},
+		{"items 0-1/2", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := parseContentRange(tt.in)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v; want %d, %d, %v", tt.in, start, size, ok, tt.start, tt.size, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"fmt"
+
+	"github.com/tmc/nlm/internal/drive"
+	"github.com/tmc/nlm/internal/rpc"
+)
+
+// AddDriveSource adds a Google Docs, Slides or Sheets file as a native
+// Drive source, which NotebookLM can later re-sync. The title may be empty;
+// NotebookLM then uses the file's name.
+func (c *Client) AddDriveSource(projectID string, f drive.File, title string) (string, error) {
+	c.log().Debug("adding Drive source", "project", projectID, "file", f.ID, "type", f.Type)
+	payload := []interface{}{
+		[]interface{}{driveSource(f, title)},
+		projectID,
+	}
+	resp, err := c.rpc.Do(rpc.Call{
+		ID:         rpc.RPCAddSources,
+		NotebookID: projectID,
+		Args:       payload,
+	})
+	if err != nil {
+		return "", fmt.Errorf("add Drive source: %w", err)
+	}
+	sourceID, err := extractSourceID(resp)
+	if err != nil {
+		return "", fmt.Errorf("extract source ID: %w", err)
+	}
+	return sourceID, nil
+}
+
+// driveSource returns the AddSources entry for a Drive file.
+func driveSource(f drive.File, title string) []interface{} {
+	var t interface{}
+	if title != "" {
+		t = title
+	}
+	// The web client sends Drive files in the first slot of the source
+	// entry, where text and URL sources use the second and third.
+	return []interface{}{
+		[]interface{}{f.ID, f.MIMEType(), 1, t},
+		nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		1,
+	}
+}
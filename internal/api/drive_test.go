@@ -0,0 +1,25 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/drive"
+)
+
+func TestDriveSource(t *testing.T) {
+	f := drive.File{ID: "abc", Type: pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES}
+	for title, want := range map[string]string{
+		"":     `[["abc","application/vnd.google-apps.presentation",1,null],null,null,null,null,null,null,null,null,null,1]`,
+		"Deck": `[["abc","application/vnd.google-apps.presentation",1,"Deck"],null,null,null,null,null,null,null,null,null,1]`,
+	} {
+		got, err := json.Marshal(driveSource(f, title))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Errorf("driveSource(%q) = %s, want %s", title, got, want)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+// ReportOptions describes the report to create with CreateReport.
+type ReportOptions struct {
+	// Title names the report, typically one of the strings returned by
+	// GenerateReportSuggestions.
+	Title string
+	// Prompt describes the report to write. It may be empty when Title
+	// alone says what is wanted.
+	Prompt string
+	// SourceIDs restricts the report to these sources; empty means all
+	// sources in the notebook.
+	SourceIDs []string
+}
+
+// newReportRequest builds the CreateArtifact request for a tailored report.
+// The title and prompt travel in the report the artifact will hold; the
+// selected sources go in the request context.
+func newReportRequest(projectID string, opts ReportOptions) *pb.CreateArtifactRequest {
+	title := strings.TrimSpace(opts.Title)
+	prompt := strings.TrimSpace(opts.Prompt)
+	if title == "" {
+		title, _, _ = strings.Cut(prompt, "\n")
+	}
+	return &pb.CreateArtifactRequest{
+		Context: &pb.Context{
+			ProjectId: projectID,
+			SourceIds: opts.SourceIDs,
+		},
+		ProjectId: projectID,
+		Artifact: &pb.Artifact{
+			ProjectId: projectID,
+			Type:      pb.ArtifactType_ARTIFACT_TYPE_REPORT,
+			State:     pb.ArtifactState_ARTIFACT_STATE_CREATING,
+			TailoredReport: &pb.Report{
+				Title:   title,
+				Content: prompt,
+			},
+		},
+	}
+}
+
+// CreateReport starts generating a tailored report in projectID. The
+// returned artifact is usually still CREATING; use WaitForArtifact to
+// block until it is ready.
+func (c *Client) CreateReport(projectID string, opts ReportOptions) (*pb.Artifact, error) {
+	if projectID == "" {
+		return nil, fmt.Errorf("create report: project ID required")
+	}
+	artifact, err := c.orchestrationService.CreateArtifact(context.Background(), newReportRequest(projectID, opts))
+	if err != nil {
+		return nil, fmt.Errorf("create report: %w", err)
+	}
+	return artifact, nil
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/tmc/nlm/gen/method"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+func TestNewReportRequest(t *testing.T) {
+	req := newReportRequest("nb1", ReportOptions{
+		Prompt:    "  Compare the pricing models\nFocus on enterprise tiers.  ",
+		SourceIDs: []string{"s1", "s2"},
+	})
+	if req.GetProjectId() != "nb1" || req.GetContext().GetProjectId() != "nb1" {
+		t.Errorf("project IDs = %q, %q", req.GetProjectId(), req.GetContext().GetProjectId())
+	}
+	if !reflect.DeepEqual(req.GetContext().GetSourceIds(), []string{"s1", "s2"}) {
+		t.Errorf("source IDs = %q", req.GetContext().GetSourceIds())
+	}
+	a := req.GetArtifact()
+	if a.GetType() != pb.ArtifactType_ARTIFACT_TYPE_REPORT || a.GetState() != pb.ArtifactState_ARTIFACT_STATE_CREATING {
+		t.Errorf("artifact = %v", a)
+	}
+	if r := a.GetTailoredReport(); r.GetTitle() != "Compare the pricing models" || r.GetContent() != "Compare the pricing models\nFocus on enterprise tiers." {
+		t.Errorf("report = %v", r)
+	}
+	// The sources, title and prompt must all reach the wire.
+	got, err := json.Marshal(method.EncodeCreateArtifactArgs(req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[["nb1",["s1","s2"]],"nb1",[null,"nb1",3,[],1,null,[],[],["Compare the pricing models","Compare the pricing models\nFocus on enterprise tiers.",[]],[]]]`
+	if string(got) != want {
+		t.Errorf("wire args = %s, want %s", got, want)
+	}
+
+	req = newReportRequest("nb1", ReportOptions{Title: "Briefing Doc"})
+	if r := req.GetArtifact().GetTailoredReport(); r.GetTitle() != "Briefing Doc" || r.GetContent() != "" {
+		t.Errorf("report from suggestion = %v", r)
+	}
+	if len(req.GetContext().GetSourceIds()) != 0 {
+		t.Errorf("source IDs = %q, want all sources", req.GetContext().GetSourceIds())
+	}
+}
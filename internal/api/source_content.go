@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/tmc/nlm/gen/method"
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"github.com/tmc/nlm/internal/beprotojson"
+	"github.com/tmc/nlm/internal/rpc"
+)
+
+// SourceContent is a source together with the text NotebookLM extracted
+// from it, such as the body of a PDF or the transcript of a video.
+type SourceContent struct {
+	Source *pb.Source
+	Text   string
+}
+
+// LoadSourceContent loads a source's metadata and its server-side
+// extracted text.
+func (c *Client) LoadSourceContent(sourceID string) (*SourceContent, error) {
+	req := &pb.LoadSourceRequest{
+		SourceId: sourceID,
+	}
+	resp, err := c.rpc.Do(rpc.Call{
+		ID:   rpc.RPCLoadSource,
+		Args: method.EncodeLoadSourceArgs(req),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("load source: %w", err)
+	}
+	sc, err := parseSourceContent(resp)
+	if err != nil {
+		return nil, fmt.Errorf("load source: %w", err)
+	}
+	if sc.Source.GetSourceId().GetSourceId() == "" {
+		sc.Source.SourceId = &pb.SourceId{SourceId: sourceID}
+	}
+	c.log().Debug("loaded source content", "source", sourceID, "chars", len(sc.Text))
+	return sc, nil
+}
+
+// parseSourceContent decodes a LoadSource response. The response is either
+// a bare source or an array whose first element is the source and whose
+// fourth element holds the extracted content blocks.
+func parseSourceContent(resp json.RawMessage) (*SourceContent, error) {
+	var data []interface{}
+	if err := json.Unmarshal(resp, &data); err != nil {
+		// Some responses arrive as a JSON string containing the array.
+		var s string
+		if err2 := json.Unmarshal(resp, &s); err2 != nil {
+			return nil, fmt.Errorf("parse response: %w", err)
+		}
+		if err := json.Unmarshal([]byte(s), &data); err != nil {
+			return nil, fmt.Errorf("parse response: %w", err)
+		}
+		resp = json.RawMessage(s)
+	}
+
+	raw := resp
+	if len(data) > 0 {
+		if inner, ok := data[0].([]interface{}); ok && len(inner) > 0 {
+			if _, ok := inner[0].([]interface{}); ok {
+				raw, _ = json.Marshal(inner)
+			}
+		}
+	}
+	src := &pb.Source{}
+	if err := beprotojson.Unmarshal(raw, src); err != nil {
+		// The metadata is best effort; the text is what callers are after.
+		src = &pb.Source{}
+	}
+
+	sc := &SourceContent{Source: src, Text: src.Content}
+	if sc.Text == "" && len(data) > 3 {
+		blocks := data[3]
+		if arr, ok := blocks.([]interface{}); ok && len(arr) > 0 {
+			if _, ok := arr[0].([]interface{}); ok {
+				blocks = arr[0]
+			}
+		}
+		var texts []string
+		collectText(blocks, &texts)
+		sc.Text = strings.Join(texts, "\n")
+	}
+	return sc, nil
+}
+
+// collectText appends every non-empty string in v, depth first.
+func collectText(v interface{}, out *[]string) {
+	switch v := v.(type) {
+	case string:
+		if strings.TrimSpace(v) != "" {
+			*out = append(*out, v)
+		}
+	case []interface{}:
+		for _, e := range v {
+			collectText(e, out)
+		}
+	}
+}
+
+// SourceIssueReasons decodes the warnings attached to a source.
+func SourceIssueReasons(src *pb.Source) []pb.SourceIssue_Reason {
+	var reasons []pb.SourceIssue_Reason
+	for _, w := range src.GetWarnings() {
+		reasons = append(reasons, pb.SourceIssue_Reason(w.GetValue()))
+	}
+	return reasons
+}
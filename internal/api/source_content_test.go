@@ -0,0 +1,73 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestParseSourceContent(t *testing.T) {
+	tests := []struct {
+		name      string
+		resp      string
+		wantID    string
+		wantTitle string
+		wantText  string
+	}{
+		{
+			name:      "wrapped with content blocks",
+			resp:      `[[["src1"],"Paper.pdf"],null,null,[[[0,10,[[[0,10,["First paragraph."]]]]],[10,20,[[[10,20,["Second paragraph."]]]]]]]]`,
+			wantID:    "src1",
+			wantTitle: "Paper.pdf",
+			wantText:  "First paragraph.\nSecond paragraph.",
+		},
+		{
+			name:      "bare source",
+			resp:      `[["src2"],"Notes"]`,
+			wantID:    "src2",
+			wantTitle: "Notes",
+		},
+		{
+			name:      "string encoded",
+			resp:      `"[[[\"src3\"],\"Talk\"],null,null,[[[0,5,[\"hello\"]]]]]"`,
+			wantID:    "src3",
+			wantTitle: "Talk",
+			wantText:  "hello",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc, err := parseSourceContent(json.RawMessage(tt.resp))
+			if err != nil {
+				t.Fatalf("parseSourceContent() error: %v", err)
+			}
+			if got := sc.Source.GetSourceId().GetSourceId(); got != tt.wantID {
+				t.Errorf("source id = %q, want %q", got, tt.wantID)
+			}
+			if got := sc.Source.GetTitle(); got != tt.wantTitle {
+				t.Errorf("title = %q, want %q", got, tt.wantTitle)
+			}
+			if sc.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", sc.Text, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestParseSourceContentInvalid(t *testing.T) {
+	if _, err := parseSourceContent(json.RawMessage(`{`)); err == nil {
+		t.Error("parseSourceContent({) succeeded, want error")
+	}
+}
+
+func TestSourceIssueReasons(t *testing.T) {
+	src := &pb.Source{Warnings: []*wrapperspb.Int32Value{
+		wrapperspb.Int32(int32(pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE)),
+	}}
+	got := SourceIssueReasons(src)
+	if len(got) != 1 || got[0] != pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE {
+		t.Errorf("SourceIssueReasons() = %v, want [REASON_YOUTUBE_ERROR_PRIVATE]", got)
+	}
+}
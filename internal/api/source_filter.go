@@ -0,0 +1,161 @@
+package api
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+// SourceFilter selects sources by title, type, status and age.
+// A zero SourceFilter matches every source.
+type SourceFilter struct {
+	Match     string                           // Glob matched against the source title (path.Match syntax)
+	Types     []pb.SourceType                  // Any of these types; empty means all
+	Statuses  []pb.SourceSettings_SourceStatus // Any of these statuses; empty means all
+	OlderThan time.Duration                    // Last modified at least this long ago; zero disables
+
+	// Now returns the reference time for OlderThan; defaults to time.Now.
+	Now func() time.Time
+}
+
+// IsZero reports whether the filter has no criteria.
+func (f SourceFilter) IsZero() bool {
+	return f.Match == "" && len(f.Types) == 0 && len(f.Statuses) == 0 && f.OlderThan == 0
+}
+
+// Matches reports whether src satisfies every criterion of the filter.
+func (f SourceFilter) Matches(src *pb.Source) bool {
+	if src == nil {
+		return false
+	}
+	if f.Match != "" {
+		ok, err := path.Match(f.Match, strings.TrimSpace(src.GetTitle()))
+		if err != nil || !ok {
+			return false
+		}
+	}
+	if len(f.Types) > 0 && !containsValue(f.Types, src.GetMetadata().GetSourceType()) {
+		return false
+	}
+	if len(f.Statuses) > 0 && !containsValue(f.Statuses, SourceStatus(src)) {
+		return false
+	}
+	if f.OlderThan > 0 {
+		modified, ok := SourceModifiedTime(src)
+		if !ok {
+			return false
+		}
+		now := time.Now
+		if f.Now != nil {
+			now = f.Now
+		}
+		if now().Sub(modified) < f.OlderThan {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the sources that match the filter, preserving order.
+func (f SourceFilter) Filter(sources []*pb.Source) []*pb.Source {
+	var matched []*pb.Source
+	for _, src := range sources {
+		if f.Matches(src) {
+			matched = append(matched, src)
+		}
+	}
+	return matched
+}
+
+// SourceStatus returns the effective status of a source, preferring the
+// settings over the metadata copy.
+func SourceStatus(src *pb.Source) pb.SourceSettings_SourceStatus {
+	if s := src.GetSettings().GetStatus(); s != pb.SourceSettings_SOURCE_STATUS_UNSPECIFIED {
+		return s
+	}
+	return src.GetMetadata().GetStatus()
+}
+
+// SourceModifiedTime returns when a source was last modified, if known.
+func SourceModifiedTime(src *pb.Source) (time.Time, bool) {
+	md := src.GetMetadata()
+	if ts := md.GetLastModifiedTime(); ts != nil {
+		return ts.AsTime(), true
+	}
+	if secs := md.GetLastUpdateTimeSeconds(); secs != nil {
+		return time.Unix(int64(secs.GetValue()), 0), true
+	}
+	return time.Time{}, false
+}
+
+// sourceTypeAliases maps common names to the source type NotebookLM
+// records. Uploaded files, PDFs included, are all LOCAL_FILE sources.
+var sourceTypeAliases = map[string]pb.SourceType{
+	"PDF":  pb.SourceType_SOURCE_TYPE_LOCAL_FILE,
+	"FILE": pb.SourceType_SOURCE_TYPE_LOCAL_FILE,
+}
+
+// ParseSourceType parses a source type name such as "YOUTUBE_VIDEO",
+// "youtube_video" or "SOURCE_TYPE_YOUTUBE_VIDEO". "PDF" and "FILE" are
+// accepted as aliases for LOCAL_FILE.
+func ParseSourceType(s string) (pb.SourceType, error) {
+	name := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(s), "-", "_"))
+	if t, ok := sourceTypeAliases[name]; ok {
+		return t, nil
+	}
+	if v, ok := pb.SourceType_value[name]; ok {
+		return pb.SourceType(v), nil
+	}
+	if v, ok := pb.SourceType_value["SOURCE_TYPE_"+name]; ok {
+		return pb.SourceType(v), nil
+	}
+	return 0, fmt.Errorf("unknown source type %q", s)
+}
+
+// ParseSourceStatus parses a source status name such as "ERROR" or
+// "SOURCE_STATUS_DISABLED".
+func ParseSourceStatus(s string) (pb.SourceSettings_SourceStatus, error) {
+	name := strings.ToUpper(strings.TrimSpace(s))
+	if v, ok := pb.SourceSettings_SourceStatus_value[name]; ok {
+		return pb.SourceSettings_SourceStatus(v), nil
+	}
+	if v, ok := pb.SourceSettings_SourceStatus_value["SOURCE_STATUS_"+name]; ok {
+		return pb.SourceSettings_SourceStatus(v), nil
+	}
+	return 0, fmt.Errorf("unknown source status %q", s)
+}
+
+// ParseAge parses a duration that may use day and week units in addition to
+// the units accepted by time.ParseDuration, e.g. "30d", "2w" or "36h".
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty age")
+	}
+	unit := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}[s[len(s)-1]]
+	if unit == 0 {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid age %q: %w", s, err)
+		}
+		return d, nil
+	}
+	n, err := strconv.ParseFloat(s[:len(s)-1], 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return time.Duration(n * float64(unit)), nil
+}
+
+func containsValue[T comparable](values []T, v T) bool {
+	for _, x := range values {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func testSource(id, title string, typ pb.SourceType, status pb.SourceSettings_SourceStatus, modified time.Time) *pb.Source {
+	return &pb.Source{
+		SourceId: &pb.SourceId{SourceId: id},
+		Title:    title,
+		Metadata: &pb.SourceMetadata{
+			SourceType:       typ,
+			Status:           status,
+			LastModifiedTime: timestamppb.New(modified),
+		},
+	}
+}
+
+func TestSourceFilterMatches(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := testSource("a", "draft notes", pb.SourceType_SOURCE_TYPE_TEXT, pb.SourceSettings_SOURCE_STATUS_ERROR, now.AddDate(0, 0, -40))
+	video := testSource("b", "Talk", pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO, pb.SourceSettings_SOURCE_STATUS_ENABLED, now.AddDate(0, 0, -1))
+
+	tests := []struct {
+		name   string
+		filter SourceFilter
+		want   []string
+	}{
+		{"zero matches all", SourceFilter{}, []string{"a", "b"}},
+		{"glob", SourceFilter{Match: "draft*"}, []string{"a"}},
+		{"type", SourceFilter{Types: []pb.SourceType{pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO}}, []string{"b"}},
+		{"status", SourceFilter{Statuses: []pb.SourceSettings_SourceStatus{pb.SourceSettings_SOURCE_STATUS_ERROR}}, []string{"a"}},
+		{"older than", SourceFilter{OlderThan: 30 * 24 * time.Hour}, []string{"a"}},
+		{"combined", SourceFilter{Match: "draft*", Types: []pb.SourceType{pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Now = func() time.Time { return now }
+			var got []string
+			for _, src := range tt.filter.Filter([]*pb.Source{old, video}) {
+				got = append(got, src.GetSourceId().GetSourceId())
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("Filter() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Filter() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSourceStatusPrefersSettings(t *testing.T) {
+	src := &pb.Source{
+		Settings: &pb.SourceSettings{Status: pb.SourceSettings_SOURCE_STATUS_DISABLED},
+		Metadata: &pb.SourceMetadata{Status: pb.SourceSettings_SOURCE_STATUS_ENABLED},
+	}
+	if got := SourceStatus(src); got != pb.SourceSettings_SOURCE_STATUS_DISABLED {
+		t.Errorf("SourceStatus() = %v, want DISABLED", got)
+	}
+}
+
+func TestParseSourceType(t *testing.T) {
+	for _, in := range []string{"YOUTUBE_VIDEO", "youtube-video", "SOURCE_TYPE_YOUTUBE_VIDEO"} {
+		got, err := ParseSourceType(in)
+		if err != nil || got != pb.SourceType_SOURCE_TYPE_YOUTUBE_VIDEO {
+			t.Errorf("ParseSourceType(%q) = %v, %v", in, got, err)
+		}
+	}
+	if got, err := ParseSourceType("pdf"); err != nil || got != pb.SourceType_SOURCE_TYPE_LOCAL_FILE {
+		t.Errorf("ParseSourceType(pdf) = %v, %v", got, err)
+	}
+	if _, err := ParseSourceType("podcast"); err == nil {
+		t.Error("ParseSourceType(podcast) succeeded, want error")
+	}
+}
+
+func TestParseSourceStatus(t *testing.T) {
+	got, err := ParseSourceStatus("error")
+	if err != nil || got != pb.SourceSettings_SOURCE_STATUS_ERROR {
+		t.Errorf("ParseSourceStatus(error) = %v, %v", got, err)
+	}
+	if _, err := ParseSourceStatus("broken"); err == nil {
+		t.Error("ParseSourceStatus(broken) succeeded, want error")
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"30d", 30 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+		{"36h", 36 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseAge(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseAge(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []string{"", "d", "-3d", "soon"} {
+		if _, err := ParseAge(in); err == nil {
+			t.Errorf("ParseAge(%q) succeeded, want error", in)
+		}
+	}
+}
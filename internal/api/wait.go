@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+// ErrWaitTimeout is returned when a Wait method gives up before the
+// operation completes.
+var ErrWaitTimeout = errors.New("timed out waiting for completion")
+
+// WaitOptions controls how the Wait methods poll. The zero value polls
+// every 5s at first, backing off to once a minute, for up to 20 minutes.
+type WaitOptions struct {
+	Timeout      time.Duration // Give up after this long; defaults to 20m
+	InitialDelay time.Duration // First interval between polls; defaults to 5s
+	MaxDelay     time.Duration // Backoff cap; defaults to 60s
+	Multiplier   float64       // Backoff factor; defaults to 1.5
+	MaxErrors    int           // Consecutive poll errors tolerated; defaults to 3
+
+	// Progress, if set, is called after every poll.
+	Progress func(WaitStatus)
+}
+
+// WaitStatus reports the outcome of one poll.
+type WaitStatus struct {
+	Attempt int
+	Elapsed time.Duration
+	State   string // Server-reported state, e.g. ARTIFACT_STATE_CREATING
+	Err     error  // Poll error that will be retried
+}
+
+func (o WaitOptions) withDefaults() WaitOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = 20 * time.Minute
+	}
+	if o.InitialDelay <= 0 {
+		o.InitialDelay = 5 * time.Second
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = time.Minute
+	}
+	if o.Multiplier < 1 {
+		o.Multiplier = 1.5
+	}
+	if o.MaxErrors <= 0 {
+		o.MaxErrors = 3
+	}
+	return o
+}
+
+// Poll calls check with exponential backoff until it reports done, returns
+// an error MaxErrors times in a row, or the timeout or ctx expires. The
+// timeout also bounds each call to check, even one that ignores ctx.
+func Poll(ctx context.Context, opts WaitOptions, check func(context.Context) (done bool, state string, err error)) error {
+	opts = opts.withDefaults()
+	ctx, cancel := context.WithTimeout(ctx, opts.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	delay := opts.InitialDelay
+	var errCount int
+	for attempt := 1; ; attempt++ {
+		done, state, err := runCheck(ctx, check)
+		if err != nil && ctx.Err() != nil {
+			return waitError(ctx, opts)
+		}
+		if err != nil {
+			errCount++
+			if errCount >= opts.MaxErrors {
+				return err
+			}
+		} else {
+			errCount = 0
+		}
+		if opts.Progress != nil {
+			opts.Progress(WaitStatus{Attempt: attempt, Elapsed: time.Since(start), State: state, Err: err})
+		}
+		if done && err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return waitError(ctx, opts)
+		case <-timer.C:
+		}
+		if delay = time.Duration(float64(delay) * opts.Multiplier); delay > opts.MaxDelay {
+			delay = opts.MaxDelay
+		}
+	}
+}
+
+// runCheck calls check, giving up when ctx is done. The Client methods
+// behind the Wait checks do not take a context, so a request that hangs
+// is abandoned to finish in the background rather than cancelled.
+func runCheck(ctx context.Context, check func(context.Context) (bool, string, error)) (bool, string, error) {
+	type result struct {
+		done  bool
+		state string
+		err   error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		done, state, err := check(ctx)
+		ch <- result{done, state, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.done, r.state, r.err
+	case <-ctx.Done():
+		return false, "", ctx.Err()
+	}
+}
+
+func waitError(ctx context.Context, opts WaitOptions) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %v", ErrWaitTimeout, opts.Timeout)
+	}
+	return ctx.Err()
+}
+
+// WaitForAudio polls until the notebook's audio overview is ready.
+func (c *Client) WaitForAudio(projectID string, opts WaitOptions) (*AudioOverviewResult, error) {
+	var result *AudioOverviewResult
+	err := Poll(context.Background(), opts, func(context.Context) (bool, string, error) {
+		r, err := c.GetAudioOverview(projectID)
+		if err != nil {
+			return false, "", err
+		}
+		result = r
+		if !r.IsReady {
+			return false, statusCreating, nil
+		}
+		return true, "READY", nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wait for audio overview: %w", err)
+	}
+	return result, nil
+}
+
+// WaitForVideo polls until the notebook's video overview is ready.
+func (c *Client) WaitForVideo(projectID string, opts WaitOptions) (*VideoOverviewResult, error) {
+	if !c.config.UseDirectRPC {
+		return nil, fmt.Errorf("wait for video overview: requires --direct-rpc flag")
+	}
+	var result *VideoOverviewResult
+	err := Poll(context.Background(), opts, func(context.Context) (bool, string, error) {
+		r, err := c.GetVideoOverview(projectID)
+		if err != nil {
+			return false, "", err
+		}
+		result = r
+		if !r.IsReady {
+			return false, statusCreating, nil
+		}
+		return true, "READY", nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wait for video overview: %w", err)
+	}
+	return result, nil
+}
+
+// WaitForArtifact polls until the artifact leaves the CREATING state. An
+// artifact that ends up FAILED is reported as an error.
+func (c *Client) WaitForArtifact(artifactID string, opts WaitOptions) (*pb.Artifact, error) {
+	var result *pb.Artifact
+	err := Poll(context.Background(), opts, func(context.Context) (bool, string, error) {
+		a, err := c.GetArtifact(artifactID)
+		if err != nil {
+			return false, "", err
+		}
+		result = a
+		switch a.GetState() {
+		case pb.ArtifactState_ARTIFACT_STATE_READY, pb.ArtifactState_ARTIFACT_STATE_FAILED:
+			return true, a.GetState().String(), nil
+		}
+		return false, a.GetState().String(), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wait for artifact: %w", err)
+	}
+	if result.GetState() == pb.ArtifactState_ARTIFACT_STATE_FAILED {
+		return result, fmt.Errorf("wait for artifact: artifact %s failed", artifactID)
+	}
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func fastWait() WaitOptions {
+	return WaitOptions{InitialDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond, Timeout: time.Second}
+}
+
+func TestPollUntilDone(t *testing.T) {
+	var statuses []WaitStatus
+	opts := fastWait()
+	opts.Progress = func(s WaitStatus) { statuses = append(statuses, s) }
+
+	calls := 0
+	err := Poll(context.Background(), opts, func(context.Context) (bool, string, error) {
+		calls++
+		return calls == 3, "CREATING", nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if calls != 3 || len(statuses) != 3 {
+		t.Errorf("calls = %d, progress reports = %d, want 3 and 3", calls, len(statuses))
+	}
+	if statuses[2].Attempt != 3 || statuses[2].State != "CREATING" {
+		t.Errorf("last status = %+v", statuses[2])
+	}
+}
+
+func TestPollToleratesTransientErrors(t *testing.T) {
+	calls := 0
+	err := Poll(context.Background(), fastWait(), func(context.Context) (bool, string, error) {
+		calls++
+		if calls%2 == 1 {
+			return false, "", errors.New("temporary")
+		}
+		return calls == 4, "", nil
+	})
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+}
+
+func TestPollGivesUpAfterMaxErrors(t *testing.T) {
+	errBoom := errors.New("boom")
+	calls := 0
+	err := Poll(context.Background(), fastWait(), func(context.Context) (bool, string, error) {
+		calls++
+		return false, "", errBoom
+	})
+	if !errors.Is(err, errBoom) {
+		t.Fatalf("Poll error = %v, want %v", err, errBoom)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+}
+
+func TestPollTimeout(t *testing.T) {
+	opts := fastWait()
+	opts.Timeout = 20 * time.Millisecond
+	err := Poll(context.Background(), opts, func(context.Context) (bool, string, error) {
+		return false, "CREATING", nil
+	})
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("Poll error = %v, want ErrWaitTimeout", err)
+	}
+}
+
+func TestPollTimeoutInterruptsHungCheck(t *testing.T) {
+	opts := fastWait()
+	opts.Timeout = 20 * time.Millisecond
+	hang := make(chan struct{})
+	defer close(hang)
+	start := time.Now()
+	err := Poll(context.Background(), opts, func(context.Context) (bool, string, error) {
+		<-hang // a request that ignores its context
+		return true, "READY", nil
+	})
+	if !errors.Is(err, ErrWaitTimeout) {
+		t.Fatalf("Poll error = %v, want ErrWaitTimeout", err)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("Poll returned after %v", d)
+	}
+}
+
+func TestWaitOptionsBackoff(t *testing.T) {
+	o := WaitOptions{}.withDefaults()
+	if o.Timeout != 20*time.Minute || o.InitialDelay != 5*time.Second || o.MaxDelay != time.Minute {
+		t.Errorf("defaults = %+v", o)
+	}
+}
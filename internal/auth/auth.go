@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -21,6 +22,7 @@ const defaultProfileName = "Default"
 
 type BrowserAuth struct {
 	debug           bool
+	logger          *slog.Logger
 	tempDir         string
 	chromeCmd       *exec.Cmd
 	cancel          context.CancelFunc
@@ -35,6 +37,19 @@ func New(debug bool) *BrowserAuth {
 	}
 }
 
+// SetLogger sets the logger for progress and diagnostics. Without one,
+// output goes to stderr in debug mode and is discarded otherwise.
+func (ba *BrowserAuth) SetLogger(logger *slog.Logger) {
+	ba.logger = logger
+}
+
+func (ba *BrowserAuth) log() *slog.Logger {
+	if ba.logger != nil {
+		return ba.logger
+	}
+	return defaultLogger(ba.debug)
+}
+
 type Options struct {
 	ProfileName       string
 	TryAllProfiles    bool
@@ -71,26 +86,20 @@ func (ba *BrowserAuth) tryMultipleProfiles(targetURL string) (token, cookies str
 
 	// Try each profile
 	for _, profile := range profiles {
-		if ba.debug {
-			fmt.Printf("Trying profile: %s [%s]\n", profile.Name, profile.Browser)
-		}
+		ba.log().Debug("trying profile", "profile", profile.Name, "browser", profile.Browser)
 
 		// Clean up previous attempts
 		ba.cleanup()
 
 		// Check if we should use original profile directory
 		useOriginal := os.Getenv("NLM_USE_ORIGINAL_PROFILE")
-		if ba.debug {
-			fmt.Printf("NLM_USE_ORIGINAL_PROFILE=%s\n", useOriginal)
-		}
+		ba.log().Debug("profile mode", "NLM_USE_ORIGINAL_PROFILE", useOriginal)
 
 		var userDataDir string
 		if useOriginal == "1" {
 			// Use parent directory of the profile path for session continuity
 			userDataDir = filepath.Dir(profile.Path)
-			if ba.debug {
-				fmt.Printf("Using original profile directory: %s\n", userDataDir)
-			}
+			ba.log().Debug("using original profile directory", "dir", userDataDir)
 		} else {
 			// Create a temporary directory and copy the profile data
 			tempDir, err := os.MkdirTemp("", "nlm-chrome-*")
@@ -102,9 +111,7 @@ func (ba *BrowserAuth) tryMultipleProfiles(targetURL string) (token, cookies str
 
 			// Copy the entire profile directory to temp location
 			if err := ba.copyProfileDataFromPath(profile.Path); err != nil {
-				if ba.debug {
-					fmt.Printf("Error copying profile %s: %v\n", profile.Name, err)
-				}
+				ba.log().Debug("copy profile failed", "profile", profile.Name, "error", err)
 				_ = os.RemoveAll(tempDir)
 				continue
 			}
@@ -160,21 +167,17 @@ func (ba *BrowserAuth) tryMultipleProfiles(targetURL string) (token, cookies str
 
 		if ba.debug {
 			ctx, _ = chromedp.NewContext(ctx, chromedp.WithLogf(func(format string, args ...interface{}) {
-				fmt.Printf("ChromeDP: "+format+"\n", args...)
+				ba.log().Debug("chromedp: " + fmt.Sprintf(format, args...))
 			}))
 		}
 
 		token, cookies, err = ba.extractAuthDataForURL(ctx, targetURL)
 		if err == nil && token != "" {
-			if ba.debug {
-				fmt.Printf("Successfully authenticated with profile: %s [%s]\n", profile.Name, profile.Browser)
-			}
+			ba.log().Debug("authenticated with profile", "profile", profile.Name, "browser", profile.Browser)
 			return token, cookies, nil
 		}
 
-		if ba.debug {
-			fmt.Printf("Profile %s [%s] could not authenticate: %v\n", profile.Name, profile.Browser, err)
-		}
+		ba.log().Debug("profile could not authenticate", "profile", profile.Name, "browser", profile.Browser, "error", err)
 	}
 
 	return "", "", fmt.Errorf("no profiles could authenticate")
@@ -425,7 +428,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 
 		// If requested, check notebooks for each profile that has valid cookies
 		if o.CheckNotebooks {
-			fmt.Println("Checking notebook access for profiles...")
+			ba.log().Info("checking notebook access for profiles")
 
 			// Create a pool of profiles to check
 			var profilesToCheck []ProfileInfo
@@ -454,19 +457,20 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 				}
 
 				if shouldCheck {
-					fmt.Printf("  Checking notebooks for %s [%s]...", p.Name, p.Browser)
+					log := ba.log().With("profile", p.Name, "browser", p.Browser)
+					log.Info("checking notebooks")
 
 					// Set up a temporary Chrome instance to authenticate
 					tempDir, err := os.MkdirTemp("", "nlm-notebook-check-*")
 					if err != nil {
-						fmt.Println(" Error: could not create temp dir")
+						log.Warn("notebook check failed: could not create temp dir", "error", err)
 						updatedProfiles = append(updatedProfiles, p)
 						continue
 					}
 
 					// Create a temporary BrowserAuth
 					tempAuth := &BrowserAuth{
-						debug:   false,
+						logger:  ba.log(),
 						tempDir: tempDir,
 					}
 					defer func() {
@@ -476,7 +480,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 					// Copy profile data
 					err = tempAuth.copyProfileDataFromPath(p.Path)
 					if err != nil {
-						fmt.Println(" Error: could not copy profile data")
+						log.Warn("notebook check failed: could not copy profile data", "error", err)
 						updatedProfiles = append(updatedProfiles, p)
 						continue
 					}
@@ -505,7 +509,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 					cancel()
 
 					if err != nil || token == "" {
-						fmt.Println(" Not authenticated")
+						log.Info("profile not authenticated")
 						updatedProfiles = append(updatedProfiles, p)
 						continue
 					}
@@ -518,13 +522,13 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 					// Try to get notebooks
 					notebookCount, err := countNotebooks(token, cookies)
 					if err != nil {
-						fmt.Println(" Error counting notebooks")
+						log.Warn("count notebooks failed", "error", err)
 						updatedProfiles = append(updatedProfiles, profile)
 						continue
 					}
 
 					profile.NotebookCount = notebookCount
-					fmt.Printf(" Found %d notebooks\n", notebookCount)
+					log.Info("found notebooks", "count", notebookCount)
 					updatedProfiles = append(updatedProfiles, profile)
 				} else {
 					// Skip notebook check for this profile
@@ -537,8 +541,9 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 		}
 
 		// Show profile information
-		fmt.Println("Available browser profiles:")
-		fmt.Println("===========================")
+		// The profile list is for the person at the terminal; keep it off stdout.
+		fmt.Fprintln(os.Stderr, "Available browser profiles:")
+		fmt.Fprintln(os.Stderr, "===========================")
 		for _, p := range profiles {
 			cookieStatus := ""
 			if targetDomain != "" {
@@ -554,7 +559,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 				notebookStatus = fmt.Sprintf(" [%d notebooks]", p.NotebookCount)
 			}
 
-			fmt.Printf("%d. %s [%s] - Last used: %s (%d files, %.1f MB)%s%s\n",
+			fmt.Fprintf(os.Stderr, "%d. %s [%s] - Last used: %s (%d files, %.1f MB)%s%s\n",
 				1, p.Name, p.Browser,
 				p.LastUsed.Format("2006-01-02 15:04:05"),
 				len(p.Files),
@@ -562,14 +567,14 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 				cookieStatus,
 				notebookStatus)
 		}
-		fmt.Println("===========================")
+		fmt.Fprintln(os.Stderr, "===========================")
 
 		if o.TryAllProfiles {
-			fmt.Println("Will try profiles in order shown above...")
+			fmt.Fprintln(os.Stderr, "Will try profiles in order shown above...")
 		} else {
-			fmt.Printf("Using profile: %s\n", o.ProfileName)
+			fmt.Fprintf(os.Stderr, "Using profile: %s\n", o.ProfileName)
 		}
-		fmt.Println()
+		fmt.Fprintln(os.Stderr)
 	}
 
 	// If trying all profiles, try to find one that works
@@ -595,10 +600,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 	// If no exact match, use the first profile (most recently used)
 	if selectedProfile == nil && len(profiles) > 0 {
 		selectedProfile = &profiles[0]
-		if ba.debug {
-			fmt.Printf("Profile '%s' not found, using most recently used profile: %s [%s]\n",
-				o.ProfileName, selectedProfile.Name, selectedProfile.Browser)
-		}
+		ba.log().Debug("profile not found, using most recently used", "requested", o.ProfileName, "profile", selectedProfile.Name, "browser", selectedProfile.Browser)
 	}
 
 	if selectedProfile == nil {
@@ -615,9 +617,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 	if useOriginalProfile {
 		// Use the parent directory of the profile path (e.g., ~/.config/google-chrome)
 		userDataDir = filepath.Dir(selectedProfile.Path)
-		if ba.debug {
-			fmt.Printf("Using original profile directory: %s\n", userDataDir)
-		}
+		ba.log().Debug("using original profile directory", "dir", userDataDir)
 	} else {
 		// Default behavior: create a temporary directory and copy profile data
 		tempDir, err := os.MkdirTemp("", "nlm-chrome-*")
@@ -670,7 +670,7 @@ func (ba *BrowserAuth) GetAuth(opts ...Option) (token, cookies string, err error
 
 	if ba.debug {
 		ctx, _ = chromedp.NewContext(ctx, chromedp.WithLogf(func(format string, args ...interface{}) {
-			fmt.Printf("ChromeDP: "+format+"\n", args...)
+			ba.log().Debug("chromedp: " + fmt.Sprintf(format, args...))
 		}))
 	}
 
@@ -693,25 +693,17 @@ func (ba *BrowserAuth) copyProfileData(profileName string) error {
 
 		if _, err := os.Stat(canarySourceDir); err == nil {
 			sourceDir = canarySourceDir
-			if ba.debug {
-				fmt.Printf("Using Chrome Canary profile: %s\n", sourceDir)
-			}
+			ba.log().Debug("using Chrome Canary profile", "dir", sourceDir)
 		} else if profileName == defaultProfileName {
 			// If still not found and this is Default, try to find any recent profile
 			// Try to find the most recently used profile
 			profiles, _ := ba.scanProfiles()
 			if len(profiles) > 0 {
 				sourceDir = profiles[0].Path
-				if ba.debug {
-					fmt.Printf("Profile '%s' not found, using most recently used profile: %s [%s]\n",
-						defaultProfileName,
-						profiles[0].Name, profiles[0].Browser)
-				}
+				ba.log().Debug("profile not found, using most recently used", "requested", defaultProfileName, "profile", profiles[0].Name, "browser", profiles[0].Browser)
 			} else if foundProfile := findMostRecentProfile(profilePath); foundProfile != "" {
 				sourceDir = foundProfile
-				if ba.debug {
-					fmt.Printf("Profile '%s' not found, using most recently used profile: %s\n", defaultProfileName, sourceDir)
-				}
+				ba.log().Debug("profile not found, using most recently used", "requested", defaultProfileName, "dir", sourceDir)
 			}
 		}
 	}
@@ -721,9 +713,7 @@ func (ba *BrowserAuth) copyProfileData(profileName string) error {
 
 // copyProfileDataFromPath copies profile data from a specific path
 func (ba *BrowserAuth) copyProfileDataFromPath(sourceDir string) error {
-	if ba.debug {
-		fmt.Printf("Copying profile data from: %s\n", sourceDir)
-	}
+	ba.log().Debug("copying profile data", "dir", sourceDir)
 
 	// Create Default profile directory
 	defaultDir := filepath.Join(ba.tempDir, defaultProfileName)
@@ -754,17 +744,13 @@ func (ba *BrowserAuth) copyProfileDataFromPath(sourceDir string) error {
 		}
 
 		if err := copyFile(srcPath, dstPath); err != nil {
-			if ba.debug {
-				fmt.Printf("Warning: Failed to copy %s: %v\n", file, err)
-			}
+			ba.log().Warn("copy profile file failed", "file", file, "error", err)
 			continue
 		}
 		copiedCount++
 	}
 
-	if ba.debug {
-		fmt.Printf("Copied %d essential files for authentication\n", copiedCount)
-	}
+	ba.log().Debug("copied profile files", "count", copiedCount)
 
 	// Create minimal Local State file
 	localState := `{"os_crypt":{"encrypted_key":""}}`
@@ -840,10 +826,7 @@ func (ba *BrowserAuth) startChromeExec() (string, error) {
 		return "", fmt.Errorf("chrome not found")
 	}
 
-	if ba.debug {
-		fmt.Printf("Starting Chrome from: %s\n", chromePath)
-		fmt.Printf("Using profile: %s\n", ba.tempDir)
-	}
+	ba.log().Debug("starting Chrome", "path", chromePath, "profile", ba.tempDir)
 
 	//nolint:gosec // fixed binary and flags for local browser auth
 	ba.chromeCmd = exec.Command(chromePath,
@@ -875,7 +858,7 @@ func (ba *BrowserAuth) startChromeExec() (string, error) {
 
 //nolint:unused // retained for direct Chrome exec path
 func (ba *BrowserAuth) waitForDebugger(debugURL string) error {
-	fmt.Println("Waiting for Chrome debugger...")
+	ba.log().Debug("waiting for Chrome debugger")
 
 	timeout := time.After(20 * time.Second)
 	ticker := time.NewTicker(100 * time.Millisecond)
@@ -889,12 +872,9 @@ func (ba *BrowserAuth) waitForDebugger(debugURL string) error {
 			resp, err := http.Get(debugURL + "/json/version")
 			if err == nil {
 				_ = resp.Body.Close()
-				fmt.Println("Chrome debugger ready")
+				ba.log().Debug("Chrome debugger ready")
 				return nil
 			}
-			if ba.debug {
-				fmt.Printf(".")
-			}
 		}
 	}
 }
@@ -937,14 +917,14 @@ func copyFile(src, dst string) error {
 // copyDirectoryRecursive recursively copies all files and subdirectories from src to dst
 //
 //nolint:unused // retained for full profile copy fallback
-func copyDirectoryRecursive(src, dst string, debug bool) error {
-	return copyDirectoryRecursiveWithCount(src, dst, debug, nil, nil)
+func copyDirectoryRecursive(src, dst string, logger *slog.Logger) error {
+	return copyDirectoryRecursiveWithCount(src, dst, logger, nil, nil)
 }
 
 // copyDirectoryRecursiveWithCount recursively copies with file counting
 //
 //nolint:unused // retained for full profile copy fallback
-func copyDirectoryRecursiveWithCount(src, dst string, debug bool, fileCount, dirCount *int) error {
+func copyDirectoryRecursiveWithCount(src, dst string, logger *slog.Logger, fileCount, dirCount *int) error {
 	entries, err := os.ReadDir(src)
 	if err != nil {
 		return fmt.Errorf("read directory %s: %w", src, err)
@@ -957,9 +937,7 @@ func copyDirectoryRecursiveWithCount(src, dst string, debug bool, fileCount, dir
 		if entry.IsDir() {
 			// Create destination directory
 			if err := os.MkdirAll(dstPath, 0o750); err != nil {
-				if debug {
-					fmt.Printf("Failed to create directory %s: %v\n", dstPath, err)
-				}
+				logger.Debug("create directory failed", "dir", dstPath, "error", err)
 				continue
 			}
 
@@ -968,10 +946,8 @@ func copyDirectoryRecursiveWithCount(src, dst string, debug bool, fileCount, dir
 			}
 
 			// Recursively copy subdirectory
-			if err := copyDirectoryRecursiveWithCount(srcPath, dstPath, debug, fileCount, dirCount); err != nil {
-				if debug {
-					fmt.Printf("Failed to copy subdirectory %s: %v\n", srcPath, err)
-				}
+			if err := copyDirectoryRecursiveWithCount(srcPath, dstPath, logger, fileCount, dirCount); err != nil {
+				logger.Debug("copy subdirectory failed", "dir", srcPath, "error", err)
 				continue
 			}
 		} else {
@@ -1054,15 +1030,13 @@ func (ba *BrowserAuth) extractAuthDataForURL(ctx context.Context, targetURL stri
 		}
 	`, nil)); err != nil {
 		// Don't fail if anti-detection script fails, just log it
-		if ba.debug {
-			fmt.Printf("Anti-detection script failed: %v\n", err)
-		}
+		ba.log().Debug("anti-detection script failed", "error", err)
 	}
 
 	// If keep-open is set, give user time to manually authenticate BEFORE checking
 	if ba.keepOpenSeconds > 0 {
-		fmt.Printf("\n⏳ Browser opened. You have %d seconds to manually log in if needed...\n", ba.keepOpenSeconds)
-		fmt.Printf("  If already logged in, just wait for automatic authentication.\n\n")
+		fmt.Fprintf(os.Stderr, "\n⏳ Browser opened. You have %d seconds to manually log in if needed...\n", ba.keepOpenSeconds)
+		fmt.Fprintf(os.Stderr, "  If already logged in, just wait for automatic authentication.\n\n")
 		time.Sleep(time.Duration(ba.keepOpenSeconds) * time.Second)
 	}
 
@@ -1070,17 +1044,13 @@ func (ba *BrowserAuth) extractAuthDataForURL(ctx context.Context, targetURL stri
 	var currentURL string
 	if err := chromedp.Run(ctx, chromedp.Location(&currentURL)); err == nil {
 		// Log the initial URL we landed on
-		if ba.debug {
-			fmt.Printf("Initial navigation landed on: %s\n", currentURL)
-		}
+		ba.log().Debug("initial navigation", "url", currentURL)
 
 		// If we immediately landed on an auth page, this profile is likely not authenticated
 		if strings.Contains(currentURL, "accounts.google.com") ||
 			strings.Contains(currentURL, "signin") ||
 			strings.Contains(currentURL, "login") {
-			if ba.debug {
-				fmt.Printf("Redirected to auth page: %s\n", currentURL)
-			}
+			ba.log().Debug("redirected to auth page", "url", currentURL)
 
 			return "", "", fmt.Errorf("redirected to authentication page - not logged in")
 		}
@@ -1119,12 +1089,8 @@ func (ba *BrowserAuth) extractAuthDataForURL(ctx context.Context, targetURL stri
 					}
 				}
 
-				if ba.debug {
-					// Show seconds remaining from ctx at end of this:
-					deadline, _ := ctx.Deadline()
-					remaining := time.Until(deadline).Seconds()
-					fmt.Printf("   Auth check failed: %v (%.1f seconds remaining)\n", err, remaining)
-				}
+				deadline, _ := ctx.Deadline()
+				ba.log().Debug("auth check failed", "error", err, "remaining", time.Until(deadline).Round(100*time.Millisecond))
 				continue
 			}
 
@@ -1134,9 +1100,7 @@ func (ba *BrowserAuth) extractAuthDataForURL(ctx context.Context, targetURL stri
 				// Get the final URL to confirm we're on the right page
 				var successURL string
 				if err := chromedp.Run(ctx, chromedp.Location(&successURL)); err == nil {
-					if ba.debug {
-						fmt.Printf("Successful authentication URL: %s\n", successURL)
-					}
+					ba.log().Debug("authentication URL", "url", successURL)
 
 					// Double-check we're not on a login page (shouldn't happen with our improved checks)
 					if strings.Contains(successURL, "accounts.google.com") ||
@@ -1146,23 +1110,17 @@ func (ba *BrowserAuth) extractAuthDataForURL(ctx context.Context, targetURL stri
 				}
 
 				// Authentication successful - perform graceful shutdown
-				if ba.debug {
-					fmt.Printf("✓ Authentication successful!\n")
-				}
+				ba.log().Debug("authentication successful")
 
 				// Gracefully close the browser to avoid crash detection
 				if err := ba.gracefulShutdown(ctx); err != nil {
-					if ba.debug {
-						fmt.Printf("Warning: graceful shutdown failed: %v\n", err)
-					}
+					ba.log().Warn("graceful browser shutdown failed", "error", err)
 				}
 
 				return token, cookies, nil
 			}
 
-			if ba.debug {
-				fmt.Println("Waiting for auth data...")
-			}
+			ba.log().Debug("waiting for auth data")
 		}
 	}
 }
@@ -1190,9 +1148,7 @@ func (ba *BrowserAuth) tryExtractAuth(ctx context.Context) (token, cookies strin
 	)
 	if err != nil {
 		// If there's an error evaluating, just continue
-		if ba.debug {
-			fmt.Printf("Error checking if on signin page: %v\n", err)
-		}
+		ba.log().Debug("check for sign-in page failed", "error", err)
 	}
 
 	if isSigninPage {
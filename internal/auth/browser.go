@@ -2,6 +2,7 @@ package auth
 
 import (
 	"fmt"
+	"log/slog"
 )
 
 type BrowserType int
@@ -24,14 +25,14 @@ func (b Browser) String() string {
 }
 
 //nolint:unused // retained for future browser detection enhancements
-func detectBrowsers(debug bool) []Browser {
+func detectBrowsers(logger *slog.Logger) []Browser {
 	var browsers []Browser
 
-	if chrome := detectChrome(debug); chrome.Path != "" {
+	if chrome := detectChrome(logger); chrome.Path != "" {
 		browsers = append(browsers, chrome)
 	}
 
-	if safari := detectSafari(debug); safari.Path != "" {
+	if safari := detectSafari(logger); safari.Path != "" {
 		browsers = append(browsers, safari)
 	}
 
@@ -4,6 +4,7 @@ package auth
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -88,7 +89,7 @@ func getBrowserPathForProfile(browserName string) string {
 }
 
 //nolint:unused // retained for future browser detection enhancements
-func detectChrome(debug bool) Browser {
+func detectChrome(logger *slog.Logger) Browser {
 	// First try standard paths
 	for _, browser := range macOSBrowserPaths {
 		if browser.Type != BrowserChrome {
@@ -96,9 +97,7 @@ func detectChrome(debug bool) Browser {
 		}
 		if _, err := os.Stat(browser.Path); err == nil {
 			version := getChromeVersion(browser.Path)
-			if debug {
-				fmt.Printf("Found %s at %s (version: %s)\n", browser.Name, browser.Path, version)
-			}
+			logger.Debug("found browser", "browser", browser.Name, "path", browser.Path, "version", version)
 			return Browser{
 				Type:    browser.Type,
 				Path:    browser.Path,
@@ -121,9 +120,7 @@ func detectChrome(debug bool) Browser {
 		if path := findBrowserViaMDFind(bundleID); path != "" {
 			execPath := filepath.Join(path, "Contents/MacOS", browser.Name)
 			version := getChromeVersion(execPath)
-			if debug {
-				fmt.Printf("Found %s via mdfind at %s (version: %s)\n", browser.Name, execPath, version)
-			}
+			logger.Debug("found browser via mdfind", "browser", browser.Name, "path", execPath, "version", version)
 			return Browser{
 				Type:    browser.Type,
 				Path:    execPath,
@@ -131,14 +128,10 @@ func detectChrome(debug bool) Browser {
 				Version: version,
 			}
 		}
-		if debug {
-			fmt.Printf("No %s found via mdfind\n", browser.Name)
-		}
+		logger.Debug("browser not found via mdfind", "browser", browser.Name)
 	}
 
-	if debug {
-		fmt.Printf("No Chrome-based browsers found\n")
-	}
+	logger.Debug("no Chrome-based browsers found")
 	return Browser{Type: BrowserUnknown}
 }
 
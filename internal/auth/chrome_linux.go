@@ -3,6 +3,7 @@
 package auth
 
 import (
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,7 +11,7 @@ import (
 )
 
 //nolint:unused // retained for future browser detection enhancements
-func detectChrome(debug bool) Browser {
+func detectChrome(logger *slog.Logger) Browser {
 	// Try standard Chrome first
 	if path, err := exec.LookPath("google-chrome"); err == nil {
 		version := getChromeVersion(path)
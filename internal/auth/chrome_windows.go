@@ -3,13 +3,14 @@
 package auth
 
 import (
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 )
 
-func detectChrome(debug bool) Browser {
+func detectChrome(logger *slog.Logger) Browser {
 	path := getChromePath()
 	if path == "" {
 		return Browser{Type: BrowserUnknown}
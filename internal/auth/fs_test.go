@@ -51,7 +51,7 @@ func TestCopyDirectoryRecursiveWithCount(t *testing.T) {
 	}
 
 	var files, dirs int
-	if err := copyDirectoryRecursiveWithCount(src, dst, false, &files, &dirs); err != nil {
+	if err := copyDirectoryRecursiveWithCount(src, dst, defaultLogger(false), &files, &dirs); err != nil {
 		t.Fatalf("copyDirectoryRecursiveWithCount error: %v", err)
 	}
 
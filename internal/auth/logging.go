@@ -0,0 +1,15 @@
+package auth
+
+import (
+	"log/slog"
+	"os"
+)
+
+// defaultLogger returns a stderr debug logger when debug is set and a
+// discarding logger otherwise.
+func defaultLogger(debug bool) *slog.Logger {
+	if !debug {
+		return slog.New(slog.DiscardHandler)
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
@@ -30,6 +31,7 @@ type RefreshClient struct {
 	sapisid    string
 	httpClient *http.Client
 	debug      bool
+	logger     *slog.Logger
 }
 
 // NewRefreshClient creates a new refresh client
@@ -52,6 +54,18 @@ func (r *RefreshClient) SetDebug(debug bool) {
 	r.debug = debug
 }
 
+// SetLogger sets the structured logger used for debug output
+func (r *RefreshClient) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
+
+func (r *RefreshClient) log() *slog.Logger {
+	if r.logger != nil {
+		return r.logger
+	}
+	return defaultLogger(r.debug)
+}
+
 // RefreshCredentials refreshes the authentication credentials
 func (r *RefreshClient) RefreshCredentials(gsessionID string) error {
 	// Build the URL with parameters
@@ -92,14 +106,11 @@ func (r *RefreshClient) RefreshCredentials(gsessionID string) error {
 	req.Header.Set("X-Goog-AuthUser", "0")
 	req.Header.Set("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/139.0.0.0 Safari/537.36")
 
-	if r.debug {
-		fmt.Printf("=== Credential Refresh Request ===\n")
-		fmt.Printf("URL: %s\n", fullURL)
-		fmt.Printf("Authorization: SAPISIDHASH %d_%s\n", timestamp, authHash)
-		fmt.Printf("Body: %s\n", string(bodyJSON))
-	}
+	log := r.log()
+	log.Debug("credential refresh request", "url", fullURL, "body", string(bodyJSON))
 
 	// Send the request
+	start := time.Now()
 	resp, err := r.httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send refresh request: %w", err)
@@ -114,11 +125,12 @@ func (r *RefreshClient) RefreshCredentials(gsessionID string) error {
 		return fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if r.debug {
-		fmt.Printf("=== Credential Refresh Response ===\n")
-		fmt.Printf("Status: %s\n", resp.Status)
-		fmt.Printf("Body: %s\n", string(body))
-	}
+	log.Debug("credential refresh response",
+		"status", resp.StatusCode,
+		"duration", time.Since(start),
+		"bytes", len(body),
+		"body", string(body),
+	)
 
 	if resp.StatusCode != http.StatusOK {
 		return fmt.Errorf("refresh failed with status %d: %s", resp.StatusCode, string(body))
@@ -126,9 +138,7 @@ func (r *RefreshClient) RefreshCredentials(gsessionID string) error {
 
 	// Parse response to check for success
 	// The response format needs to be determined from actual API responses
-	if r.debug {
-		fmt.Println("Credentials refreshed successfully")
-	}
+	log.Debug("credentials refreshed")
 
 	return nil
 }
@@ -228,9 +238,7 @@ func (r *RefreshClient) RefreshLoop(gsessionID string, interval time.Duration) {
 
 	for range ticker.C {
 		if err := r.RefreshCredentials(gsessionID); err != nil {
-			if r.debug {
-				fmt.Printf("Failed to refresh credentials: %v\n", err)
-			}
+			r.log().Warn("credential refresh failed", "error", err)
 		}
 	}
 }
@@ -281,6 +289,7 @@ type TokenManager struct {
 	stopChan     chan struct{}
 	running      bool
 	debug        bool
+	logger       *slog.Logger
 	refreshAhead time.Duration // How far ahead of expiry to refresh (e.g., 5 minutes)
 }
 
@@ -293,6 +302,18 @@ func NewTokenManager(debug bool) *TokenManager {
 	}
 }
 
+// SetLogger sets the structured logger used for debug output
+func (tm *TokenManager) SetLogger(logger *slog.Logger) {
+	tm.logger = logger
+}
+
+func (tm *TokenManager) log() *slog.Logger {
+	if tm.logger != nil {
+		return tm.logger
+	}
+	return defaultLogger(tm.debug)
+}
+
 // ParseAuthToken parses the auth token to extract expiration time
 // Token format: "token:timestamp" where timestamp is Unix milliseconds
 func ParseAuthToken(token string) (string, time.Time, error) {
@@ -381,9 +402,7 @@ func (tm *TokenManager) StartAutoRefreshManager() error {
 
 	go tm.monitorTokenExpiry()
 
-	if tm.debug {
-		fmt.Fprintf(os.Stderr, "Auto-refresh manager started\n")
-	}
+	tm.log().Debug("auto-refresh manager started")
 
 	return nil
 }
@@ -397,14 +416,10 @@ func (tm *TokenManager) monitorTokenExpiry() {
 		select {
 		case <-ticker.C:
 			if err := tm.checkAndRefresh(); err != nil {
-				if tm.debug {
-					fmt.Fprintf(os.Stderr, "Auto-refresh check failed: %v\n", err)
-				}
+				tm.log().Debug("auto-refresh check failed", "error", err)
 			}
 		case <-tm.stopChan:
-			if tm.debug {
-				fmt.Fprintf(os.Stderr, "Auto-refresh manager stopped\n")
-			}
+			tm.log().Debug("auto-refresh manager stopped")
 			return
 		}
 	}
@@ -427,15 +442,11 @@ func (tm *TokenManager) checkAndRefresh() error {
 	// Check if we need to refresh
 	timeUntilExpiry := time.Until(expiryTime)
 	if timeUntilExpiry > tm.refreshAhead {
-		if tm.debug {
-			fmt.Fprintf(os.Stderr, "Token still valid for %v, no refresh needed\n", timeUntilExpiry)
-		}
+		tm.log().Debug("token still valid, no refresh needed", "expires_in", timeUntilExpiry)
 		return nil
 	}
 
-	if tm.debug {
-		fmt.Fprintf(os.Stderr, "Token expiring in %v, refreshing now...\n", timeUntilExpiry)
-	}
+	tm.log().Debug("token expiring, refreshing", "expires_in", timeUntilExpiry)
 
 	// Get cookies for refresh
 	cookies, err := GetStoredCookies()
@@ -449,9 +460,8 @@ func (tm *TokenManager) checkAndRefresh() error {
 		return fmt.Errorf("failed to create refresh client: %w", err)
 	}
 
-	if tm.debug {
-		refreshClient.SetDebug(true)
-	}
+	refreshClient.SetDebug(tm.debug)
+	refreshClient.SetLogger(tm.logger)
 
 	// Use hardcoded gsessionID for now (TODO: extract dynamically)
 	gsessionID := "LsWt3iCG3ezhLlQau_BO2Gu853yG1uLi0RnZlSwqVfg"
@@ -461,9 +471,7 @@ func (tm *TokenManager) checkAndRefresh() error {
 		return fmt.Errorf("failed to refresh credentials: %w", err)
 	}
 
-	if tm.debug {
-		fmt.Fprintf(os.Stderr, "Credentials refreshed successfully\n")
-	}
+	tm.log().Debug("credentials refreshed")
 
 	return nil
 }
@@ -4,22 +4,21 @@ package auth
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
 )
 
 //nolint:unused // retained for future browser detection enhancements
-func detectSafari(debug bool) Browser {
+func detectSafari(logger *slog.Logger) Browser {
 	for _, browser := range macOSBrowserPaths {
 		if browser.Type != BrowserSafari {
 			continue
 		}
 		if _, err := os.Stat(browser.Path); err == nil {
 			version := getSafariVersion()
-			if debug {
-				fmt.Printf("Found Safari at %s (version: %s)\n", browser.Path, version)
-			}
+			logger.Debug("found browser", "browser", "Safari", "path", browser.Path, "version", version)
 			return Browser{
 				Type:    BrowserSafari,
 				Path:    browser.Path,
@@ -2,7 +2,9 @@
 
 package auth
 
+import "log/slog"
+
 //nolint:unused // retained for future browser detection enhancements
-func detectSafari(debug bool) Browser {
+func detectSafari(logger *slog.Logger) Browser {
 	return Browser{Type: BrowserUnknown}
 }
@@ -2,10 +2,12 @@ package batchexecute
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/rand"
 	"net/http"
 	"net/url"
@@ -124,6 +126,11 @@ func (c *Client) Do(rpc RPC) (*Response, error) {
 	return c.Execute([]RPC{rpc})
 }
 
+// DoContext executes a single RPC call with the given context.
+func (c *Client) DoContext(ctx context.Context, rpc RPC) (*Response, error) {
+	return c.ExecuteContext(ctx, []RPC{rpc})
+}
+
 // maskSensitiveValue masks sensitive values like tokens for debug output
 func maskSensitiveValue(value string) string {
 	switch {
@@ -173,6 +180,12 @@ func buildRPCData(rpc RPC) []interface{} {
 
 // Execute performs the batch execute request
 func (c *Client) Execute(rpcs []RPC) (*Response, error) {
+	return c.ExecuteContext(context.Background(), rpcs)
+}
+
+// ExecuteContext performs the batch execute request with the given context.
+func (c *Client) ExecuteContext(ctx context.Context, rpcs []RPC) (*Response, error) {
+	started := time.Now()
 	u, err := url.Parse(fmt.Sprintf("https://%s/_/%s/data/batchexecute", c.config.Host, c.config.App))
 	if err != nil {
 		return nil, fmt.Errorf("parse url: %w", err)
@@ -196,13 +209,15 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 	}
 	// Note: rt parameter is now controlled via URLParams from client configuration
 	// If not set, we'll get JSON array format (easier to parse)
-	q.Set("_reqid", c.reqid.Next())
+	reqID := c.reqid.Next()
+	q.Set("_reqid", reqID)
 	u.RawQuery = q.Encode()
 
-	if c.config.Debug {
-		fmt.Printf("\n=== BatchExecute Request ===\n")
-		fmt.Printf("URL: %s\n", u.String())
+	rpcIDs := make([]string, 0, len(rpcs))
+	for _, rpc := range rpcs {
+		rpcIDs = append(rpcIDs, rpc.ID)
 	}
+	log := c.logger.With("rpc", strings.Join(rpcIDs, ","), "reqid", reqID)
 
 	// Build request body
 	var envelope []interface{}
@@ -219,28 +234,26 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 	form.Set("f.req", string(reqBody))
 	form.Set("at", c.config.AuthToken)
 
-	if c.config.Debug {
-		// Safely display auth token with conservative masking
-		tokenDisplay := maskSensitiveValue(c.config.AuthToken)
-		fmt.Printf("\nAuth Token: %s\n", tokenDisplay)
-
+	if log.Enabled(ctx, slog.LevelDebug) {
 		// Mask auth token in request body display
 		maskedForm := url.Values{}
 		for k, v := range form {
 			if k == "at" && len(v) > 0 {
-				// Mask the auth token value
-				maskedValue := maskSensitiveValue(v[0])
-				maskedForm.Set(k, maskedValue)
+				maskedForm.Set(k, maskSensitiveValue(v[0]))
 			} else {
 				maskedForm[k] = v
 			}
 		}
-		fmt.Printf("\nRequest Body:\n%s\n", maskedForm.Encode())
-		fmt.Printf("\nDecoded Request Body:\n%s\n", string(reqBody))
+		log.DebugContext(ctx, "batchexecute request",
+			"url", u.String(),
+			"auth_token", maskSensitiveValue(c.config.AuthToken),
+			"body", maskedForm.Encode(),
+			"f.req", string(reqBody),
+		)
 	}
 
 	// Create request
-	req, err := http.NewRequest("POST", u.String(), strings.NewReader(form.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", u.String(), strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
@@ -252,24 +265,26 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 	}
 	req.Header.Set("cookie", c.config.Cookies)
 
-	if c.config.Debug {
-		fmt.Printf("\nRequest Headers:\n")
+	if log.Enabled(ctx, slog.LevelDebug) {
+		var attrs []any
 		for k, v := range req.Header {
 			if strings.ToLower(k) == "cookie" && len(v) > 0 {
 				// Mask cookie values for security
-				maskedCookies := maskCookieValues(v[0])
-				fmt.Printf("%s: [%s]\n", k, maskedCookies)
+				attrs = append(attrs, k, maskCookieValues(v[0]))
 			} else {
-				fmt.Printf("%s: %v\n", k, v)
+				attrs = append(attrs, k, strings.Join(v, ", "))
 			}
 		}
+		log.DebugContext(ctx, "batchexecute request headers", slog.Group("headers", attrs...))
 	}
 
 	// Execute request with retry logic
 	var resp *http.Response
 	var lastErr error
+	var attempts int
 
 	for attempt := 0; attempt <= c.config.MaxRetries; attempt++ {
+		attempts = attempt + 1
 		if attempt > 0 {
 			// Calculate retry delay with exponential backoff
 			multiplier := 1 << (attempt - 1)
@@ -278,20 +293,21 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 				delay = c.config.RetryMaxDelay
 			}
 
-			if c.config.Debug {
-				fmt.Printf("\nRetrying request (attempt %d/%d) after %v...\n", attempt, c.config.MaxRetries, delay)
-			}
+			log.InfoContext(ctx, "batchexecute retry", "attempt", attempt+1, "max_retries", c.config.MaxRetries, "delay", delay)
 			time.Sleep(delay)
 		}
 
 		// Clone the request for each attempt
-		reqClone := req.Clone(req.Context())
+		info := RequestInfo{RPCIDs: rpcIDs, ReqID: reqID, Attempt: attempt + 1}
+		reqClone := req.Clone(withRequestInfo(req.Context(), info))
 		if req.Body != nil {
 			reqClone.Body = io.NopCloser(strings.NewReader(form.Encode()))
 		}
 
+		start := time.Now()
 		resp, err = c.httpClient.Do(reqClone)
 		if err != nil {
+			log.DebugContext(ctx, "batchexecute attempt failed", "attempt", attempt+1, "duration", time.Since(start), "error", err)
 			lastErr = err
 			// Check for common network errors and provide more helpful messages
 			if strings.Contains(err.Error(), "dial tcp") {
@@ -311,6 +327,8 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 			return nil, lastErr
 		}
 
+		log.DebugContext(ctx, "batchexecute attempt", "attempt", attempt+1, "status", resp.StatusCode, "duration", time.Since(start))
+
 		// Check if response status is retryable
 		if isRetryableStatus(resp.StatusCode) && attempt < c.config.MaxRetries {
 			_ = resp.Body.Close()
@@ -334,11 +352,13 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 		return nil, fmt.Errorf("read response: %w", err)
 	}
 
-	if c.config.Debug {
-		fmt.Printf("\nResponse Status: %s\n", resp.Status)
-		fmt.Printf("Raw Response Body:\n%q\n", string(body))
-		fmt.Printf("Response Body:\n%s\n", string(body))
-	}
+	log.InfoContext(ctx, "batchexecute",
+		"attempt", attempts,
+		"status", resp.StatusCode,
+		"duration", time.Since(started),
+		"bytes", len(body),
+	)
+	log.DebugContext(ctx, "batchexecute response body", "body", string(body))
 
 	if resp.StatusCode != http.StatusOK {
 		return nil, &BatchExecuteError{
@@ -351,10 +371,7 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 	// Try to parse the response
 	responses, err := decodeResponse(string(body))
 	if err != nil {
-		if c.config.Debug {
-			fmt.Printf("Failed to decode response: %v\n", err)
-			fmt.Printf("Raw response: %s\n", string(body))
-		}
+		log.DebugContext(ctx, "batchexecute decode failed", "error", err)
 
 		// Special handling for certain responses
 		if strings.Contains(string(body), "\"error\"") {
@@ -371,18 +388,14 @@ func (c *Client) Execute(rpcs []RPC) (*Response, error) {
 	}
 
 	if len(responses) == 0 {
-		if c.config.Debug {
-			fmt.Printf("No valid responses found in: %s\n", string(body))
-		}
+		log.DebugContext(ctx, "batchexecute response had no valid entries")
 		return nil, fmt.Errorf("no valid responses found")
 	}
 
 	// Check the first response for API errors
 	firstResponse := &responses[0]
 	if apiError, isError := IsErrorResponse(firstResponse); isError {
-		if c.config.Debug {
-			fmt.Printf("Detected API error: %s\n", apiError.Error())
-		}
+		log.DebugContext(ctx, "batchexecute api error", "error", apiError)
 		return nil, apiError
 	}
 
@@ -864,6 +877,9 @@ type Client struct {
 	httpClient *http.Client
 	debug      func(format string, args ...interface{})
 	reqid      *ReqIDGenerator
+	logger     *slog.Logger
+	middleware []Middleware
+	values     map[any]any
 }
 
 // NewClient creates a new batchexecute client
@@ -888,6 +904,10 @@ func NewClient(config Config, opts ...Option) *Client {
 	for _, opt := range opts {
 		opt(c)
 	}
+	if c.logger == nil {
+		c.logger = DefaultLogger(c.config.Debug)
+	}
+	c.applyMiddleware()
 	return c
 }
 
@@ -895,6 +915,22 @@ func (c *Client) Config() Config {
 	return c.config
 }
 
+// WithValue attaches a value to the client under key. It lets packages built
+// on top of batchexecute carry their own options through Option lists.
+func WithValue(key, value any) Option {
+	return func(c *Client) {
+		if c.values == nil {
+			c.values = make(map[any]any)
+		}
+		c.values[key] = value
+	}
+}
+
+// Value returns the value attached under key with WithValue, or nil.
+func (c *Client) Value(key any) any {
+	return c.values[key]
+}
+
 // ReqIDGenerator generates sequential request IDs
 type ReqIDGenerator struct {
 	base     int // Initial 4-digit number
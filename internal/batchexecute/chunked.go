@@ -18,7 +18,7 @@ import (
 // ...
 //
 //nolint:unused // retained for alternate chunked parsing
-func parseChunkedResponse(r io.Reader) ([]Response, error) {
+func (c *Client) parseChunkedResponse(r io.Reader) ([]Response, error) {
 	// First, strip the prefix if present
 	br := bufio.NewReader(r)
 
@@ -33,9 +33,8 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 		}
 	}
 
-	// Debug: print what we see
 	if len(prefix) > 0 {
-		fmt.Printf("DEBUG: Response starts with: %q\n", prefix)
+		c.logger.Debug("chunked response", "prefix", string(prefix))
 	}
 
 	// Check for and discard the )]}' prefix with newlines
@@ -45,13 +44,13 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 		if err != nil && err != io.EOF {
 			return nil, fmt.Errorf("read prefix line: %w", err)
 		}
-		fmt.Printf("DEBUG: Discarded prefix line: %q\n", line)
+		c.logger.Debug("discarded prefix line", "line", line)
 
 		// Check if there's an additional empty line and consume it
 		nextByte, err := br.Peek(1)
 		if err == nil && len(nextByte) > 0 && nextByte[0] == '\n' {
 			_, _ = br.ReadByte() // Consume the extra newline
-			fmt.Printf("DEBUG: Discarded extra newline after prefix\n")
+			c.logger.Debug("discarded extra newline after prefix")
 		}
 	}
 
@@ -74,16 +73,16 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 		line := scanner.Text()
 		allLines = append(allLines, line)
 
-		// Only debug small lines to avoid flooding
+		// Only log small lines in full to avoid flooding
 		if len(line) < 200 {
-			fmt.Printf("DEBUG: Processing line: %q\n", line)
+			c.logger.Debug("processing line", "line", line)
 		} else {
-			fmt.Printf("DEBUG: Processing large line (%d bytes)\n", len(line))
+			c.logger.Debug("processing large line", "bytes", len(line))
 		}
 
 		// Skip empty lines only if not collecting
 		if !collecting && strings.TrimSpace(line) == "" {
-			fmt.Printf("DEBUG: Skipping empty line\n")
+			c.logger.Debug("skipping empty line")
 			continue
 		}
 
@@ -110,7 +109,7 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 			chunkSize = size
 			collecting = true
 			chunkData.Reset()
-			fmt.Printf("DEBUG: Expecting chunk of %d bytes\n", chunkSize)
+			c.logger.Debug("expecting chunk", "bytes", chunkSize)
 			continue
 		}
 
@@ -122,7 +121,7 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 
 		// If we've collected enough data, add the chunk and reset
 		if chunkData.Len() >= chunkSize {
-			fmt.Printf("DEBUG: Collected full chunk (%d bytes)\n", chunkData.Len())
+			c.logger.Debug("collected full chunk", "bytes", chunkData.Len())
 			chunks = append(chunks, chunkData.String())
 			collecting = false
 		}
@@ -131,7 +130,7 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 	// Check if we have any partial chunk data remaining
 	if collecting && chunkData.Len() > 0 {
 		// We have partial data, add it as a chunk
-		fmt.Printf("DEBUG: Adding partial chunk (%d of %d bytes)\n", chunkData.Len(), chunkSize)
+		c.logger.Debug("adding partial chunk", "bytes", chunkData.Len(), "expected", chunkSize)
 		chunks = append(chunks, chunkData.String())
 	} else if collecting && chunkData.Len() == 0 {
 		// We were expecting data but got none
@@ -139,12 +138,12 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 		if chunkSize < 1000 {
 			// Small number, might be an error code
 			possibleError := strconv.Itoa(chunkSize)
-			fmt.Printf("DEBUG: Expected %d bytes but got 0, treating %s as potential error response\n", chunkSize, possibleError)
+			c.logger.Debug("empty chunk, treating its size as a possible error code", "code", possibleError)
 			chunks = append(chunks, possibleError)
 		} else {
 			// Large number, probably a real chunk size but we didn't get the data
 			// This might be a parsing issue with the scanner
-			fmt.Printf("DEBUG: Expected large chunk (%d bytes) but got 0, scanner may have hit limit\n", chunkSize)
+			c.logger.Debug("expected large chunk but got none, scanner may have hit its limit", "bytes", chunkSize)
 			// Try to use all lines as the chunk data
 			if len(allLines) > 1 {
 				// Skip the first line (chunk size) and use the rest
@@ -163,19 +162,19 @@ func parseChunkedResponse(r io.Reader) ([]Response, error) {
 	}
 
 	// Process all collected chunks
-	return processChunks(chunks)
+	return c.processChunks(chunks)
 }
 
 // extractWRBResponse attempts to manually extract a response from a chunk that contains "wrb.fr"
 // but can't be properly parsed as JSON
 //
 //nolint:unused // retained for alternate chunked parsing
-func extractWRBResponse(chunk string) *Response {
+func (c *Client) extractWRBResponse(chunk string) *Response {
 	// Try to parse this as a regular JSON array first
 	var data []interface{}
 	if err := json.Unmarshal([]byte(chunk), &data); err == nil {
 		// Use the standard extraction logic
-		responses, err := extractResponses([][]interface{}{data})
+		responses, err := c.extractResponses([][]interface{}{data})
 		if err == nil && len(responses) > 0 {
 			return &responses[0]
 		}
@@ -238,7 +237,7 @@ func extractWRBResponse(chunk string) *Response {
 	}
 
 	// No data found - return response with null data (don't mask the issue)
-	fmt.Printf("WARNING: No data found in wrb.fr response for ID %s\n", id)
+	c.logger.Warn("no data found in wrb.fr response", "rpc", id)
 	return &Response{
 		ID:   id,
 		Data: nil, // Return nil to indicate no data rather than fake success
@@ -304,10 +303,10 @@ func isNumeric(s string) bool {
 }
 
 //nolint:unused // retained for alternate chunked parsing
-func processChunks(chunks []string) ([]Response, error) {
-	fmt.Printf("DEBUG: processChunks called with %d chunks\n", len(chunks))
+func (c *Client) processChunks(chunks []string) ([]Response, error) {
+	c.logger.Debug("processing chunks", "count", len(chunks))
 	for i, chunk := range chunks {
-		fmt.Printf("DEBUG: Chunk %d: %q\n", i, chunk)
+		c.logger.Debug("chunk", "index", i, "data", chunk)
 	}
 
 	if len(chunks) == 0 {
@@ -362,8 +361,8 @@ func processChunks(chunks []string) ([]Response, error) {
 				// If it still fails, check if it contains wrb.fr and try to manually extract
 				if strings.Contains(chunk, rpcTypeWRB) {
 					// Manually construct a response
-					fmt.Printf("Attempting to manually extract wrb.fr response from: %s\n", chunk)
-					if resp := extractWRBResponse(chunk); resp != nil {
+					c.logger.Debug("extracting wrb.fr response manually", "chunk", chunk)
+					if resp := c.extractWRBResponse(chunk); resp != nil {
 						allResponses = append(allResponses, *resp)
 						continue
 					}
@@ -375,7 +374,7 @@ func processChunks(chunks []string) ([]Response, error) {
 		}
 
 		// Extract RPC responses from the chunk
-		responses, err := extractResponses(data)
+		responses, err := c.extractResponses(data)
 		if err != nil {
 			continue
 		}
@@ -393,7 +392,7 @@ func processChunks(chunks []string) ([]Response, error) {
 // extractResponses extracts Response objects from RPC data
 //
 //nolint:unused // retained for alternate chunked parsing
-func extractResponses(data [][]interface{}) ([]Response, error) {
+func (c *Client) extractResponses(data [][]interface{}) ([]Response, error) {
 	var responses []Response
 
 	for _, rpcData := range data {
@@ -431,7 +430,7 @@ func extractResponses(data [][]interface{}) ([]Response, error) {
 			}
 		} else {
 			// Data is null - this usually indicates an authentication issue or inaccessible resource
-			fmt.Printf("WARNING: Received null data for RPC %s - possible authentication issue\n", id)
+			c.logger.Warn("received null data for RPC, possible authentication issue", "rpc", id)
 		}
 
 		// Extract the response index
@@ -0,0 +1,91 @@
+package batchexecute
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"os"
+)
+
+// Middleware wraps the HTTP transport used for batchexecute requests.
+// It is the hook for tracing and metrics: an OpenTelemetry transport such as
+// otelhttp.NewTransport can be installed with WithMiddleware, and
+// RequestInfoFromContext exposes the RPC IDs and attempt for span attributes.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// RequestInfo describes the batchexecute request an HTTP round trip belongs to.
+type RequestInfo struct {
+	RPCIDs  []string // RPC endpoint IDs in the batch
+	ReqID   string   // _reqid query parameter
+	Attempt int      // 1-based attempt number, including retries
+}
+
+type requestInfoKey struct{}
+
+// RequestInfoFromContext returns the RequestInfo attached to an outgoing
+// request context by Execute.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+func withRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// WithLogger sets the structured logger used for request logging.
+// When unset, debug mode logs to stderr and logging is otherwise discarded.
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithMiddleware wraps the HTTP transport with the given middleware.
+// The first middleware is the outermost one.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// Logger returns the client's logger so layers built on top of the client
+// can share it.
+func (c *Client) Logger() *slog.Logger {
+	return c.logger
+}
+
+// DefaultLogger returns the logger used when none is configured: a stderr
+// text logger at debug level when debug is set, otherwise a discarding one.
+func DefaultLogger(debug bool) *slog.Logger {
+	if !debug {
+		return slog.New(slog.DiscardHandler)
+	}
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+// applyMiddleware installs the configured middleware on a copy of the HTTP
+// client so shared clients such as http.DefaultClient are left untouched.
+func (c *Client) applyMiddleware() {
+	if len(c.middleware) == 0 {
+		return
+	}
+	hc := *c.httpClient
+	rt := hc.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	hc.Transport = rt
+	c.httpClient = &hc
+}
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
@@ -0,0 +1,109 @@
+package batchexecute
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testOKBody = `)]}'
+123
+[[["wrb.fr","test","{\"result\":\"success\"}",null,null,null,"generic"]]]
+`
+
+func TestMiddlewareSeesRequestInfo(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(testOKBody))
+	}))
+	defer server.Close()
+
+	var infos []RequestInfo
+	mw := func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			info, ok := RequestInfoFromContext(req.Context())
+			if !ok {
+				t.Error("request context has no RequestInfo")
+			}
+			infos = append(infos, info)
+			return next.RoundTrip(req)
+		})
+	}
+
+	client := NewClient(Config{
+		Host:       server.URL[7:],
+		App:        "test",
+		RetryDelay: time.Millisecond,
+		UseHTTP:    true,
+	}, WithMiddleware(mw))
+
+	if _, err := client.Execute([]RPC{{ID: "test"}}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("middleware saw %d requests, want 2", len(infos))
+	}
+	for i, info := range infos {
+		if info.Attempt != i+1 {
+			t.Errorf("infos[%d].Attempt = %d, want %d", i, info.Attempt, i+1)
+		}
+		if len(info.RPCIDs) != 1 || info.RPCIDs[0] != "test" {
+			t.Errorf("infos[%d].RPCIDs = %v, want [test]", i, info.RPCIDs)
+		}
+		if info.ReqID == "" {
+			t.Errorf("infos[%d].ReqID is empty", i)
+		}
+	}
+	if http.DefaultClient.Transport != nil {
+		t.Error("WithMiddleware modified http.DefaultClient")
+	}
+}
+
+func TestExecuteLogsStructuredFields(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testOKBody))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	client := NewClient(Config{
+		Host:      server.URL[7:],
+		App:       "test",
+		AuthToken: "secret-auth-token-value",
+		UseHTTP:   true,
+	}, WithLogger(logger))
+
+	if _, err := client.Execute([]RPC{{ID: "test"}}); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"rpc=test", "reqid=", "attempt=1", "status=200", "duration=", "bytes="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret-auth-token-value") {
+		t.Error("log output contains the unmasked auth token")
+	}
+}
+
+func TestDefaultLoggerDiscardsWithoutDebug(t *testing.T) {
+	client := NewClient(Config{})
+	if client.Logger() == nil {
+		t.Fatal("Logger() = nil")
+	}
+	if client.Logger().Enabled(t.Context(), slog.LevelError) {
+		t.Error("default logger is enabled without debug")
+	}
+}
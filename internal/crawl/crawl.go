@@ -0,0 +1,213 @@
+// Package crawl discovers web pages to add to a notebook: by following
+// links from a start page, from sitemaps, and from RSS and Atom feeds.
+// Only the discovery happens locally; NotebookLM fetches the pages itself
+// when they are added.
+package crawl
+
+import (
+	"context"
+	"errors"
+	"html"
+	"log/slog"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Options limit a crawl.
+type Options struct {
+	// Depth is how many links away from the start page to go; 0 returns
+	// only the start page.
+	Depth int
+	// SameHost keeps the crawl on the start page's host.
+	SameHost bool
+	// Max caps the number of pages returned; 0 means no limit.
+	Max int
+}
+
+// Crawler discovers URLs, honoring each site's robots.txt.
+type Crawler struct {
+	Fetcher   Fetcher
+	UserAgent string
+	Logger    *slog.Logger
+
+	robots map[string]*Robots // by scheme://host
+}
+
+// New returns a Crawler that fetches with f.
+func New(f Fetcher) *Crawler {
+	return &Crawler{Fetcher: f, UserAgent: DefaultUserAgent}
+}
+
+func (c *Crawler) log() *slog.Logger {
+	if c.Logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return c.Logger
+}
+
+// ErrDisallowed is returned when robots.txt forbids fetching a URL.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// Allowed reports whether the site's robots.txt lets us fetch u. A missing
+// robots.txt allows everything; one that cannot be read because the
+// server fails forbids everything, as the robots.txt spec asks.
+func (c *Crawler) Allowed(ctx context.Context, u *url.URL) bool {
+	site := u.Scheme + "://" + u.Host
+	if c.robots == nil {
+		c.robots = map[string]*Robots{}
+	}
+	r, ok := c.robots[site]
+	if !ok {
+		page, err := c.Fetcher.Fetch(ctx, site+"/robots.txt")
+		var se *StatusError
+		switch {
+		case err == nil:
+			r = ParseRobots(page.Body, c.UserAgent)
+		case errors.As(err, &se) && se.Code >= 400 && se.Code < 500:
+			r = nil
+		default:
+			c.log().Debug("robots.txt unavailable, not crawling site", "site", site, "error", err)
+			r = &Robots{rules: []robotsRule{{pattern: "/"}}}
+		}
+		c.robots[site] = r
+	}
+	return r.Allowed(u.RequestURI())
+}
+
+// fetch fetches rawURL if robots.txt allows it.
+func (c *Crawler) fetch(ctx context.Context, rawURL string) (*Page, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if !c.Allowed(ctx, u) {
+		return nil, ErrDisallowed
+	}
+	return c.Fetcher.Fetch(ctx, rawURL)
+}
+
+// Crawl returns start and the pages reachable from it by following links,
+// breadth first, within opts. Pages that cannot be fetched or that
+// robots.txt forbids are left out.
+func (c *Crawler) Crawl(ctx context.Context, start string, opts Options) ([]string, error) {
+	startURL, err := url.Parse(start)
+	if err != nil {
+		return nil, err
+	}
+	if startURL.Scheme != "http" && startURL.Scheme != "https" {
+		return nil, errors.New("crawl: start URL must be http or https")
+	}
+	type item struct {
+		url   string
+		depth int
+	}
+	queue := []item{{Normalize(startURL.String()), 0}}
+	seen := map[string]bool{queue[0].url: true}
+	var found []string
+	for len(queue) > 0 && (opts.Max <= 0 || len(found) < opts.Max) {
+		if err := ctx.Err(); err != nil {
+			return found, err
+		}
+		it := queue[0]
+		queue = queue[1:]
+		page, err := c.fetch(ctx, it.url)
+		if err != nil {
+			c.log().Debug("skipping page", "url", it.url, "error", err)
+			continue
+		}
+		found = append(found, it.url)
+		if it.depth >= opts.Depth || !isHTML(page) {
+			continue
+		}
+		base, _ := url.Parse(page.URL)
+		for _, link := range Links(base, page.Body) {
+			if seen[link] {
+				continue
+			}
+			seen[link] = true
+			if u, _ := url.Parse(link); opts.SameHost && !strings.EqualFold(u.Host, startURL.Host) {
+				continue
+			}
+			queue = append(queue, item{link, it.depth + 1})
+		}
+	}
+	return found, nil
+}
+
+func isHTML(p *Page) bool {
+	ct := strings.ToLower(p.ContentType)
+	return strings.Contains(ct, "html") || (ct == "" && strings.Contains(strings.ToLower(string(p.Body[:min(len(p.Body), 512)])), "<html"))
+}
+
+var (
+	hrefAttr = regexp.MustCompile(`(?is)<a\s[^>]*?href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	baseHref = regexp.MustCompile(`(?is)<base\s[^>]*?href\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s>]+))`)
+	noFollow = regexp.MustCompile(`(?is)<meta\s[^>]*name\s*=\s*["']?robots["']?[^>]*content\s*=\s*["'][^"']*nofollow`)
+)
+
+// skipExt lists extensions of links that are not pages worth adding.
+var skipExt = map[string]bool{
+	".css": true, ".js": true, ".json": true, ".xml": true, ".rss": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".svg": true, ".webp": true, ".ico": true,
+	".zip": true, ".gz": true, ".tar": true, ".dmg": true, ".exe": true,
+	".mp3": true, ".mp4": true, ".woff": true, ".woff2": true,
+}
+
+// Links returns the http(s) links of an HTML page, resolved against base
+// (or the page's <base href>), normalized and without duplicates. Pages
+// marked nofollow yield no links.
+func Links(base *url.URL, body []byte) []string {
+	if noFollow.Match(body) {
+		return nil
+	}
+	if m := baseHref.FindSubmatch(body); m != nil {
+		if b, err := base.Parse(html.UnescapeString(firstGroup(m))); err == nil {
+			base = b
+		}
+	}
+	var links []string
+	seen := map[string]bool{}
+	for _, m := range hrefAttr.FindAllSubmatch(body, -1) {
+		u, err := base.Parse(strings.TrimSpace(html.UnescapeString(firstGroup(m))))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			continue
+		}
+		if skipExt[strings.ToLower(path.Ext(u.Path))] {
+			continue
+		}
+		link := Normalize(u.String())
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+func firstGroup(m [][]byte) string {
+	for _, g := range m[1:] {
+		if g != nil {
+			return string(g)
+		}
+	}
+	return ""
+}
+
+// Normalize drops the fragment of rawURL and lowercases its scheme and
+// host, so that links to the same page compare equal.
+func Normalize(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
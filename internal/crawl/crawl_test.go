@@ -0,0 +1,198 @@
+package crawl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// site serves a few linked pages, a robots.txt, sitemaps and a feed.
+func site(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	var srv *httptest.Server
+	page := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, strings.ReplaceAll(body, "{{host}}", srv.URL))
+		}
+	}
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	})
+	mux.Handle("/{$}", page(`<html><a href="/a">A</a> <a href='b#top'>B</a> <a href=/private/x>P</a>
+		<a href="https://elsewhere.example/">Out</a> <a href="/style.css">css</a> <a href="mailto:x@example.com">mail</a>`))
+	mux.Handle("/a", page(`<html><a href="/a/deep">Deep</a><a href="/">Home</a>`))
+	mux.Handle("/b", page(`<html><head><meta name="robots" content="noindex, nofollow"></head><a href="/hidden">H</a>`))
+	mux.Handle("/a/deep", page(`<html><a href="/deeper">Deeper</a>`))
+	mux.Handle("/private/x", page(`<html>secret`))
+	mux.HandleFunc("/sitemap-index.xml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<sitemap><loc>%s/sitemap-1.xml.gz</loc></sitemap>
+			<sitemap><loc>%s/private/sitemap.xml</loc></sitemap>
+		</sitemapindex>`, srv.URL, srv.URL)
+	})
+	mux.HandleFunc("/sitemap-1.xml.gz", func(w http.ResponseWriter, r *http.Request) {
+		zw := gzip.NewWriter(w)
+		fmt.Fprint(zw, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+			<url><loc>https://example.com/one</loc><lastmod>2026-01-02</lastmod></url>
+			<url><loc> https://example.com/two </loc></url>
+		</urlset>`)
+		zw.Close()
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCrawl(t *testing.T) {
+	srv := site(t)
+	c := New(&HTTPFetcher{Client: srv.Client()})
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		opts Options
+		want []string
+	}{
+		{"start only", Options{}, []string{"/"}},
+		{"depth 1", Options{Depth: 1, SameHost: true}, []string{"/", "/a", "/b"}},
+		{"depth 2", Options{Depth: 2, SameHost: true}, []string{"/", "/a", "/b", "/a/deep"}},
+		{"max", Options{Depth: 2, SameHost: true, Max: 2}, []string{"/", "/a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Crawl(ctx, srv.URL, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want []string
+			for _, p := range tt.want {
+				want = append(want, srv.URL+p)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Crawl = %v, want %v", got, want)
+			}
+		})
+	}
+
+	if _, err := c.Crawl(ctx, "ftp://example.com/", Options{}); err == nil {
+		t.Error("expected error for a non-HTTP start URL")
+	}
+}
+
+// stubFetcher serves canned pages and records what was fetched.
+type stubFetcher struct {
+	pages   map[string]*Page
+	fetched []string
+}
+
+func (f *stubFetcher) Fetch(ctx context.Context, u string) (*Page, error) {
+	f.fetched = append(f.fetched, u)
+	if p, ok := f.pages[u]; ok {
+		return p, nil
+	}
+	if strings.HasSuffix(u, "/robots.txt") && strings.Contains(u, "down.example") {
+		return nil, &StatusError{URL: u, Code: http.StatusServiceUnavailable}
+	}
+	return nil, &StatusError{URL: u, Code: http.StatusNotFound}
+}
+
+func TestCrawlOtherHostsAndRobotsFailures(t *testing.T) {
+	html := func(body string) *Page { return &Page{ContentType: "text/html", Body: []byte(body)} }
+	f := &stubFetcher{pages: map[string]*Page{
+		"https://start.example/":    html(`<a href="https://open.example/page">o</a><a href="https://down.example/page">d</a>`),
+		"https://open.example/page": html(`open`),
+		"https://down.example/page": html(`down`),
+	}}
+	for k, p := range f.pages {
+		p.URL = k
+	}
+	got, err := New(f).Crawl(context.Background(), "https://start.example", Options{Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"https://start.example/", "https://open.example/page"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Crawl = %v, want %v (a site whose robots.txt fails is skipped)", got, want)
+	}
+	for _, u := range f.fetched {
+		if u == "https://down.example/page" {
+			t.Error("fetched a page on a site whose robots.txt failed")
+		}
+	}
+}
+
+func TestLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/docs/index.html")
+	body := []byte(`<base href="https://example.com/v2/"><A HREF="guide">g</A><a class="x" href="/Guide#s">G</a>
+		<a href="HTTPS://EXAMPLE.com/v2/guide#again">dup</a><a href="javascript:void(0)">js</a><a href="logo.PNG">img</a>
+		<a href="?q=a&amp;b=c">query</a>`)
+	got := Links(base, body)
+	want := []string{"https://example.com/v2/guide", "https://example.com/Guide", "https://example.com/v2/?q=a&b=c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Links = %v, want %v", got, want)
+	}
+}
+
+func TestSitemap(t *testing.T) {
+	srv := site(t)
+	c := New(&HTTPFetcher{Client: srv.Client()})
+	entries, err := c.Sitemap(context.Background(), srv.URL+"/sitemap-index.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{
+		{URL: "https://example.com/one", Modified: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{URL: "https://example.com/two"},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("Sitemap = %+v, want %+v", entries, want)
+	}
+
+	if _, err := c.Sitemap(context.Background(), srv.URL+"/private/sitemap.xml"); !errors.Is(err, ErrDisallowed) {
+		t.Errorf("Sitemap of a disallowed URL: err = %v, want ErrDisallowed", err)
+	}
+	if _, _, err := ParseSitemap([]byte(`<rss></rss>`)); err == nil {
+		t.Error("expected error for a non-sitemap document")
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	fmt.Fprint(zw, `<urlset><url><loc>https://example.com/z</loc></url></urlset>`)
+	zw.Close()
+	if got, err := c.ExpandSitemap(context.Background(), buf.Bytes()); err != nil || len(got) != 1 {
+		t.Errorf("ExpandSitemap(gzip) = %+v, %v", got, err)
+	}
+}
+
+func TestFeedSince(t *testing.T) {
+	f := &stubFetcher{pages: map[string]*Page{
+		"https://example.com/robots.txt": {Body: []byte("User-agent: *\nAllow: /\n")},
+		"https://example.com/feed": {Body: []byte(`<rss><channel>
+			<item><link>https://example.com/new</link><pubDate>Sun, 01 Mar 2026 00:00:00 +0000</pubDate></item>
+			<item><link>https://example.com/old</link><pubDate>Sun, 01 Feb 2026 00:00:00 +0000</pubDate></item>
+			<item><link>https://example.com/undated</link></item>
+		</channel></rss>`)},
+	}}
+	got, err := New(f).Feed(context.Background(), "https://example.com/feed", time.Date(2026, 2, 15, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, e := range got {
+		urls = append(urls, e.URL)
+	}
+	if want := []string{"https://example.com/new", "https://example.com/undated"}; !reflect.DeepEqual(urls, want) {
+		t.Errorf("Feed = %v, want %v", urls, want)
+	}
+}
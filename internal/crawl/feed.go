@@ -0,0 +1,119 @@
+package crawl
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// feedDoc covers RSS 2.0, RSS 1.0 (RDF) and Atom; only the fields of the
+// format at hand are filled in.
+type feedDoc struct {
+	XMLName xml.Name
+	// RSS 2.0 puts items in <channel>, RSS 1.0 at the top level.
+	Channel struct {
+		Items []feedItem `xml:"item"`
+	} `xml:"channel"`
+	Items   []feedItem `xml:"item"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+type feedItem struct {
+	Title   string `xml:"title"`
+	Link    string `xml:"link"`
+	GUID    string `xml:"guid"`
+	PubDate string `xml:"pubDate"`
+	Date    string `xml:"date"` // dc:date
+}
+
+// ParseFeed returns the entries of an RSS or Atom feed, newest first when
+// the feed gives dates.
+func ParseFeed(data []byte) ([]Entry, error) {
+	var doc feedDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse feed: %w", err)
+	}
+	var entries []Entry
+	switch doc.XMLName.Local {
+	case "rss", "RDF":
+		for _, it := range append(doc.Channel.Items, doc.Items...) {
+			link := strings.TrimSpace(it.Link)
+			if link == "" && strings.HasPrefix(it.GUID, "http") {
+				link = strings.TrimSpace(it.GUID)
+			}
+			if link == "" {
+				continue
+			}
+			date := it.PubDate
+			if date == "" {
+				date = it.Date
+			}
+			entries = append(entries, Entry{URL: link, Title: strings.TrimSpace(it.Title), Modified: parseTime(date)})
+		}
+	case "feed":
+		for _, e := range doc.Entries {
+			var link string
+			for _, l := range e.Links {
+				if l.Rel == "" || l.Rel == "alternate" {
+					link = strings.TrimSpace(l.Href)
+					break
+				}
+			}
+			if link == "" {
+				continue
+			}
+			date := e.Updated
+			if date == "" {
+				date = e.Published
+			}
+			entries = append(entries, Entry{URL: link, Title: strings.TrimSpace(e.Title), Modified: parseTime(date)})
+		}
+	default:
+		return nil, fmt.Errorf("parse feed: unexpected <%s> document", doc.XMLName.Local)
+	}
+	sortNewestFirst(entries)
+	return entries, nil
+}
+
+// Feed fetches the feed at url and returns the entries modified since the
+// given time. Entries without a date are kept, since their age is unknown.
+func (c *Crawler) Feed(ctx context.Context, url string, since time.Time) ([]Entry, error) {
+	page, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch feed: %w", err)
+	}
+	entries, err := ParseFeed(page.Body)
+	if err != nil {
+		return nil, err
+	}
+	var recent []Entry
+	for _, e := range entries {
+		if e.Modified.IsZero() || !e.Modified.Before(since) {
+			recent = append(recent, e)
+		}
+	}
+	return recent, nil
+}
+
+// sortNewestFirst orders dated entries newest first and keeps undated
+// ones, in feed order, after them.
+func sortNewestFirst(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i].Modified, entries[j].Modified
+		if b.IsZero() {
+			return !a.IsZero()
+		}
+		return a.After(b)
+	})
+}
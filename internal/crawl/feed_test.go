@@ -0,0 +1,71 @@
+package crawl
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseFeed(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []string
+	}{
+		{
+			name: "rss",
+			data: `<?xml version="1.0"?><rss version="2.0"><channel><title>Blog</title>
+				<item><title>Old</title><link>https://example.com/old</link><pubDate>Mon, 02 Jan 2006 15:04:05 +0000</pubDate></item>
+				<item><title>Undated</title><guid>https://example.com/undated</guid></item>
+				<item><title>New</title><link>https://example.com/new</link><pubDate>Tue, 03 Jan 2006 15:04:05 GMT</pubDate></item>
+				<item><title>No link</title></item>
+			</channel></rss>`,
+			want: []string{"https://example.com/new", "https://example.com/old", "https://example.com/undated"},
+		},
+		{
+			name: "atom",
+			data: `<feed xmlns="http://www.w3.org/2005/Atom"><title>Videos</title>
+				<entry><title>A</title><link rel="self" href="https://example.com/a.xml"/><link rel="alternate" href="https://www.youtube.com/watch?v=a"/><published>2026-01-01T00:00:00Z</published></entry>
+				<entry><title>B</title><link href="https://www.youtube.com/watch?v=b"/><updated>2026-02-01T00:00:00Z</updated></entry>
+			</feed>`,
+			want: []string{"https://www.youtube.com/watch?v=b", "https://www.youtube.com/watch?v=a"},
+		},
+		{
+			name: "rdf",
+			data: `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+				<item><title>R</title><link>https://example.com/r</link><dc:date>2026-03-01</dc:date></item>
+			</rdf:RDF>`,
+			want: []string{"https://example.com/r"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := ParseFeed([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.URL)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseFeed URLs = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := ParseFeed([]byte(`<html></html>`)); err == nil {
+		t.Error("expected error for a non-feed document")
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	for _, s := range []string{"2026-03-01", "2026-03-01T10:00:00+02:00", "Sun, 01 Mar 2026 08:00:00 GMT", "1 Mar 2026 08:00:00 +0000"} {
+		if got := parseTime(s); got.Year() != 2026 || got.Month() != time.March {
+			t.Errorf("parseTime(%q) = %v", s, got)
+		}
+	}
+	if !parseTime("yesterday").IsZero() {
+		t.Error("parseTime should return the zero time for unknown formats")
+	}
+}
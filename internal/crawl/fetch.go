@@ -0,0 +1,82 @@
+package crawl
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// DefaultUserAgent identifies nlm to the sites it reads.
+const DefaultUserAgent = "nlm (+https://github.com/tmc/nlm)"
+
+// Page is a fetched document.
+type Page struct {
+	URL         string // after redirects
+	ContentType string
+	Body        []byte
+}
+
+// Fetcher retrieves documents. HTTPFetcher is the real implementation;
+// tests can point one at an httptest.Server or supply their own.
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (*Page, error)
+}
+
+// StatusError is returned by HTTPFetcher for responses other than 2xx.
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("fetch %s: %s", e.URL, http.StatusText(e.Code))
+}
+
+// HTTPFetcher fetches documents over HTTP.
+type HTTPFetcher struct {
+	Client    *http.Client // nil means a client with a 30s timeout
+	UserAgent string       // empty means DefaultUserAgent
+	MaxBytes  int64        // bodies are cut at this size; 0 means 10 MiB
+}
+
+var defaultClient = &http.Client{Timeout: 30 * time.Second}
+
+// Fetch implements Fetcher.
+func (f *HTTPFetcher) Fetch(ctx context.Context, url string) (*Page, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	ua := f.UserAgent
+	if ua == "" {
+		ua = DefaultUserAgent
+	}
+	req.Header.Set("User-Agent", ua)
+	client := f.Client
+	if client == nil {
+		client = defaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &StatusError{URL: url, Code: resp.StatusCode}
+	}
+	limit := f.MaxBytes
+	if limit <= 0 {
+		limit = 10 << 20
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", url, err)
+	}
+	return &Page{
+		URL:         resp.Request.URL.String(),
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        body,
+	}, nil
+}
@@ -0,0 +1,111 @@
+package crawl
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+// Robots holds the robots.txt rules that apply to one user agent.
+type Robots struct {
+	rules []robotsRule
+}
+
+type robotsRule struct {
+	pattern string
+	allow   bool
+}
+
+// ParseRobots parses a robots.txt file and keeps the rules of the group
+// that names agent, or of the "*" group if none does. Agent matching
+// uses the product token, e.g. "nlm" in "nlm (+https://...)".
+func ParseRobots(data []byte, agent string) *Robots {
+	token := strings.ToLower(strings.Fields(agent + " *")[0])
+	var specific, wildcard []robotsRule
+	var agents []string
+	inRules := false // whether the current group has started listing rules
+	haveSpecific := false
+
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+		switch key {
+		case "user-agent":
+			if inRules {
+				agents, inRules = nil, false
+			}
+			agents = append(agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				// "Disallow:" with no path allows everything.
+				continue
+			}
+			r := robotsRule{pattern: value, allow: key == "allow"}
+			for _, a := range agents {
+				switch {
+				case a == "*":
+					wildcard = append(wildcard, r)
+				case a == token:
+					specific = append(specific, r)
+					haveSpecific = true
+				}
+			}
+		}
+	}
+	if haveSpecific {
+		return &Robots{rules: specific}
+	}
+	return &Robots{rules: wildcard}
+}
+
+// Allowed reports whether path (with any query) may be fetched. The most
+// specific matching rule wins; allow wins ties.
+func (r *Robots) Allowed(path string) bool {
+	if r == nil {
+		return true
+	}
+	if path == "" {
+		path = "/"
+	}
+	best, allowed := -1, true
+	for _, rule := range r.rules {
+		if !robotsMatch(rule.pattern, path) {
+			continue
+		}
+		if n := len(rule.pattern); n > best || (n == best && rule.allow) {
+			best, allowed = n, rule.allow
+		}
+	}
+	return allowed
+}
+
+// robotsMatch matches path against a robots.txt pattern, where * matches
+// any run of characters and a trailing $ anchors the end.
+func robotsMatch(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(path, parts[0]) {
+		return false
+	}
+	rest := path[len(parts[0]):]
+	for _, p := range parts[1:] {
+		i := strings.Index(rest, p)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(p):]
+	}
+	if anchored {
+		last := parts[len(parts)-1]
+		return rest == "" || (len(parts) > 1 && strings.HasSuffix(path, last))
+	}
+	return true
+}
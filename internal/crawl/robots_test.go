@@ -0,0 +1,43 @@
+package crawl
+
+import "testing"
+
+func TestRobots(t *testing.T) {
+	data := []byte(`# comment
+User-agent: Googlebot
+Disallow: /
+
+User-agent: *
+Disallow: /private/
+Allow: /private/public-*.html$
+Disallow: /*.pdf$
+Disallow:
+
+User-agent: nlm
+User-agent: other
+Disallow: /drafts
+`)
+	tests := []struct {
+		agent, path string
+		want        bool
+	}{
+		{"curl/8", "/", true},
+		{"curl/8", "/private/x", false},
+		{"curl/8", "/private/public-1.html", true},
+		{"curl/8", "/private/public-1.html?x", false},
+		{"curl/8", "/docs/a.pdf", false},
+		{"curl/8", "/docs/a.pdf.html", true},
+		{"curl/8", "/drafts/1", true},
+		{DefaultUserAgent, "/drafts/1", false},
+		{DefaultUserAgent, "/private/x", true}, // its own group replaces "*"
+	}
+	for _, tt := range tests {
+		if got := ParseRobots(data, tt.agent).Allowed(tt.path); got != tt.want {
+			t.Errorf("ParseRobots(%q).Allowed(%q) = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+	var none *Robots
+	if !none.Allowed("/anything") {
+		t.Error("nil Robots should allow everything")
+	}
+}
@@ -0,0 +1,136 @@
+package crawl
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Entry is a URL listed by a sitemap or feed.
+type Entry struct {
+	URL      string
+	Title    string    // feeds only
+	Modified time.Time // zero if not given
+}
+
+// maxSitemapDepth bounds how deeply sitemap indexes may nest.
+const maxSitemapDepth = 3
+
+type sitemapDoc struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc     string `xml:"loc"`
+		LastMod string `xml:"lastmod"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// ParseSitemap parses a sitemap (a <urlset>) or sitemap index (a
+// <sitemapindex>), gzipped or not. It returns the page entries and the
+// locations of child sitemaps.
+func ParseSitemap(data []byte) (entries []Entry, children []string, err error) {
+	if data, err = gunzip(data); err != nil {
+		return nil, nil, err
+	}
+	var doc sitemapDoc
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, nil, fmt.Errorf("parse sitemap: %w", err)
+	}
+	switch doc.XMLName.Local {
+	case "urlset", "sitemapindex":
+	default:
+		return nil, nil, fmt.Errorf("parse sitemap: unexpected <%s> document", doc.XMLName.Local)
+	}
+	for _, u := range doc.URLs {
+		if loc := strings.TrimSpace(u.Loc); loc != "" {
+			entries = append(entries, Entry{URL: loc, Modified: parseTime(u.LastMod)})
+		}
+	}
+	for _, s := range doc.Sitemaps {
+		if loc := strings.TrimSpace(s.Loc); loc != "" {
+			children = append(children, loc)
+		}
+	}
+	return entries, children, nil
+}
+
+// Sitemap fetches the sitemap at url and returns its entries, following
+// sitemap indexes.
+func (c *Crawler) Sitemap(ctx context.Context, url string) ([]Entry, error) {
+	page, err := c.fetch(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch sitemap: %w", err)
+	}
+	return c.ExpandSitemap(ctx, page.Body)
+}
+
+// ExpandSitemap parses a sitemap already in hand, such as a local file,
+// and fetches any child sitemaps it lists.
+func (c *Crawler) ExpandSitemap(ctx context.Context, data []byte) ([]Entry, error) {
+	return c.expandSitemap(ctx, data, 0, map[string]bool{})
+}
+
+func (c *Crawler) expandSitemap(ctx context.Context, data []byte, depth int, seen map[string]bool) ([]Entry, error) {
+	entries, children, err := ParseSitemap(data)
+	if err != nil {
+		return nil, err
+	}
+	for _, child := range children {
+		if seen[child] || depth >= maxSitemapDepth {
+			continue
+		}
+		seen[child] = true
+		page, err := c.fetch(ctx, child)
+		if err != nil {
+			c.log().Debug("skipping sitemap", "url", child, "error", err)
+			continue
+		}
+		more, err := c.expandSitemap(ctx, page.Body, depth+1, seen)
+		if err != nil {
+			c.log().Debug("skipping sitemap", "url", child, "error", err)
+			continue
+		}
+		entries = append(entries, more...)
+	}
+	return entries, nil
+}
+
+// gunzip decompresses data if it is gzipped.
+func gunzip(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return data, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(io.LimitReader(zr, 50<<20))
+}
+
+// parseTime accepts the date formats used by sitemaps and feeds.
+func parseTime(s string) time.Time {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{
+		time.RFC3339,
+		"2006-01-02T15:04Z07:00",
+		"2006-01-02",
+		time.RFC1123Z,
+		time.RFC1123,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		"2 Jan 2006 15:04:05 -0700",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
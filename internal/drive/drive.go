@@ -0,0 +1,99 @@
+// Package drive recognizes the Google Docs, Slides and Sheets files that
+// NotebookLM can add as native Drive sources.
+package drive
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+// File is a Google Docs, Slides or Sheets file in Google Drive.
+type File struct {
+	ID   string
+	Type pb.SourceType // SOURCE_TYPE_GOOGLE_DOCS, _SLIDES or _SHEETS
+}
+
+// MIME types of the Drive files NotebookLM can add natively.
+const (
+	mimeGoogleDocs   = "application/vnd.google-apps.document"
+	mimeGoogleSlides = "application/vnd.google-apps.presentation"
+	mimeGoogleSheets = "application/vnd.google-apps.spreadsheet"
+)
+
+// MIMEType returns the Drive MIME type of the file.
+func (f File) MIMEType() string {
+	switch f.Type {
+	case pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES:
+		return mimeGoogleSlides
+	case pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS:
+		return mimeGoogleSheets
+	}
+	return mimeGoogleDocs
+}
+
+// IsSourceType reports whether t is a Google Docs, Slides or Sheets
+// source, which NotebookLM can re-sync from Drive.
+func IsSourceType(t pb.SourceType) bool {
+	switch t {
+	case pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS, pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES, pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS:
+		return true
+	}
+	return false
+}
+
+// prefixes name the file type of a file ID, as in "slides:<id>".
+var prefixes = map[string]pb.SourceType{
+	"docs":   pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS,
+	"slides": pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES,
+	"sheets": pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS,
+}
+
+// fileID matches a Drive file ID. Real IDs are 25 to 44 characters long.
+var fileID = regexp.MustCompile(`^[A-Za-z0-9_-]{25,}$`)
+
+// ParseRef recognizes a Google Docs, Slides or Sheets file given as an
+// editor URL or as "docs:<id>", "slides:<id>" or "sheets:<id>". A file ID
+// without a prefix is not recognized: it cannot be told apart from text.
+func ParseRef(s string) (File, bool) {
+	s = strings.TrimSpace(s)
+	if f, ok := ParseURL(s); ok {
+		return f, true
+	}
+	prefix, id, ok := strings.Cut(s, ":")
+	if typ, known := prefixes[prefix]; ok && known && fileID.MatchString(id) {
+		return File{ID: id, Type: typ}, true
+	}
+	return File{}, false
+}
+
+// ParseURL handles docs.google.com/{document,presentation,spreadsheets}/d/<id>
+// URLs. drive.google.com/file/d/<id> and /open?id=<id> links are not
+// recognized: they do not say what kind of file they point to, and are
+// usually uploaded PDFs or Office files rather than Google Docs.
+func ParseURL(s string) (File, bool) {
+	u, err := url.Parse(s)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.EqualFold(u.Host, "docs.google.com") {
+		return File{}, false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	typ, ok := map[string]pb.SourceType{
+		"document":     pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS,
+		"presentation": pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES,
+		"spreadsheets": pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS,
+	}[parts[0]]
+	if !ok {
+		return File{}, false
+	}
+	rest := parts[1:]
+	// Skip the /u/<n>/ account selector.
+	if len(rest) > 2 && rest[0] == "u" {
+		rest = rest[2:]
+	}
+	if len(rest) > 1 && rest[0] == "d" && fileID.MatchString(rest[1]) {
+		return File{ID: rest[1], Type: typ}, true
+	}
+	return File{}, false
+}
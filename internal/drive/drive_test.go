@@ -0,0 +1,61 @@
+package drive
+
+import (
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+func TestParseRef(t *testing.T) {
+	const id = "1AbCdEfGhIjKlMnOpQrStUvWxYz0123456789_-"
+	docs, slides, sheets := pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS, pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES, pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS
+	tests := []struct {
+		in   string
+		want File
+		ok   bool
+	}{
+		{"https://docs.google.com/document/d/" + id + "/edit", File{id, docs}, true},
+		{"https://docs.google.com/document/u/1/d/" + id + "/edit?tab=t.0", File{id, docs}, true},
+		{"https://docs.google.com/presentation/d/" + id + "/edit#slide=id.p", File{id, slides}, true},
+		{"https://docs.google.com/spreadsheets/d/" + id + "/edit#gid=0", File{id, sheets}, true},
+		{"docs:" + id, File{id, docs}, true},
+		{"slides:" + id, File{id, slides}, true},
+		{"sheets:" + id, File{id, sheets}, true},
+		// Drive file links may be PDFs or Office files; they stay URLs.
+		{"https://drive.google.com/file/d/" + id + "/view", File{}, false},
+		{"https://drive.google.com/open?id=" + id, File{}, false},
+		// Bare tokens are text, however much they look like IDs.
+		{id, File{}, false},
+		{"123e4567-e89b-12d3-a456-426614174000", File{}, false},
+		{"docs:short", File{}, false},
+		{"https://docs.google.com/document/d/123/edit", File{}, false},
+		{"https://docs.google.com/forms/d/" + id + "/edit", File{}, false},
+		{"https://example.com/document/d/" + id, File{}, false},
+		{"video:" + id, File{}, false},
+		{"notes.md", File{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseRef(tt.in)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseRef(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFileMIMEType(t *testing.T) {
+	for typ, want := range map[pb.SourceType]string{
+		pb.SourceType_SOURCE_TYPE_GOOGLE_DOCS:   "application/vnd.google-apps.document",
+		pb.SourceType_SOURCE_TYPE_GOOGLE_SLIDES: "application/vnd.google-apps.presentation",
+		pb.SourceType_SOURCE_TYPE_GOOGLE_SHEETS: "application/vnd.google-apps.spreadsheet",
+	} {
+		if got := (File{ID: "x", Type: typ}).MIMEType(); got != want {
+			t.Errorf("MIMEType(%v) = %q, want %q", typ, got, want)
+		}
+		if !IsSourceType(typ) {
+			t.Errorf("IsSourceType(%v) = false", typ)
+		}
+	}
+	if IsSourceType(pb.SourceType_SOURCE_TYPE_WEB_PAGE) {
+		t.Error("IsSourceType(WEB_PAGE) = true")
+	}
+}
@@ -0,0 +1,251 @@
+package repo
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tmc/nlm/internal/api"
+	"github.com/tmc/nlm/internal/split"
+)
+
+// Bundle is the content of one source made from the repository.
+type Bundle struct {
+	Title   string // unique within the repository; also the state key
+	Content string
+	Files   int
+}
+
+// Hash returns the SHA-256 of the bundle's content.
+func (b Bundle) Hash() string {
+	sum := sha256.Sum256([]byte(b.Content))
+	return hex.EncodeToString(sum[:])
+}
+
+// Options control how files are bundled.
+type Options struct {
+	// Include, if set, keeps only files whose name or path matches one of
+	// these path.Match patterns.
+	Include []string
+	// Limits bound each bundle; directories over them are split by file.
+	Limits split.Limits
+	// MaxBundles caps the number of bundles, before any directory is split
+	// into parts. Directories are folded into their parents, deepest first,
+	// until the bundles fit. 0 means no limit.
+	MaxBundles int
+}
+
+// Skipped is a file left out of the bundles, with the reason.
+type Skipped struct {
+	Path   string
+	Reason string
+}
+
+// Skip reasons.
+const (
+	SkipBinary   = "binary"
+	SkipTooLarge = "too large"
+	SkipNoise    = "lock or checksum file"
+)
+
+// noiseFiles are generated files that say nothing about the code.
+var noiseFiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Cargo.lock": true, "poetry.lock": true, "Gemfile.lock": true, "composer.lock": true,
+}
+
+// IsReadme reports whether p is the repository's top-level README.
+func IsReadme(p string) bool {
+	return !strings.Contains(p, "/") && strings.HasPrefix(strings.ToUpper(p), "README")
+}
+
+// Bundles groups files into Markdown bundles, one per directory, each file
+// under a heading with its path. The top-level README becomes a bundle of
+// its own, whatever opts.Include says, as does commits if it is not empty.
+// Bundles are returned in title order.
+func Bundles(name string, files []File, commits string, opts Options) ([]Bundle, []Skipped) {
+	var skipped []Skipped
+	var readme *File
+	groups := map[string][]File{}
+	for i := range files {
+		f := files[i]
+		readmeFile := IsReadme(f.Path) && readme == nil
+		if !readmeFile && !matches(opts.Include, f.Path) {
+			continue
+		}
+		if reason := skipReason(f, opts.Limits); reason != "" {
+			skipped = append(skipped, Skipped{f.Path, reason})
+			continue
+		}
+		if readmeFile {
+			readme = &files[i]
+			continue
+		}
+		dir := path.Dir(f.Path)
+		groups[dir] = append(groups[dir], f)
+	}
+	if opts.MaxBundles > 0 {
+		n := opts.MaxBundles
+		if readme != nil {
+			n--
+		}
+		if commits != "" {
+			n--
+		}
+		foldGroups(groups, max(n, 1))
+	}
+
+	var bundles []Bundle
+	if readme != nil {
+		bundles = append(bundles, Bundle{Title: name + "/" + readme.Path, Content: string(readme.Data), Files: 1})
+	}
+	if commits != "" {
+		bundles = append(bundles, Bundle{Title: name + ": recent commits", Content: commits})
+	}
+	for dir, fs := range groups {
+		bundles = append(bundles, dirBundles(name, dir, fs, opts.Limits)...)
+	}
+	sort.Slice(bundles, func(i, j int) bool { return bundles[i].Title < bundles[j].Title })
+	return bundles, skipped
+}
+
+func matches(patterns []string, p string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pat := range patterns {
+		if ok, _ := path.Match(pat, path.Base(p)); ok {
+			return true
+		}
+		if ok, _ := path.Match(pat, p); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func skipReason(f File, l split.Limits) string {
+	switch {
+	case noiseFiles[path.Base(f.Path)]:
+		return SkipNoise
+	case f.Data == nil && f.Size > 0:
+		return SkipTooLarge
+	case bytes.IndexByte(f.Data, 0) >= 0 || !utf8.Valid(f.Data):
+		return SkipBinary
+	case !l.FitsText(string(f.Data)):
+		return SkipTooLarge
+	}
+	return ""
+}
+
+// foldGroups merges directories into their parents, deepest and then
+// smallest first, until there are at most limit of them.
+func foldGroups(groups map[string][]File, limit int) {
+	for len(groups) > limit {
+		var deepest string
+		for dir := range groups {
+			if dir == "." {
+				continue
+			}
+			if deepest == "" || deeper(dir, deepest, groups) {
+				deepest = dir
+			}
+		}
+		if deepest == "" {
+			return
+		}
+		parent := path.Dir(deepest)
+		groups[parent] = append(groups[parent], groups[deepest]...)
+		delete(groups, deepest)
+	}
+}
+
+// deeper reports whether directory a should be folded before b.
+func deeper(a, b string, groups map[string][]File) bool {
+	da, db := strings.Count(a, "/"), strings.Count(b, "/")
+	if da != db {
+		return da > db
+	}
+	if la, lb := len(groups[a]), len(groups[b]); la != lb {
+		return la < lb
+	}
+	return a < b
+}
+
+// dirBundles renders the files of one directory, split into parts if they
+// do not fit in one source.
+func dirBundles(name, dir string, files []File, l split.Limits) []Bundle {
+	sortFiles(files)
+	title := name + "/"
+	if dir != "." {
+		title += dir + "/"
+	}
+	header := "# " + title + "\n\n"
+
+	// Sections end in a newline, so the size of a part is the sum of its
+	// sections' sizes and is kept as a running total.
+	var parts []string
+	var counts []int
+	var cur strings.Builder
+	headerWords := split.Words(header)
+	n, words, size := 0, 0, int64(0)
+	flush := func() {
+		parts, counts = append(parts, cur.String()), append(counts, n)
+		cur.Reset()
+		n = 0
+	}
+	for _, f := range files {
+		section, err := api.CodeSection(f.Path, f.Data)
+		if err != nil {
+			continue
+		}
+		section += "\n"
+		w, sz := split.Words(section), int64(len(section))
+		if n > 0 && !l.Fits(words+w, size+sz) {
+			flush()
+		}
+		if n == 0 {
+			cur.WriteString(header)
+			words, size = headerWords, int64(len(header))
+		}
+		cur.WriteString(section)
+		words, size = words+w, size+sz
+		n++
+	}
+	if n > 0 {
+		flush()
+	}
+
+	titles := split.Titles(title, len(parts))
+	bundles := make([]Bundle, len(parts))
+	for i := range parts {
+		bundles[i] = Bundle{Title: titles[i], Content: parts[i], Files: counts[i]}
+	}
+	return bundles
+}
+
+// sortFiles orders files by path.
+func sortFiles(files []File) {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+}
+
+// Describe summarizes skipped files by reason, e.g.
+// "3 binary, 1 too large".
+func Describe(skipped []Skipped) string {
+	counts := map[string]int{}
+	for _, s := range skipped {
+		counts[s.Reason]++
+	}
+	var parts []string
+	for _, reason := range []string{SkipBinary, SkipTooLarge, SkipNoise} {
+		if n := counts[reason]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, reason))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,239 @@
+// Package repo turns a git repository into notebook sources: one Markdown
+// bundle per directory with a header for each file, plus the README and a
+// summary of recent commits. Bundles are hashed so that re-running only
+// replaces the sources whose content changed.
+package repo
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrNoGit is returned when the git command is not installed.
+var ErrNoGit = errors.New("git is not installed")
+
+// File is a file of the repository.
+type File struct {
+	Path string // slash-separated, relative to the checkout directory
+	Size int64
+	Data []byte // nil if the file was over the size limit
+}
+
+// Checkout is a local view of a repository: a directory inside a git work
+// tree, and optionally the ref to read files at instead of the work tree.
+type Checkout struct {
+	Dir  string
+	Ref  string
+	Name string // short name for titles, e.g. "nlm"
+	// ID identifies the repository across runs: its URL or absolute path,
+	// and the ref.
+	ID string
+
+	temp string // clone directory to remove on Close
+}
+
+// IsRemote reports whether s is a git URL rather than a local path.
+func IsRemote(s string) bool {
+	for _, prefix := range []string{"https://", "http://", "ssh://", "git://", "git@", "file://"} {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Open returns a Checkout for target, a local path or a git URL. URLs are
+// cloned, shallowly, into a temporary directory that Close removes; for
+// them ref must name a branch or tag. For local paths any ref git accepts
+// works, and an empty ref reads the work tree, including untracked files
+// that .gitignore does not exclude.
+func Open(ctx context.Context, target, ref string) (*Checkout, error) {
+	if IsRemote(target) {
+		return clone(ctx, target, ref)
+	}
+	abs, err := filepath.Abs(target)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", target)
+	}
+	if _, err := git(ctx, abs, "rev-parse", "--is-inside-work-tree"); err != nil {
+		return nil, fmt.Errorf("%s is not a git checkout: %w", target, err)
+	}
+	co := &Checkout{Dir: abs, Ref: ref, Name: filepath.Base(abs), ID: abs}
+	if ref != "" {
+		co.ID += "@" + ref
+	}
+	return co, nil
+}
+
+func clone(ctx context.Context, url, ref string) (*Checkout, error) {
+	tmp, err := os.MkdirTemp("", "nlm-repo-*")
+	if err != nil {
+		return nil, err
+	}
+	// Enough history for the commit summary, without the whole repository.
+	args := []string{"clone", "--quiet", "--depth", fmt.Sprint(DefaultCommits), "--single-branch"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", url, tmp)
+	if _, err := git(ctx, "", args...); err != nil {
+		os.RemoveAll(tmp)
+		return nil, err
+	}
+	name := strings.TrimSuffix(path.Base(strings.TrimRight(url, "/")), ".git")
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		name = name[i+1:]
+	}
+	id := url
+	if ref != "" {
+		id += "@" + ref
+	}
+	return &Checkout{Dir: tmp, Name: name, ID: id, temp: tmp}, nil
+}
+
+// Close removes the clone of a remote repository.
+func (co *Checkout) Close() error {
+	if co.temp == "" {
+		return nil
+	}
+	return os.RemoveAll(co.temp)
+}
+
+// Files returns the files of the checkout that git tracks, or would track,
+// at co.Ref, in path order. The contents of files over maxSize bytes are
+// not read.
+func (co *Checkout) Files(ctx context.Context, maxSize int64) ([]File, error) {
+	if co.Ref != "" {
+		return co.archiveFiles(ctx, maxSize)
+	}
+	out, err := git(ctx, co.Dir, "ls-files", "-z", "--cached", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	seen := map[string]bool{}
+	for _, p := range strings.Split(string(out), "\x00") {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		full := filepath.Join(co.Dir, filepath.FromSlash(p))
+		info, err := os.Lstat(full)
+		if err != nil || !info.Mode().IsRegular() {
+			continue // deleted, a symlink or a submodule
+		}
+		f := File{Path: p, Size: info.Size()}
+		if f.Size <= maxSize {
+			//nolint:gosec // files listed by git under a user-provided checkout
+			if f.Data, err = os.ReadFile(full); err != nil {
+				return nil, err
+			}
+		}
+		files = append(files, f)
+	}
+	sortFiles(files)
+	return files, nil
+}
+
+// archiveFiles reads the files at co.Ref with git archive, which lists
+// what is committed there and nothing that is ignored.
+func (co *Checkout) archiveFiles(ctx context.Context, maxSize int64) ([]File, error) {
+	out, err := git(ctx, co.Dir, "archive", "--format=tar", co.Ref)
+	if err != nil {
+		return nil, err
+	}
+	var files []File
+	tr := tar.NewReader(bytes.NewReader(out))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read archive: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		f := File{Path: hdr.Name, Size: hdr.Size}
+		if f.Size <= maxSize {
+			if f.Data, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("read archive: %w", err)
+			}
+		}
+		files = append(files, f)
+	}
+	sortFiles(files)
+	return files, nil
+}
+
+// DefaultCommits is how many commits the commit summary lists.
+const DefaultCommits = 100
+
+// CommitSummary returns a Markdown list of the latest n commits at co.Ref,
+// or "" if there are none.
+func (co *Checkout) CommitSummary(ctx context.Context, n int) (string, error) {
+	ref := co.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	out, err := git(ctx, co.Dir, "log", "-n", fmt.Sprint(n), "--date=short", "--format=%h%x09%ad%x09%an%x09%s", ref, "--")
+	if err != nil {
+		if _, headErr := git(ctx, co.Dir, "rev-parse", "--verify", "--quiet", ref); headErr != nil {
+			return "", nil // no commits yet
+		}
+		return "", err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s: recent commits\n\n", co.Name)
+	count := 0
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		f := strings.SplitN(line, "\t", 4)
+		if len(f) != 4 {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s %s (%s): %s\n", f[1], f[0], f[2], f[3])
+		count++
+	}
+	if count == 0 {
+		return "", nil
+	}
+	return b.String(), nil
+}
+
+func git(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	sub := args[0]
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, ErrNoGit
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", sub, msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", sub, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,364 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/nlm/internal/split"
+)
+
+// newRepo creates a git repository in a temporary directory with files
+// committed, and returns its path.
+func newRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	writeFiles(t, dir, files)
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("init", "-q")
+	run("add", "-A")
+	run("commit", "-q", "-m", "Initial import")
+	return dir
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func paths(files []File) []string {
+	var out []string
+	for _, f := range files {
+		out = append(out, f.Path)
+	}
+	return out
+}
+
+func TestCheckoutFiles(t *testing.T) {
+	dir := newRepo(t, map[string]string{
+		".gitignore":  "*.log\nbuild/\n",
+		"README.md":   "# Demo\n",
+		"main.go":     "package main\n",
+		"pkg/util.go": "package pkg\n",
+	})
+	writeFiles(t, dir, map[string]string{
+		"app.log":      "ignored\n",
+		"build/out.go": "ignored\n",
+		"pkg/new.go":   "package pkg // untracked\n",
+		"big.txt":      strings.Repeat("x", 100),
+	})
+	ctx := context.Background()
+
+	co, err := Open(ctx, dir, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := co.Files(ctx, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".gitignore", "README.md", "big.txt", "main.go", "pkg/new.go", "pkg/util.go"}
+	if got := paths(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("work tree files = %q, want %q", got, want)
+	}
+	for _, f := range files {
+		if (f.Data == nil) != (f.Path == "big.txt") {
+			t.Errorf("%s: data read = %v, size %d", f.Path, f.Data != nil, f.Size)
+		}
+	}
+
+	co, err = Open(ctx, dir, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err = co.Files(ctx, 50)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []string{".gitignore", "README.md", "main.go", "pkg/util.go"}
+	if got := paths(files); !reflect.DeepEqual(got, want) {
+		t.Errorf("files at HEAD = %q, want %q", got, want)
+	}
+
+	commits, err := co.CommitSummary(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(commits, "# "+filepath.Base(dir)+": recent commits\n\n- ") || !strings.Contains(commits, "(Test): Initial import\n") {
+		t.Errorf("CommitSummary =\n%s", commits)
+	}
+
+	if _, err := Open(ctx, t.TempDir(), ""); err == nil {
+		t.Error("Open of a directory outside git succeeded")
+	}
+}
+
+func TestIsRemote(t *testing.T) {
+	for s, want := range map[string]bool{
+		"https://github.com/tmc/nlm":     true,
+		"git@github.com:tmc/nlm.git":     true,
+		"ssh://git@example.com/repo.git": true,
+		"./nlm":                          false,
+		"/src/nlm":                       false,
+	} {
+		if got := IsRemote(s); got != want {
+			t.Errorf("IsRemote(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestBundles(t *testing.T) {
+	files := []File{
+		{Path: "README.md", Data: []byte("# Demo\n")},
+		{Path: "go.sum", Data: []byte("sums\n")},
+		{Path: "logo.png", Data: []byte("\x89PNG\x00")},
+		{Path: "main.go", Data: []byte("package main\n")},
+		{Path: "huge.bin", Size: 1 << 30},
+		{Path: "internal/api/client.go", Data: []byte("package api\n")},
+		{Path: "internal/api/client_test.go", Data: []byte("package api\n")},
+		{Path: "internal/api/testdata/x.json", Data: []byte("{}\n")},
+		{Path: "internal/rpc/rpc.go", Data: []byte("package rpc\n")},
+	}
+	bundles, skipped := Bundles("demo", files, "# demo: recent commits\n", Options{})
+	var titles []string
+	for _, b := range bundles {
+		titles = append(titles, b.Title)
+	}
+	want := []string{"demo/", "demo/README.md", "demo/internal/api/", "demo/internal/api/testdata/", "demo/internal/rpc/", "demo: recent commits"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("titles = %q, want %q", titles, want)
+	}
+	if got := Describe(skipped); got != "1 binary, 1 too large, 1 lock or checksum file" {
+		t.Errorf("Describe(skipped) = %q", got)
+	}
+	api := bundles[2]
+	if api.Files != 2 || !strings.HasPrefix(api.Content, "# demo/internal/api/\n\n## internal/api/client.go\n\n```go\npackage api\n```\n") {
+		t.Errorf("internal/api bundle = %d files:\n%s", api.Files, api.Content)
+	}
+
+	// Folding to fit: the deepest directories go into their parents first.
+	bundles, _ = Bundles("demo", files, "# demo: recent commits\n", Options{MaxBundles: 4})
+	titles = nil
+	for _, b := range bundles {
+		titles = append(titles, b.Title)
+	}
+	want = []string{"demo/", "demo/README.md", "demo/internal/", "demo: recent commits"}
+	if !reflect.DeepEqual(titles, want) {
+		t.Errorf("folded titles = %q, want %q", titles, want)
+	}
+
+	// --include keeps the README.
+	bundles, _ = Bundles("demo", files, "", Options{Include: []string{"*_test.go"}})
+	if len(bundles) != 2 || bundles[0].Title != "demo/README.md" || bundles[1].Files != 1 {
+		t.Errorf("Bundles with Include = %+v", bundles)
+	}
+}
+
+func TestBundlesSplitLargeDirectory(t *testing.T) {
+	var files []File
+	for _, name := range []string{"a.go", "b.go", "c.go"} {
+		files = append(files, File{Path: "pkg/" + name, Data: []byte(strings.Repeat("word ", 40) + "\n")})
+	}
+	bundles, _ := Bundles("demo", files, "", Options{Limits: split.Limits{MaxWords: 80}})
+	if len(bundles) != 3 || bundles[0].Title != "demo/pkg/ (part 1/3)" {
+		t.Fatalf("Bundles = %+v", bundles)
+	}
+	for _, b := range bundles {
+		if !strings.HasPrefix(b.Content, "# demo/pkg/\n\n") || split.Words(b.Content) > 80 {
+			t.Errorf("part %q: %d words", b.Title, split.Words(b.Content))
+		}
+	}
+}
+
+func TestBundlesLargeDirectory(t *testing.T) {
+	// 20,000 files of 30 words each overflow one source once.
+	files := make([]File, 20_000)
+	for i := range files {
+		files[i] = File{Path: fmt.Sprintf("gen/f%05d.go", i), Data: []byte(strings.Repeat("word ", 30) + "\n")}
+	}
+	start := time.Now()
+	bundles, _ := Bundles("demo", files, "", Options{Limits: split.DefaultLimits})
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Bundles took %v", elapsed)
+	}
+	var total int
+	for _, b := range bundles {
+		if !split.DefaultLimits.FitsText(b.Content) {
+			t.Errorf("%s has %s", b.Title, split.DefaultLimits.Describe(b.Content))
+		}
+		total += b.Files
+	}
+	if len(bundles) < 2 || total != len(files) {
+		t.Errorf("Bundles = %d bundles holding %d files", len(bundles), total)
+	}
+}
+
+type fakeClient struct {
+	added      map[string]string // title -> content
+	deleted    []string
+	next       int
+	failAdd    string
+	failDelete bool
+	sources    int // in the notebook
+	limit      int // 0 means no limit
+}
+
+func (c *fakeClient) AddSourceFromText(projectID, content, title string) (string, error) {
+	if title == c.failAdd || (c.limit > 0 && c.sources >= c.limit) {
+		return "", errors.New("quota exceeded")
+	}
+	c.next++
+	c.sources++
+	c.added[title] = content
+	return fmt.Sprintf("src%d", c.next), nil
+}
+
+func (c *fakeClient) DeleteSources(projectID string, ids []string) error {
+	if c.failDelete {
+		return errors.New("backend error")
+	}
+	c.sources -= len(ids)
+	c.deleted = append(c.deleted, ids...)
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "repos", "state.json")
+	st, err := LoadState(statePath, "nb1", "/src/demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &fakeClient{added: map[string]string{}}
+	first := []Bundle{{Title: "demo/", Content: "one"}, {Title: "demo/pkg/", Content: "two"}, {Title: "demo/old/", Content: "three"}}
+	var changes []Change
+	record := func(ch Change) { changes = append(changes, ch) }
+	if err := Sync(c, "nb1", st, first, record); err != nil {
+		t.Fatal(err)
+	}
+	if len(c.added) != 3 || len(changes) != 3 {
+		t.Fatalf("first sync added %d, reported %v", len(c.added), changes)
+	}
+	if err := st.Save(statePath); err != nil {
+		t.Fatal(err)
+	}
+
+	st, err = LoadState(statePath, "nb1", "/src/demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldPkg := st.Sources["demo/pkg/"].SourceID
+	second := []Bundle{{Title: "demo/", Content: "one"}, {Title: "demo/pkg/", Content: "two, edited"}, {Title: "demo/new/", Content: "four"}}
+	wantPlan := []Change{
+		{Title: "demo/old/", Action: Removed, SourceID: st.Sources["demo/old/"].SourceID},
+		{Title: "demo/", Action: Unchanged, SourceID: st.Sources["demo/"].SourceID},
+		{Title: "demo/pkg/", Action: Updated, SourceID: oldPkg},
+		{Title: "demo/new/", Action: Added},
+	}
+	if got := Plan(st, second); !reflect.DeepEqual(got, wantPlan) {
+		t.Errorf("Plan =\n%+v\nwant\n%+v", got, wantPlan)
+	}
+
+	c.added, changes = map[string]string{}, nil
+	if err := Sync(c, "nb1", st, second, record); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.added["demo/"]; ok || len(c.added) != 2 {
+		t.Errorf("second sync added %v, want only the changed and new bundles", c.added)
+	}
+	if !reflect.DeepEqual(c.deleted, []string{wantPlan[0].SourceID, oldPkg}) {
+		t.Errorf("deleted %v", c.deleted)
+	}
+	if _, ok := st.Sources["demo/old/"]; ok || st.Sources["demo/pkg/"].SourceID == oldPkg {
+		t.Errorf("state not updated: %+v", st.Sources)
+	}
+
+	// A failed upload keeps the old source and its state.
+	c.failAdd = "demo/"
+	third := []Bundle{{Title: "demo/", Content: "one, edited"}, {Title: "demo/pkg/", Content: "two, edited"}, {Title: "demo/new/", Content: "four"}}
+	before := st.Sources["demo/"]
+	if err := Sync(c, "nb1", st, third, nil); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Sync with a failing upload: %v", err)
+	}
+	if st.Sources["demo/"] != before {
+		t.Error("failed upload changed the state")
+	}
+
+	if _, err := LoadState(statePath, "nb2", "/src/demo"); err == nil {
+		t.Error("LoadState for another notebook succeeded")
+	}
+}
+
+func TestSyncFailedDeleteKeepsStaleSource(t *testing.T) {
+	st := &State{Sources: map[string]SourceState{}}
+	c := &fakeClient{added: map[string]string{}}
+	if err := Sync(c, "nb1", st, []Bundle{{Title: "demo/", Content: "one"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	old := st.Sources["demo/"].SourceID
+
+	c.failDelete = true
+	edited := []Bundle{{Title: "demo/", Content: "one, edited"}}
+	if err := Sync(c, "nb1", st, edited, nil); err == nil || !strings.Contains(err.Error(), "backend error") {
+		t.Errorf("Sync with a failing delete: %v", err)
+	}
+	if st.Sources["demo/"].SourceID == old {
+		t.Error("state still points at the replaced source")
+	}
+	if !reflect.DeepEqual(st.Stale, []string{old}) {
+		t.Errorf("stale = %v, want [%s]", st.Stale, old)
+	}
+	if !st.SourceIDs()[old] {
+		t.Error("SourceIDs does not include the stale source")
+	}
+
+	// The next sync retries the delete even though nothing changed.
+	c.failDelete = false
+	if err := Sync(c, "nb1", st, edited, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(c.deleted, []string{old}) || len(st.Stale) != 0 {
+		t.Errorf("deleted %v, stale %v after retry", c.deleted, st.Stale)
+	}
+}
+
+func TestSyncRemovesBeforeAdding(t *testing.T) {
+	// A restructured repository: every old bundle is replaced by a new one
+	// in a notebook with room for only one more source.
+	st := &State{Sources: map[string]SourceState{}}
+	var bundles []Bundle
+	for i := range 3 {
+		st.Sources[fmt.Sprintf("demo/old%d/", i)] = SourceState{SourceID: fmt.Sprintf("old%d", i)}
+		bundles = append(bundles, Bundle{Title: fmt.Sprintf("demo/new%d/", i), Content: "new"})
+	}
+	c := &fakeClient{added: map[string]string{}, sources: 3, limit: 4}
+	if err := Sync(c, "nb1", st, bundles, nil); err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if len(c.added) != 3 || len(c.deleted) != 3 || len(st.Sources) != 3 {
+		t.Errorf("added %v, deleted %v, state %v", c.added, c.deleted, st.Sources)
+	}
+}
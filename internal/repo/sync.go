@@ -0,0 +1,201 @@
+package repo
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/tmc/nlm/internal/statefile"
+)
+
+// State maps the bundles of a repository to the sources they were added
+// as, so that a later run only replaces what changed.
+type State struct {
+	NotebookID string                 `json:"notebook_id"`
+	Repo       string                 `json:"repo"` // Checkout.ID
+	Sources    map[string]SourceState `json:"sources"`
+
+	// Stale lists sources that were replaced by a newer version but could
+	// not be deleted. Sync deletes them before anything else.
+	Stale []string `json:"stale,omitempty"`
+}
+
+// SourceState records the source created from one bundle.
+type SourceState struct {
+	SourceID   string    `json:"source_id"`
+	SHA256     string    `json:"sha256"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// LoadState reads the state file at path. A missing file yields an empty
+// state; a file that belongs to another notebook or repository is an
+// error.
+func LoadState(path, notebookID, repo string) (*State, error) {
+	st := &State{NotebookID: notebookID, Repo: repo, Sources: make(map[string]SourceState)}
+	if _, err := statefile.Load(path, st); err != nil {
+		return nil, err
+	}
+	if st.NotebookID != notebookID || st.Repo != repo {
+		return nil, fmt.Errorf("state file %s tracks %s in notebook %s, not %s in %s", path, st.Repo, st.NotebookID, repo, notebookID)
+	}
+	if st.Sources == nil {
+		st.Sources = make(map[string]SourceState)
+	}
+	return st, nil
+}
+
+// Save writes the state to path, creating its directory and replacing the
+// file atomically.
+func (st *State) Save(path string) error {
+	return statefile.Save(path, st)
+}
+
+// SourceIDs returns the IDs of the tracked sources, stale ones included.
+func (st *State) SourceIDs() map[string]bool {
+	ids := make(map[string]bool, len(st.Sources)+len(st.Stale))
+	for _, s := range st.Sources {
+		ids[s.SourceID] = true
+	}
+	for _, id := range st.Stale {
+		ids[id] = true
+	}
+	return ids
+}
+
+// Client is the subset of the NotebookLM API Sync needs.
+// *api.Client satisfies it.
+type Client interface {
+	AddSourceFromText(projectID, content, title string) (string, error)
+	DeleteSources(projectID string, sourceIDs []string) error
+}
+
+// Action is what Sync did, or would do, for one bundle.
+type Action string
+
+const (
+	Added     Action = "added"
+	Updated   Action = "updated"
+	Removed   Action = "removed"
+	Unchanged Action = "unchanged"
+)
+
+// Change describes the action taken for one bundle.
+type Change struct {
+	Title    string
+	Action   Action
+	SourceID string
+}
+
+// Plan compares bundles with the state and returns the change each needs,
+// removals first so that the sources they free make room for new ones.
+// Nothing is modified.
+func Plan(st *State, bundles []Bundle) []Change {
+	current := make(map[string]bool, len(bundles))
+	for _, b := range bundles {
+		current[b.Title] = true
+	}
+	var gone []string
+	for title := range st.Sources {
+		if !current[title] {
+			gone = append(gone, title)
+		}
+	}
+	sort.Strings(gone)
+	var changes []Change
+	for _, title := range gone {
+		changes = append(changes, Change{Title: title, Action: Removed, SourceID: st.Sources[title].SourceID})
+	}
+	for _, b := range bundles {
+		old, tracked := st.Sources[b.Title]
+		switch {
+		case !tracked:
+			changes = append(changes, Change{Title: b.Title, Action: Added})
+		case old.SHA256 != b.Hash():
+			changes = append(changes, Change{Title: b.Title, Action: Updated, SourceID: old.SourceID})
+		default:
+			changes = append(changes, Change{Title: b.Title, Action: Unchanged, SourceID: old.SourceID})
+		}
+	}
+	return changes
+}
+
+// Sync brings the notebook in line with bundles: new bundles are added,
+// changed ones replaced and those no longer produced removed. The state is
+// updated as each change succeeds, so it stays accurate if Sync fails part
+// way; it is not saved. onChange, if not nil, is called after each change.
+//
+// A changed bundle is uploaded before its old source is deleted. The old
+// source is moved to st.Stale until the delete succeeds, so a failed
+// delete is retried by the next Sync rather than forgotten.
+func Sync(c Client, notebookID string, st *State, bundles []Bundle, onChange func(Change)) error {
+	byTitle := make(map[string]Bundle, len(bundles))
+	for _, b := range bundles {
+		byTitle[b.Title] = b
+	}
+	report := func(ch Change) {
+		if onChange != nil {
+			onChange(ch)
+		}
+	}
+	var failed int
+	var firstErr error
+	if err := deleteStale(c, notebookID, st); err != nil {
+		failed++
+		firstErr = err
+	}
+	for _, ch := range Plan(st, bundles) {
+		switch ch.Action {
+		case Unchanged:
+			continue
+		case Removed:
+			if err := c.DeleteSources(notebookID, []string{ch.SourceID}); err != nil {
+				failed++
+				firstErr = firstError(firstErr, fmt.Errorf("remove %s: %w", ch.Title, err))
+				continue
+			}
+			delete(st.Sources, ch.Title)
+			report(ch)
+			continue
+		}
+
+		b := byTitle[ch.Title]
+		id, err := c.AddSourceFromText(notebookID, b.Content, b.Title)
+		if err != nil {
+			failed++
+			firstErr = firstError(firstErr, fmt.Errorf("add %s: %w", b.Title, err))
+			continue
+		}
+		st.Sources[b.Title] = SourceState{SourceID: id, SHA256: b.Hash(), UploadedAt: time.Now().UTC()}
+		if ch.Action == Updated {
+			st.Stale = append(st.Stale, ch.SourceID)
+			if err := deleteStale(c, notebookID, st); err != nil {
+				failed++
+				firstErr = firstError(firstErr, fmt.Errorf("%s: %w", b.Title, err))
+			}
+		}
+		report(Change{Title: b.Title, Action: ch.Action, SourceID: id})
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d changes failed, first: %w", failed, firstErr)
+	}
+	return nil
+}
+
+// deleteStale deletes the sources in st.Stale, clearing it on success.
+func deleteStale(c Client, notebookID string, st *State) error {
+	if len(st.Stale) == 0 {
+		return nil
+	}
+	if err := c.DeleteSources(notebookID, st.Stale); err != nil {
+		return fmt.Errorf("delete %d replaced sources: %w", len(st.Stale), err)
+	}
+	st.Stale = nil
+	return nil
+}
+
+func firstError(first, err error) error {
+	if first != nil {
+		return first
+	}
+	return err
+}
@@ -0,0 +1,119 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/tmc/nlm/internal/batchexecute"
+)
+
+// Invoker executes a call and returns the raw response data.
+type Invoker func(ctx context.Context, call Call) (json.RawMessage, error)
+
+// Interceptor wraps the execution of a call. It must call next to continue
+// the chain, and may inspect or replace the call, response and error.
+type Interceptor func(ctx context.Context, call Call, next Invoker) (json.RawMessage, error)
+
+type interceptorsKey struct{}
+
+// WithInterceptors registers unary interceptors on clients created with New,
+// including the generated service clients. Interceptors run in the order
+// given; repeated options append to the chain.
+func WithInterceptors(interceptors ...Interceptor) batchexecute.Option {
+	return func(c *batchexecute.Client) {
+		existing, _ := c.Value(interceptorsKey{}).([]Interceptor)
+		chain := append(append([]Interceptor(nil), existing...), interceptors...)
+		batchexecute.WithValue(interceptorsKey{}, chain)(c)
+	}
+}
+
+// chainInterceptors composes interceptors around final so the first
+// interceptor is the outermost.
+func chainInterceptors(interceptors []Interceptor, final Invoker) Invoker {
+	invoker := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		ic, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, call Call) (json.RawMessage, error) {
+			return ic(ctx, call, next)
+		}
+	}
+	return invoker
+}
+
+// LoggingInterceptor logs each call with its notebook, duration, response
+// size and error.
+func LoggingInterceptor(logger *slog.Logger) Interceptor {
+	return func(ctx context.Context, call Call, next Invoker) (json.RawMessage, error) {
+		start := time.Now()
+		resp, err := next(ctx, call)
+		attrs := []any{
+			"rpc", call.ID,
+			"notebook", call.NotebookID,
+			"duration", time.Since(start),
+			"bytes", len(resp),
+		}
+		if err != nil {
+			logger.WarnContext(ctx, "rpc failed", append(attrs, "error", err)...)
+		} else {
+			logger.InfoContext(ctx, "rpc", attrs...)
+		}
+		return resp, err
+	}
+}
+
+// TimingInterceptor reports the duration of each call to observe.
+func TimingInterceptor(observe func(call Call, d time.Duration, err error)) Interceptor {
+	return func(ctx context.Context, call Call, next Invoker) (json.RawMessage, error) {
+		start := time.Now()
+		resp, err := next(ctx, call)
+		observe(call, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// DumpInterceptor writes every call and its response to dir as pairs of
+// NNNN-<rpc-id>.request.json and NNNN-<rpc-id>.response.json files.
+// Failures to write a dump are ignored so they never break the call.
+func DumpInterceptor(dir string) Interceptor {
+	var seq atomic.Int64
+	return func(ctx context.Context, call Call, next Invoker) (json.RawMessage, error) {
+		prefix := filepath.Join(dir, fmt.Sprintf("%04d-%s", seq.Add(1), call.ID))
+		writeDump(prefix+".request.json", map[string]any{
+			"id":          call.ID,
+			"notebook_id": call.NotebookID,
+			"args":        call.Args,
+			"time":        time.Now().UTC(),
+		})
+
+		resp, err := next(ctx, call)
+
+		dump := map[string]any{"id": call.ID}
+		if err != nil {
+			dump["error"] = err.Error()
+		}
+		if json.Valid(resp) {
+			dump["data"] = resp
+		} else if len(resp) > 0 {
+			dump["raw"] = string(resp)
+		}
+		writeDump(prefix+".response.json", dump)
+		return resp, err
+	}
+}
+
+func writeDump(path string, v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, append(data, '\n'), 0o600)
+}
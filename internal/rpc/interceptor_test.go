@@ -0,0 +1,149 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/tmc/nlm/internal/batchexecute"
+)
+
+// fakeTransport answers every batchexecute request with a canned response.
+func fakeTransport(t *testing.T) batchexecute.Option {
+	t.Helper()
+	rt := batchexecute.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body := `)]}'
+123
+[[["wrb.fr","test","[\"ok\"]",null,null,null,"generic"]]]
+`
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Header:     make(http.Header),
+			Request:    req,
+		}, nil
+	})
+	return batchexecute.WithHTTPClient(&http.Client{Transport: rt})
+}
+
+func recordingInterceptor(name string, trace *[]string) Interceptor {
+	return func(ctx context.Context, call Call, next Invoker) (json.RawMessage, error) {
+		*trace = append(*trace, name+":before")
+		resp, err := next(ctx, call)
+		*trace = append(*trace, name+":after")
+		return resp, err
+	}
+}
+
+func TestInterceptorOrder(t *testing.T) {
+	var trace []string
+	client := New("token", "", fakeTransport(t),
+		WithInterceptors(recordingInterceptor("a", &trace)),
+		WithInterceptors(recordingInterceptor("b", &trace)),
+	)
+
+	resp, err := client.Do(Call{ID: "test"})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if string(resp) != `["ok"]` {
+		t.Errorf("response = %s, want [\"ok\"]", resp)
+	}
+
+	want := []string{"a:before", "b:before", "b:after", "a:after"}
+	if strings.Join(trace, ",") != strings.Join(want, ",") {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestInterceptorCanShortCircuit(t *testing.T) {
+	errBlocked := errors.New("blocked")
+	client := New("token", "", fakeTransport(t), WithInterceptors(
+		func(ctx context.Context, call Call, next Invoker) (json.RawMessage, error) {
+			return nil, errBlocked
+		},
+	))
+
+	if _, err := client.Do(Call{ID: "test"}); !errors.Is(err, errBlocked) {
+		t.Errorf("Do error = %v, want %v", err, errBlocked)
+	}
+}
+
+func TestInvokeUsesInterceptors(t *testing.T) {
+	var trace []string
+	client := New("token", "", WithInterceptors(recordingInterceptor("a", &trace)))
+
+	resp, err := client.Invoke(context.Background(), Call{ID: "custom"}, func(ctx context.Context, call Call) (json.RawMessage, error) {
+		trace = append(trace, "invoke:"+call.ID)
+		return json.RawMessage(`"done"`), nil
+	})
+	if err != nil {
+		t.Fatalf("Invoke: %v", err)
+	}
+	if string(resp) != `"done"` {
+		t.Errorf("response = %s, want \"done\"", resp)
+	}
+	want := []string{"a:before", "invoke:custom", "a:after"}
+	if strings.Join(trace, ",") != strings.Join(want, ",") {
+		t.Errorf("trace = %v, want %v", trace, want)
+	}
+}
+
+func TestTimingInterceptor(t *testing.T) {
+	var got []string
+	client := New("token", "", fakeTransport(t), WithInterceptors(
+		TimingInterceptor(func(call Call, d time.Duration, err error) {
+			if d <= 0 {
+				t.Errorf("duration = %v, want > 0", d)
+			}
+			got = append(got, call.ID)
+		}),
+	))
+
+	if _, err := client.Do(Call{ID: "test"}); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if len(got) != 1 || got[0] != "test" {
+		t.Errorf("observed calls = %v, want [test]", got)
+	}
+}
+
+func TestDumpInterceptor(t *testing.T) {
+	dir := t.TempDir()
+	client := New("token", "", fakeTransport(t), WithInterceptors(DumpInterceptor(dir)))
+
+	if _, err := client.Do(Call{ID: "test", NotebookID: "nb1", Args: []interface{}{"x"}}); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	req, err := os.ReadFile(filepath.Join(dir, "0001-test.request.json"))
+	if err != nil {
+		t.Fatalf("read request dump: %v", err)
+	}
+	if !strings.Contains(string(req), `"notebook_id": "nb1"`) {
+		t.Errorf("request dump missing notebook id:\n%s", req)
+	}
+
+	resp, err := os.ReadFile(filepath.Join(dir, "0001-test.response.json"))
+	if err != nil {
+		t.Fatalf("read response dump: %v", err)
+	}
+	var dump struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(resp, &dump); err != nil {
+		t.Fatalf("parse response dump: %v", err)
+	}
+	var data []string
+	if err := json.Unmarshal(dump.Data, &data); err != nil || len(data) != 1 || data[0] != "ok" {
+		t.Errorf("response dump data = %s, want [\"ok\"]", dump.Data)
+	}
+}
@@ -1,9 +1,11 @@
 package rpc
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"regexp"
@@ -253,8 +255,9 @@ type Call struct {
 
 // Client handles NotebookLM RPC communication
 type Client struct {
-	Config batchexecute.Config
-	client *batchexecute.Client
+	Config       batchexecute.Config
+	client       *batchexecute.Client
+	interceptors []Interceptor
 }
 
 // New creates a new NotebookLM RPC client
@@ -283,20 +286,45 @@ func New(authToken, cookies string, options ...batchexecute.Option) *Client {
 			"hl":    "en",
 		},
 	}
+	client := batchexecute.NewClient(config, options...)
+	interceptors, _ := client.Value(interceptorsKey{}).([]Interceptor)
 	return &Client{
-		Config: config,
-		client: batchexecute.NewClient(config, options...),
+		Config:       config,
+		client:       client,
+		interceptors: interceptors,
 	}
 }
 
+// Logger returns the structured logger shared with the batchexecute client.
+func (c *Client) Logger() *slog.Logger {
+	return c.client.Logger()
+}
+
 // Do executes a NotebookLM RPC call
 func (c *Client) Do(call Call) (json.RawMessage, error) {
-	if c.Config.Debug {
-		fmt.Printf("\n=== RPC Call ===\n")
-		fmt.Printf("ID: %s\n", call.ID)
-		fmt.Printf("NotebookID: %s\n", call.NotebookID)
-		fmt.Printf("Args:\n")
-		spew.Dump(call.Args)
+	return c.DoContext(context.Background(), call)
+}
+
+// DoContext executes a NotebookLM RPC call with the given context,
+// running it through the client's interceptors.
+func (c *Client) DoContext(ctx context.Context, call Call) (json.RawMessage, error) {
+	return c.Invoke(ctx, call, c.do)
+}
+
+// Invoke runs call through the client's interceptors with invoker as the
+// final step. It lets calls that bypass batchexecute, such as the streamed
+// chat endpoint, share the same interceptor chain.
+func (c *Client) Invoke(ctx context.Context, call Call, invoker Invoker) (json.RawMessage, error) {
+	if len(c.interceptors) == 0 {
+		return invoker(ctx, call)
+	}
+	return chainInterceptors(c.interceptors, invoker)(ctx, call)
+}
+
+func (c *Client) do(ctx context.Context, call Call) (json.RawMessage, error) {
+	log := c.Logger().With("rpc", call.ID)
+	if log.Enabled(ctx, slog.LevelDebug) {
+		log.DebugContext(ctx, "rpc call", "notebook", call.NotebookID, "args", spew.Sdump(call.Args))
 	}
 
 	// Create request-specific URL parameters
@@ -318,20 +346,12 @@ func (c *Client) Do(call Call) (json.RawMessage, error) {
 		URLParams: urlParams,
 	}
 
-	if c.Config.Debug {
-		fmt.Printf("\nRPC Request:\n")
-		spew.Dump(rpc)
-	}
-
-	resp, err := c.client.Do(rpc)
+	resp, err := c.client.DoContext(ctx, rpc)
 	if err != nil {
 		return nil, fmt.Errorf("execute rpc: %w", err)
 	}
 
-	if c.Config.Debug {
-		fmt.Printf("\nRPC Response:\n")
-		spew.Dump(resp)
-	}
+	log.DebugContext(ctx, "rpc response", "bytes", len(resp.Data))
 
 	return resp.Data, nil
 }
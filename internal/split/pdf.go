@@ -0,0 +1,97 @@
+package split
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// IsPDF reports whether data is a PDF document.
+func IsPDF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("%PDF-"))
+}
+
+// ErrNoQPDF is returned when splitting a PDF but qpdf is not installed.
+var ErrNoQPDF = errors.New("splitting PDFs needs qpdf (https://qpdf.sourceforge.io) on PATH")
+
+// PageRange is an inclusive range of 1-based page numbers.
+type PageRange struct {
+	First, Last int
+}
+
+func (r PageRange) String() string {
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// PageRanges divides pages into as few equal ranges as keep each part of a
+// size-byte document under maxBytes, assuming pages are of similar size.
+// Since that is only an estimate, parts get a tenth of headroom.
+func PageRanges(pages int, size, maxBytes int64) ([]PageRange, error) {
+	if pages <= 0 {
+		return nil, fmt.Errorf("%w: document has no pages", ErrCannotSplit)
+	}
+	n := 1
+	if maxBytes > 0 && size > maxBytes {
+		n = int((size*10/9 + maxBytes - 1) / maxBytes)
+	}
+	if n > pages {
+		return nil, fmt.Errorf("%w: %d bytes over %d pages would need %d parts", ErrCannotSplit, size, pages, n)
+	}
+	ranges := make([]PageRange, n)
+	first := 1
+	for i := range ranges {
+		count := pages / n
+		if i < pages%n {
+			count++
+		}
+		ranges[i] = PageRange{first, first + count - 1}
+		first += count
+	}
+	return ranges, nil
+}
+
+// PDFPages returns the number of pages of the PDF at path.
+func PDFPages(ctx context.Context, path string) (int, error) {
+	qpdf, err := exec.LookPath("qpdf")
+	if err != nil {
+		return 0, ErrNoQPDF
+	}
+	//nolint:gosec // qpdf is run on a user-provided file
+	out, err := exec.CommandContext(ctx, qpdf, "--show-npages", path).Output()
+	if err != nil {
+		return 0, fmt.Errorf("count pages of %s: %w", path, err)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, fmt.Errorf("count pages of %s: %w", path, err)
+	}
+	return n, nil
+}
+
+// PDF writes the given page ranges of the PDF at path to new files in dir
+// and returns their paths, in order.
+func PDF(ctx context.Context, path, dir string, ranges []PageRange) ([]string, error) {
+	qpdf, err := exec.LookPath("qpdf")
+	if err != nil {
+		return nil, ErrNoQPDF
+	}
+	base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	var parts []string
+	for i, r := range ranges {
+		out := filepath.Join(dir, fmt.Sprintf("%s-part%d.pdf", base, i+1))
+		//nolint:gosec // qpdf is run on a user-provided file
+		cmd := exec.CommandContext(ctx, qpdf, "--empty", "--pages", path, r.String(), "--", out)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("split %s pages %s: %w", path, r, err)
+		}
+		parts = append(parts, out)
+	}
+	return parts, nil
+}
@@ -0,0 +1,172 @@
+// Package split breaks content that exceeds NotebookLM's per-source limits
+// into parts that each fit: text at heading, paragraph or line boundaries,
+// and PDFs by page ranges.
+package split
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Limits are the most one source may hold.
+type Limits struct {
+	MaxWords int   // 0 means no word limit
+	MaxBytes int64 // 0 means no size limit
+}
+
+// DefaultLimits are NotebookLM's documented per-source limits.
+var DefaultLimits = Limits{MaxWords: 500_000, MaxBytes: 200 << 20}
+
+// ErrCannotSplit is returned when content cannot be broken into parts that
+// each fit the limits.
+var ErrCannotSplit = errors.New("cannot be split to fit the source limits")
+
+// Words counts the words of text.
+func Words(text string) int {
+	return len(strings.Fields(text))
+}
+
+// FitsText reports whether text fits in one source.
+func (l Limits) FitsText(text string) bool {
+	return l.Fits(Words(text), int64(len(text)))
+}
+
+// Fits reports whether a source of the given size fits.
+func (l Limits) Fits(words int, size int64) bool {
+	return (l.MaxWords <= 0 || words <= l.MaxWords) && (l.MaxBytes <= 0 || size <= l.MaxBytes)
+}
+
+// Describe says how text compares to the limits, e.g.
+// "612000 words (limit 500000)".
+func (l Limits) Describe(text string) string {
+	words, size := Words(text), int64(len(text))
+	if l.MaxWords > 0 && words > l.MaxWords {
+		return fmt.Sprintf("%d words (limit %d)", words, l.MaxWords)
+	}
+	return fmt.Sprintf("%d bytes (limit %d)", size, l.MaxBytes)
+}
+
+// Text splits text into as few parts as it can that each fit l, breaking
+// it at Markdown headings where possible, then at blank lines, then at
+// line ends. Text that already fits is returned as a single part. If a
+// single line is over the limits, Text returns ErrCannotSplit.
+func Text(text string, l Limits) ([]string, error) {
+	return l.pack(text, 0)
+}
+
+// splitters cut text at ever finer boundaries. Each returns pieces that
+// concatenate back to the input.
+var splitters = []func(string) []string{sections, paragraphs, lines}
+
+// pack splits text into parts that fit l, cutting it with the splitter
+// at level and using finer ones only for pieces that are still too large.
+// Neighboring parts are then joined while they fit, so that finer cuts do
+// not leave many small parts.
+//
+// Every cut falls at a line end, so the pieces are consecutive slices of
+// text whose word counts add up: joining them extends the last part in
+// place, and its size is kept as a running total rather than measured
+// again for each piece.
+func (l Limits) pack(text string, level int) ([]string, error) {
+	if l.FitsText(text) {
+		return []string{text}, nil
+	}
+	if level == len(splitters) {
+		line := strings.TrimSpace(text)
+		if len(line) > 60 {
+			line = line[:60] + "..."
+		}
+		return nil, fmt.Errorf("%w: a single line has %s: %q", ErrCannotSplit, l.Describe(text), line)
+	}
+	var parts []string
+	start, end := 0, 0 // the last part is text[start:end]
+	var words int
+	var size int64
+	for _, piece := range splitters[level](text) {
+		sub, err := l.pack(piece, level+1)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range sub {
+			w, n := Words(p), int64(len(p))
+			if len(parts) > 0 && l.Fits(words+w, size+n) {
+				end += len(p)
+				parts[len(parts)-1] = text[start:end]
+				words, size = words+w, size+n
+				continue
+			}
+			start, end = end, end+len(p)
+			parts = append(parts, text[start:end])
+			words, size = w, n
+		}
+	}
+	return parts, nil
+}
+
+// sections cuts text before each Markdown heading line.
+func sections(text string) []string {
+	return cutBefore(text, func(line string) bool {
+		return strings.HasPrefix(line, "#")
+	})
+}
+
+// paragraphs cuts text after each blank line.
+func paragraphs(text string) []string {
+	var out []string
+	for text != "" {
+		i := strings.Index(text, "\n\n")
+		if i < 0 {
+			break
+		}
+		// Keep any further blank lines with this paragraph.
+		j := i + 2
+		for j < len(text) && text[j] == '\n' {
+			j++
+		}
+		out = append(out, text[:j])
+		text = text[j:]
+	}
+	if text != "" {
+		out = append(out, text)
+	}
+	return out
+}
+
+// lines cuts text after each newline.
+func lines(text string) []string {
+	return strings.SplitAfter(text, "\n")
+}
+
+// cutBefore cuts text before each line for which start reports true.
+func cutBefore(text string, start func(line string) bool) []string {
+	var out []string
+	begin := 0
+	for i := 0; i < len(text); {
+		end := strings.IndexByte(text[i:], '\n')
+		if end < 0 {
+			end = len(text)
+		} else {
+			end += i + 1
+		}
+		if i > begin && start(text[i:end]) {
+			out = append(out, text[begin:i])
+			begin = i
+		}
+		i = end
+	}
+	return append(out, text[begin:])
+}
+
+// Titles returns the titles of n parts of a source: title itself if n is
+// 1, and "title (part i/n)" otherwise.
+func Titles(title string, n int) []string {
+	if n == 1 {
+		return []string{title}
+	}
+	titles := make([]string, n)
+	for i := range titles {
+		titles[i] = fmt.Sprintf("%s (part %d/%d)", title, i+1, n)
+	}
+	return titles
+}
@@ -0,0 +1,154 @@
+package split
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestText(t *testing.T) {
+	doc := "# One\nalpha beta gamma\n\ndelta epsilon\n# Two\nzeta eta\ntheta iota kappa\n\nlambda\n"
+	tests := []struct {
+		name     string
+		maxWords int
+		want     []string
+	}{
+		{"fits", 100, []string{doc}},
+		// "# Two" through "lambda" is 8 words.
+		{"by heading", 7, []string{
+			"# One\nalpha beta gamma\n\ndelta epsilon\n",
+			"# Two\nzeta eta\ntheta iota kappa\n\n",
+			"lambda\n",
+		}},
+		{"by paragraph", 5, []string{
+			"# One\nalpha beta gamma\n\n",
+			"delta epsilon\n",
+			"# Two\nzeta eta\n",
+			"theta iota kappa\n\nlambda\n",
+		}},
+		{"by line", 3, []string{
+			"# One\n",
+			"alpha beta gamma\n\n",
+			"delta epsilon\n",
+			"# Two\n",
+			"zeta eta\n",
+			"theta iota kappa\n\n",
+			"lambda\n",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Text(doc, Limits{MaxWords: tt.maxWords})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Text(maxWords=%d) =\n%q\nwant\n%q", tt.maxWords, got, tt.want)
+			}
+			if strings.Join(got, "") != doc {
+				t.Error("parts do not add up to the input")
+			}
+			for _, p := range got {
+				if w := Words(p); w > tt.maxWords {
+					t.Errorf("part %q has %d words, over %d", p, w, tt.maxWords)
+				}
+			}
+		})
+	}
+}
+
+func TestTextBytes(t *testing.T) {
+	doc := strings.Repeat("a line of text\n", 100)
+	got, err := Text(doc, Limits{MaxBytes: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 8 {
+		t.Errorf("Text(%d bytes, MaxBytes 200) = %d parts, want 8", len(doc), len(got))
+	}
+	for _, p := range got {
+		if len(p) > 200 {
+			t.Errorf("part has %d bytes", len(p))
+		}
+	}
+}
+
+func TestTextCannotSplit(t *testing.T) {
+	_, err := Text("short\n"+strings.Repeat("word ", 20)+"\nshort\n", Limits{MaxWords: 10})
+	if !errors.Is(err, ErrCannotSplit) {
+		t.Fatalf("Text with an oversized line: error = %v, want ErrCannotSplit", err)
+	}
+	if !strings.Contains(err.Error(), "20 words (limit 10)") {
+		t.Errorf("error %q does not say how large the line is", err)
+	}
+}
+
+func TestTextLarge(t *testing.T) {
+	// About 540,000 words in 27,000 paragraphs and no headings, like a
+	// long transcript.
+	doc := strings.Repeat(strings.Repeat("lorem ipsum dolor sit amet ", 4)+"\n\n", 27_000)
+
+	start := time.Now()
+	got, err := Text(doc, DefaultLimits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Errorf("Text took %v on %d bytes", elapsed, len(doc))
+	}
+	if len(got) != 2 {
+		t.Errorf("Text(%d words) = %d parts, want 2", Words(doc), len(got))
+	}
+	if strings.Join(got, "") != doc {
+		t.Error("parts do not add up to the input")
+	}
+	for _, p := range got {
+		if !DefaultLimits.FitsText(p) {
+			t.Errorf("part has %s", DefaultLimits.Describe(p))
+		}
+	}
+}
+
+func TestTitles(t *testing.T) {
+	if got := Titles("notes.md", 1); !reflect.DeepEqual(got, []string{"notes.md"}) {
+		t.Errorf("Titles(1) = %q", got)
+	}
+	want := []string{"Book (part 1/3)", "Book (part 2/3)", "Book (part 3/3)"}
+	if got := Titles("Book", 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("Titles(3) = %q, want %q", got, want)
+	}
+}
+
+func TestPageRanges(t *testing.T) {
+	tests := []struct {
+		pages     int
+		size, max int64
+		want      []PageRange
+		wantErr   bool
+	}{
+		{10, 50, 100, []PageRange{{1, 10}}, false},
+		{10, 250, 100, []PageRange{{1, 4}, {5, 7}, {8, 10}}, false},
+		// 95 bytes is under the limit, but not with the headroom.
+		{7, 195, 100, []PageRange{{1, 3}, {4, 5}, {6, 7}}, false},
+		{2, 1000, 100, nil, true},
+		{0, 10, 100, nil, true},
+	}
+	for _, tt := range tests {
+		got, err := PageRanges(tt.pages, tt.size, tt.max)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("PageRanges(%d, %d, %d) error = %v", tt.pages, tt.size, tt.max, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("PageRanges(%d, %d, %d) = %v, want %v", tt.pages, tt.size, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestIsPDF(t *testing.T) {
+	if !IsPDF([]byte("%PDF-1.7\n...")) || IsPDF([]byte("plain text")) {
+		t.Error("IsPDF misclassified its input")
+	}
+}
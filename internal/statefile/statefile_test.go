@@ -0,0 +1,45 @@
+package statefile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testState struct {
+	ID    string            `json:"id"`
+	Files map[string]string `json:"files"`
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.json")
+
+	var st testState
+	if found, err := Load(path, &st); err != nil || found {
+		t.Fatalf("Load of a missing file = %v, %v; want false, nil", found, err)
+	}
+
+	want := testState{ID: "nb1", Files: map[string]string{"a.md": "src1"}}
+	if err := Save(path, want); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := Load(path, &st); err != nil || !found {
+		t.Fatalf("Load = %v, %v", found, err)
+	}
+	if !reflect.DeepEqual(st, want) {
+		t.Errorf("loaded %+v, want %+v", st, want)
+	}
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("directory holds %v, %v; want only the state file", entries, err)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, &st); err == nil || !strings.Contains(err.Error(), "parse state") {
+		t.Errorf("Load of a corrupt file: %v", err)
+	}
+}
@@ -0,0 +1,188 @@
+// Package watch keeps a NotebookLM notebook in sync with a local directory.
+package watch
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Op describes what happened to a path.
+type Op int
+
+const (
+	Create Op = 1 << iota
+	Write
+	Remove
+)
+
+func (op Op) String() string {
+	switch op {
+	case Create:
+		return "create"
+	case Write:
+		return "write"
+	case Remove:
+		return "remove"
+	}
+	return "unknown"
+}
+
+// Event is a change to a file or directory below the watched directory.
+type Event struct {
+	Path string
+	Op   Op
+}
+
+// Notifier reports filesystem changes below a directory.
+type Notifier interface {
+	Events() <-chan Event
+	Errors() <-chan error
+	Close() error
+}
+
+// NewNotifier returns the best notifier for this platform: inotify on
+// Linux, polling elsewhere. It falls back to polling if native
+// notifications are unavailable.
+func NewNotifier(dir string) (Notifier, error) {
+	n, err := newNativeNotifier(dir)
+	if err == nil {
+		return n, nil
+	}
+	return NewPoller(dir, DefaultPollInterval)
+}
+
+// DefaultPollInterval is how often a Poller rescans its directory.
+const DefaultPollInterval = 2 * time.Second
+
+// Poller is a Notifier that detects changes by rescanning a directory.
+type Poller struct {
+	dir      string
+	interval time.Duration
+	events   chan Event
+	errors   chan error
+	done     chan struct{}
+	prev     map[string]fileStamp
+}
+
+type fileStamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// NewPoller returns a Notifier that rescans dir every interval.
+func NewPoller(dir string, interval time.Duration) (*Poller, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	prev, err := scanStamps(dir)
+	if err != nil {
+		return nil, err
+	}
+	p := &Poller{
+		dir:      dir,
+		interval: interval,
+		events:   make(chan Event),
+		errors:   make(chan error),
+		done:     make(chan struct{}),
+		prev:     prev,
+	}
+	go p.loop()
+	return p, nil
+}
+
+func (p *Poller) Events() <-chan Event { return p.events }
+func (p *Poller) Errors() <-chan error { return p.errors }
+
+// Close stops the poller.
+func (p *Poller) Close() error {
+	select {
+	case <-p.done:
+	default:
+		close(p.done)
+	}
+	return nil
+}
+
+func (p *Poller) loop() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+		cur, err := scanStamps(p.dir)
+		if err != nil {
+			if !p.send(nil, err) {
+				return
+			}
+			continue
+		}
+		for path, st := range cur {
+			old, ok := p.prev[path]
+			switch {
+			case !ok:
+				if !p.send(&Event{Path: path, Op: Create}, nil) {
+					return
+				}
+			case old != st:
+				if !p.send(&Event{Path: path, Op: Write}, nil) {
+					return
+				}
+			}
+		}
+		for path := range p.prev {
+			if _, ok := cur[path]; !ok {
+				if !p.send(&Event{Path: path, Op: Remove}, nil) {
+					return
+				}
+			}
+		}
+		p.prev = cur
+	}
+}
+
+// send delivers an event or error, reporting false once the poller is closed.
+func (p *Poller) send(ev *Event, err error) bool {
+	if ev != nil {
+		select {
+		case p.events <- *ev:
+			return true
+		case <-p.done:
+			return false
+		}
+	}
+	select {
+	case p.errors <- err:
+		return true
+	case <-p.done:
+		return false
+	}
+}
+
+// scanStamps records the size and modification time of every regular file
+// below dir.
+func scanStamps(dir string) (map[string]fileStamp, error) {
+	stamps := make(map[string]fileStamp)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path != dir {
+				return nil
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		stamps[path] = fileStamp{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+	return stamps, err
+}
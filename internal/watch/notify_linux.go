@@ -0,0 +1,176 @@
+//go:build linux
+
+package watch
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_MODIFY |
+	unix.IN_DELETE | unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_DELETE_SELF
+
+// inotifyNotifier watches a directory tree with inotify. Subdirectories
+// are watched as they appear.
+type inotifyNotifier struct {
+	file   *os.File
+	fd     int
+	events chan Event
+	errors chan error
+	done   chan struct{}
+
+	mu    sync.Mutex
+	paths map[int]string // watch descriptor -> directory
+}
+
+func newNativeNotifier(dir string) (Notifier, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify init: %w", err)
+	}
+	n := &inotifyNotifier{
+		// A non-blocking descriptor lets Close interrupt a pending read.
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		fd:     fd,
+		events: make(chan Event),
+		errors: make(chan error),
+		done:   make(chan struct{}),
+		paths:  make(map[int]string),
+	}
+	if err := n.addTree(dir, nil); err != nil {
+		_ = n.file.Close()
+		return nil, err
+	}
+	go n.loop()
+	return n, nil
+}
+
+func (n *inotifyNotifier) Events() <-chan Event { return n.events }
+func (n *inotifyNotifier) Errors() <-chan error { return n.errors }
+
+// Close stops the notifier and releases the inotify descriptor.
+func (n *inotifyNotifier) Close() error {
+	select {
+	case <-n.done:
+		return nil
+	default:
+		close(n.done)
+	}
+	return n.file.Close()
+}
+
+// addTree watches dir and every directory below it. Files found along the
+// way are appended to found so callers can report directories that were
+// moved in whole.
+func (n *inotifyNotifier) addTree(dir string, found *[]string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path != dir {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			if found != nil && d.Type().IsRegular() {
+				*found = append(*found, path)
+			}
+			return nil
+		}
+		wd, err := unix.InotifyAddWatch(n.fd, path, inotifyMask)
+		if err != nil {
+			return fmt.Errorf("watch %s: %w", path, err)
+		}
+		n.mu.Lock()
+		n.paths[wd] = path
+		n.mu.Unlock()
+		return nil
+	})
+}
+
+func (n *inotifyNotifier) loop() {
+	buf := make([]byte, 64*(unix.SizeofInotifyEvent+unix.NAME_MAX+1))
+	for {
+		nr, err := n.file.Read(buf)
+		if err != nil {
+			if errors.Is(err, os.ErrClosed) {
+				return
+			}
+			if !n.send(nil, fmt.Errorf("read inotify events: %w", err)) {
+				return
+			}
+			continue
+		}
+		for off := 0; off+unix.SizeofInotifyEvent <= nr; {
+			raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+			nameBytes := buf[off+unix.SizeofInotifyEvent : off+unix.SizeofInotifyEvent+int(raw.Len)]
+			off += unix.SizeofInotifyEvent + int(raw.Len)
+			name := string(bytes.TrimRight(nameBytes, "\x00"))
+			for _, ev := range n.translate(int(raw.Wd), raw.Mask, name) {
+				if !n.send(&ev, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// translate turns one inotify record into zero or more events.
+func (n *inotifyNotifier) translate(wd int, mask uint32, name string) []Event {
+	n.mu.Lock()
+	dir, ok := n.paths[wd]
+	if mask&(unix.IN_DELETE_SELF|unix.IN_IGNORED) != 0 {
+		delete(n.paths, wd)
+	}
+	n.mu.Unlock()
+	if !ok || name == "" {
+		return nil
+	}
+	path := filepath.Join(dir, name)
+
+	switch {
+	case mask&unix.IN_ISDIR != 0 && mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		// Watch the new directory and report anything already inside it.
+		var found []string
+		if err := n.addTree(path, &found); err != nil {
+			_ = n.send(nil, err)
+		}
+		events := []Event{{Path: path, Op: Create}}
+		for _, f := range found {
+			events = append(events, Event{Path: f, Op: Create})
+		}
+		return events
+	case mask&(unix.IN_DELETE|unix.IN_MOVED_FROM) != 0:
+		return []Event{{Path: path, Op: Remove}}
+	case mask&(unix.IN_CREATE|unix.IN_MOVED_TO) != 0:
+		return []Event{{Path: path, Op: Create}}
+	case mask&(unix.IN_MODIFY|unix.IN_CLOSE_WRITE) != 0:
+		return []Event{{Path: path, Op: Write}}
+	}
+	return nil
+}
+
+// send delivers an event or error, reporting false once the notifier is closed.
+func (n *inotifyNotifier) send(ev *Event, err error) bool {
+	if ev != nil {
+		select {
+		case n.events <- *ev:
+			return true
+		case <-n.done:
+			return false
+		}
+	}
+	select {
+	case n.errors <- err:
+		return true
+	case <-n.done:
+		return false
+	}
+}
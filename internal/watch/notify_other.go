@@ -0,0 +1,11 @@
+//go:build !linux
+
+package watch
+
+import "errors"
+
+// newNativeNotifier reports that native notifications are unavailable so
+// NewNotifier falls back to polling.
+func newNativeNotifier(dir string) (Notifier, error) {
+	return nil, errors.New("native file notifications not supported on this platform")
+}
@@ -0,0 +1,63 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// waitFor reads events from n until one for path with op arrives.
+func waitFor(t *testing.T, n Notifier, path string, op Op) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-n.Events():
+			if ev.Path == path && ev.Op == op {
+				return
+			}
+		case err := <-n.Errors():
+			t.Fatalf("notifier error: %v", err)
+		case <-timeout:
+			t.Fatalf("timed out waiting for %v %s", op, path)
+		}
+	}
+}
+
+func testNotifier(t *testing.T, n Notifier, dir string) {
+	t.Helper()
+	a := filepath.Join(dir, "a.md")
+	writeFile(t, a, "a")
+	waitFor(t, n, a, Create)
+
+	// Files in new subdirectories are picked up too.
+	b := filepath.Join(dir, "sub", "b.md")
+	writeFile(t, b, "b")
+	waitFor(t, n, b, Create)
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, n, a, Remove)
+}
+
+func TestNotifier(t *testing.T) {
+	dir := t.TempDir()
+	n, err := NewNotifier(dir)
+	if err != nil {
+		t.Fatalf("NewNotifier: %v", err)
+	}
+	defer n.Close()
+	testNotifier(t, n, dir)
+}
+
+func TestPoller(t *testing.T) {
+	dir := t.TempDir()
+	n, err := NewPoller(dir, 10*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewPoller: %v", err)
+	}
+	defer n.Close()
+	testNotifier(t, n, dir)
+}
@@ -0,0 +1,53 @@
+package watch
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/tmc/nlm/internal/statefile"
+)
+
+// DefaultStateFile is the name of the state file kept in the watched
+// directory when no other location is given.
+const DefaultStateFile = ".nlm-watch.json"
+
+// State maps local files to the notebook sources they were uploaded as.
+// It is saved after every sync so a restarted watcher only uploads what
+// changed while it was not running.
+type State struct {
+	NotebookID string               `json:"notebook_id"`
+	Files      map[string]FileState `json:"files"` // keyed by slash-separated path relative to the directory
+
+	// Stale lists sources replaced by a newer upload whose deletion has
+	// not yet succeeded. They are retried on every sync.
+	Stale []string `json:"stale,omitempty"`
+}
+
+// FileState records the source created from one file.
+type FileState struct {
+	SourceID   string    `json:"source_id"`
+	SHA256     string    `json:"sha256"`
+	Size       int64     `json:"size"`
+	UploadedAt time.Time `json:"uploaded_at"`
+}
+
+// LoadState reads the state file at path. A missing file yields an empty
+// state for notebookID; a file that belongs to another notebook is an error.
+func LoadState(path, notebookID string) (*State, error) {
+	st := &State{NotebookID: notebookID, Files: make(map[string]FileState)}
+	if _, err := statefile.Load(path, st); err != nil {
+		return nil, err
+	}
+	if st.NotebookID != notebookID {
+		return nil, fmt.Errorf("state file %s tracks notebook %s, not %s", path, st.NotebookID, notebookID)
+	}
+	if st.Files == nil {
+		st.Files = make(map[string]FileState)
+	}
+	return st, nil
+}
+
+// Save writes the state to path, replacing it atomically.
+func (st *State) Save(path string) error {
+	return statefile.Save(path, st)
+}
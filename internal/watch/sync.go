@@ -0,0 +1,343 @@
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDebounce is how long a Syncer waits for a burst of changes to
+// settle before uploading.
+const DefaultDebounce = 2 * time.Second
+
+// Client is the subset of the NotebookLM API a Syncer needs.
+// *api.Client satisfies it.
+type Client interface {
+	AddSourceFromFile(projectID, filepath string, contentType ...string) (string, error)
+	DeleteSources(projectID string, sourceIDs []string) error
+}
+
+// Action is what a Syncer did to the notebook for one file.
+type Action string
+
+const (
+	Added   Action = "added"
+	Updated Action = "updated"
+	Removed Action = "removed"
+)
+
+// Change describes one applied change.
+type Change struct {
+	Path     string // relative to the watched directory
+	Action   Action
+	SourceID string
+}
+
+// Syncer mirrors the files in a directory as sources in a notebook.
+type Syncer struct {
+	Client     Client
+	NotebookID string
+	Dir        string
+	StatePath  string
+	Debounce   time.Duration
+	Logger     *slog.Logger
+
+	// OnChange, if set, is called after each change is applied.
+	OnChange func(Change)
+
+	state *State
+}
+
+// NewSyncer returns a Syncer for dir, loading any saved state. An empty
+// statePath uses DefaultStateFile inside dir.
+func NewSyncer(c Client, notebookID, dir, statePath string) (*Syncer, error) {
+	if statePath == "" {
+		statePath = filepath.Join(dir, DefaultStateFile)
+	}
+	st, err := LoadState(statePath, notebookID)
+	if err != nil {
+		return nil, err
+	}
+	return &Syncer{
+		Client:     c,
+		NotebookID: notebookID,
+		Dir:        dir,
+		StatePath:  statePath,
+		Debounce:   DefaultDebounce,
+		state:      st,
+	}, nil
+}
+
+// State returns the current path to source mapping.
+func (s *Syncer) State() *State { return s.state }
+
+func (s *Syncer) log() *slog.Logger {
+	if s.Logger == nil {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+	return s.Logger
+}
+
+// Scan reconciles the whole directory with the saved state: new and
+// changed files are uploaded and sources for vanished files are removed.
+func (s *Syncer) Scan() error {
+	var paths []string
+	err := filepath.WalkDir(s.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != s.Dir && s.ignored(path) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type().IsRegular() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan %s: %w", s.Dir, err)
+	}
+	for rel := range s.state.Files {
+		paths = append(paths, filepath.Join(s.Dir, filepath.FromSlash(rel)))
+	}
+	return s.syncPaths(dedupe(paths))
+}
+
+// Run applies changes reported by n until ctx is done or n is closed.
+// Changes are debounced so an editor's save burst causes one upload.
+func (s *Syncer) Run(ctx context.Context, n Notifier) error {
+	pending := make(map[string]bool)
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-n.Events():
+			if !ok {
+				return nil
+			}
+			if s.ignored(ev.Path) {
+				continue
+			}
+			s.log().Debug("watch event", "path", ev.Path, "op", ev.Op)
+			pending[ev.Path] = true
+			timer.Reset(s.Debounce)
+		case err, ok := <-n.Errors():
+			if !ok {
+				return nil
+			}
+			s.log().Warn("watch error", "error", err)
+		case <-timer.C:
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			pending = make(map[string]bool)
+			if err := s.syncPaths(dedupe(paths)); err != nil {
+				s.log().Error("sync failed", "error", err)
+			}
+		}
+	}
+}
+
+// syncPaths syncs each path and saves the state once at the end. Failures
+// for individual files are logged and reported together.
+func (s *Syncer) syncPaths(paths []string) error {
+	var failed []string
+	for _, path := range paths {
+		if err := s.SyncPath(path); err != nil {
+			s.log().Error("sync path", "path", path, "error", err)
+			failed = append(failed, path)
+		}
+	}
+	// Retry deletions left over from earlier syncs even if no file was
+	// updated this time.
+	staleErr := s.deleteStale()
+	if err := s.state.Save(s.StatePath); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("sync %d of %d paths failed", len(failed), len(paths))
+	}
+	return staleErr
+}
+
+// SyncPath brings the notebook in line with the current contents of path.
+// A file is uploaded if it is new or its contents changed; a missing path
+// removes the sources of every tracked file at or below it; a directory is
+// synced file by file. The state is updated but not saved.
+func (s *Syncer) SyncPath(path string) error {
+	rel, err := s.rel(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		return s.removeTracked(rel)
+	case err != nil:
+		return err
+	case info.IsDir():
+		var files []string
+		err := filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if p != path && s.ignored(p) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := s.SyncPath(f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case !info.Mode().IsRegular():
+		return nil
+	}
+
+	sum, err := fileSHA256(path)
+	if err != nil {
+		return err
+	}
+	old, tracked := s.state.Files[rel]
+	if tracked && old.SHA256 == sum {
+		return nil
+	}
+
+	// Add the new version before deleting the old one so a failed upload
+	// never leaves the notebook without the file.
+	id, err := s.Client.AddSourceFromFile(s.NotebookID, path)
+	if err != nil {
+		return fmt.Errorf("upload %s: %w", rel, err)
+	}
+	s.state.Files[rel] = FileState{SourceID: id, SHA256: sum, Size: info.Size(), UploadedAt: time.Now().UTC()}
+	action := Added
+	if tracked {
+		action = Updated
+		s.state.Stale = append(s.state.Stale, old.SourceID)
+	}
+	s.changed(Change{Path: rel, Action: action, SourceID: id})
+	return s.deleteStale()
+}
+
+// deleteStale deletes the sources replaced by newer uploads. If that
+// fails they stay in the state for the next sync to retry.
+func (s *Syncer) deleteStale() error {
+	if len(s.state.Stale) == 0 {
+		return nil
+	}
+	if err := s.Client.DeleteSources(s.NotebookID, s.state.Stale); err != nil {
+		return fmt.Errorf("delete %d replaced sources: %w", len(s.state.Stale), err)
+	}
+	s.state.Stale = nil
+	return nil
+}
+
+// removeTracked deletes the sources for rel and any tracked file below it.
+func (s *Syncer) removeTracked(rel string) error {
+	var paths, ids []string
+	for p, fst := range s.state.Files {
+		if p == rel || strings.HasPrefix(p, rel+"/") {
+			paths = append(paths, p)
+			ids = append(ids, fst.SourceID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := s.Client.DeleteSources(s.NotebookID, ids); err != nil {
+		return fmt.Errorf("delete sources for %s: %w", rel, err)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		id := s.state.Files[p].SourceID
+		delete(s.state.Files, p)
+		s.changed(Change{Path: p, Action: Removed, SourceID: id})
+	}
+	return nil
+}
+
+func (s *Syncer) changed(c Change) {
+	s.log().Info("synced", "path", c.Path, "action", c.Action, "source", c.SourceID)
+	if s.OnChange != nil {
+		s.OnChange(c)
+	}
+}
+
+// rel returns path relative to the watched directory in slash form.
+func (s *Syncer) rel(path string) (string, error) {
+	rel, err := filepath.Rel(s.Dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside %s", path, s.Dir)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// ignored reports whether path should never be synced: the state file,
+// hidden files and directories, and editor backup files.
+func (s *Syncer) ignored(path string) bool {
+	if filepath.Clean(path) == filepath.Clean(s.StatePath) {
+		return true
+	}
+	rel, err := s.rel(path)
+	if err != nil {
+		return true
+	}
+	for _, part := range strings.Split(rel, "/") {
+		if strings.HasPrefix(part, ".") && part != "." {
+			return true
+		}
+	}
+	return strings.HasSuffix(rel, "~")
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hash %s: %w", path, err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func dedupe(paths []string) []string {
+	sort.Strings(paths)
+	out := paths[:0]
+	for i, p := range paths {
+		if i == 0 || p != paths[i-1] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,277 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// fakeClient records uploads and deletions.
+type fakeClient struct {
+	next    int
+	added   []string // relative to dir
+	deleted []string
+	dir     string
+	failAdd bool
+	failDel bool
+}
+
+func (c *fakeClient) AddSourceFromFile(projectID, path string, contentType ...string) (string, error) {
+	if c.failAdd {
+		return "", fmt.Errorf("upload failed")
+	}
+	c.next++
+	rel, _ := filepath.Rel(c.dir, path)
+	c.added = append(c.added, filepath.ToSlash(rel))
+	return fmt.Sprintf("src%d", c.next), nil
+}
+
+func (c *fakeClient) DeleteSources(projectID string, ids []string) error {
+	if c.failDel {
+		return fmt.Errorf("delete failed")
+	}
+	c.deleted = append(c.deleted, ids...)
+	sort.Strings(c.deleted)
+	return nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestSyncer(t *testing.T) (*Syncer, *fakeClient, string) {
+	t.Helper()
+	dir := t.TempDir()
+	c := &fakeClient{dir: dir}
+	s, err := NewSyncer(c, "nb1", dir, "")
+	if err != nil {
+		t.Fatalf("NewSyncer: %v", err)
+	}
+	return s, c, dir
+}
+
+func TestSyncerScan(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	writeFile(t, filepath.Join(dir, "a.md"), "a")
+	writeFile(t, filepath.Join(dir, "sub", "b.md"), "b")
+	writeFile(t, filepath.Join(dir, ".hidden", "c.md"), "c")
+	writeFile(t, filepath.Join(dir, "a.md~"), "backup")
+
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if want := []string{"a.md", "sub/b.md"}; !reflect.DeepEqual(c.added, want) {
+		t.Errorf("added = %v, want %v", c.added, want)
+	}
+
+	// A second scan with nothing changed uploads nothing.
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if len(c.added) != 2 {
+		t.Errorf("rescan uploaded again: %v", c.added)
+	}
+}
+
+func TestSyncerModifyAndDelete(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	a := filepath.Join(dir, "a.md")
+	writeFile(t, a, "v1")
+	if err := s.SyncPath(a); err != nil {
+		t.Fatalf("SyncPath: %v", err)
+	}
+
+	var changes []Change
+	s.OnChange = func(ch Change) { changes = append(changes, ch) }
+
+	writeFile(t, a, "v2")
+	if err := s.SyncPath(a); err != nil {
+		t.Fatalf("SyncPath: %v", err)
+	}
+	if got := s.State().Files["a.md"].SourceID; got != "src2" {
+		t.Errorf("source id after update = %q, want src2", got)
+	}
+	if want := []string{"src1"}; !reflect.DeepEqual(c.deleted, want) {
+		t.Errorf("deleted = %v, want %v", c.deleted, want)
+	}
+
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SyncPath(a); err != nil {
+		t.Fatalf("SyncPath: %v", err)
+	}
+	if _, ok := s.State().Files["a.md"]; ok {
+		t.Error("a.md still tracked after delete")
+	}
+	want := []Change{
+		{Path: "a.md", Action: Updated, SourceID: "src2"},
+		{Path: "a.md", Action: Removed, SourceID: "src2"},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+}
+
+func TestSyncerRemovedDirectory(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	writeFile(t, filepath.Join(dir, "sub", "a.md"), "a")
+	writeFile(t, filepath.Join(dir, "sub", "b.md"), "b")
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if err := os.RemoveAll(filepath.Join(dir, "sub")); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.SyncPath(filepath.Join(dir, "sub")); err != nil {
+		t.Fatalf("SyncPath: %v", err)
+	}
+	if want := []string{"src1", "src2"}; !reflect.DeepEqual(c.deleted, want) {
+		t.Errorf("deleted = %v, want %v", c.deleted, want)
+	}
+}
+
+func TestSyncerFailedUploadKeepsOldSource(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	a := filepath.Join(dir, "a.md")
+	writeFile(t, a, "v1")
+	if err := s.SyncPath(a); err != nil {
+		t.Fatalf("SyncPath: %v", err)
+	}
+	c.failAdd = true
+	writeFile(t, a, "v2")
+	if err := s.SyncPath(a); err == nil {
+		t.Fatal("SyncPath succeeded, want upload error")
+	}
+	if len(c.deleted) != 0 {
+		t.Errorf("deleted = %v after failed upload, want none", c.deleted)
+	}
+	if got := s.State().Files["a.md"].SourceID; got != "src1" {
+		t.Errorf("source id = %q, want src1", got)
+	}
+}
+
+func TestSyncerFailedDeleteKeepsStaleSource(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	a := filepath.Join(dir, "a.md")
+	writeFile(t, a, "v1")
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	c.failDel = true
+	writeFile(t, a, "v2")
+	if err := s.Scan(); err == nil {
+		t.Fatal("Scan succeeded, want delete error")
+	}
+	if got := s.State().Files["a.md"].SourceID; got != "src2" {
+		t.Errorf("source id = %q, want src2", got)
+	}
+	if want := []string{"src1"}; !reflect.DeepEqual(s.State().Stale, want) {
+		t.Errorf("stale = %v, want %v", s.State().Stale, want)
+	}
+
+	// A restarted watcher retries the deletion even though nothing changed.
+	c.failDel = false
+	s2, err := NewSyncer(c, "nb1", dir, "")
+	if err != nil {
+		t.Fatalf("NewSyncer: %v", err)
+	}
+	if err := s2.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if want := []string{"src1"}; !reflect.DeepEqual(c.deleted, want) {
+		t.Errorf("deleted = %v, want %v", c.deleted, want)
+	}
+	if len(s2.State().Stale) != 0 {
+		t.Errorf("stale = %v after retry, want none", s2.State().Stale)
+	}
+	if want := []string{"a.md", "a.md"}; !reflect.DeepEqual(c.added, want) {
+		t.Errorf("added = %v, want %v", c.added, want)
+	}
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	writeFile(t, filepath.Join(dir, "a.md"), "a")
+	if err := s.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+
+	// While "stopped", a.md is deleted and b.md created.
+	if err := os.Remove(filepath.Join(dir, "a.md")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "b.md"), "b")
+
+	s2, err := NewSyncer(c, "nb1", dir, "")
+	if err != nil {
+		t.Fatalf("NewSyncer: %v", err)
+	}
+	if got := s2.State().Files["a.md"].SourceID; got != "src1" {
+		t.Fatalf("restored source id = %q, want src1", got)
+	}
+	if err := s2.Scan(); err != nil {
+		t.Fatalf("Scan: %v", err)
+	}
+	if want := []string{"src1"}; !reflect.DeepEqual(c.deleted, want) {
+		t.Errorf("deleted = %v, want %v", c.deleted, want)
+	}
+	if want := []string{"a.md", "b.md"}; !reflect.DeepEqual(c.added, want) {
+		t.Errorf("added = %v, want %v", c.added, want)
+	}
+
+	if _, err := NewSyncer(c, "other", dir, ""); err == nil {
+		t.Error("NewSyncer with another notebook succeeded, want error")
+	}
+}
+
+// chanNotifier is a Notifier driven by the test.
+type chanNotifier struct {
+	events chan Event
+	errors chan error
+}
+
+func (n *chanNotifier) Events() <-chan Event { return n.events }
+func (n *chanNotifier) Errors() <-chan error { return n.errors }
+func (n *chanNotifier) Close() error         { close(n.events); return nil }
+
+func TestSyncerRunDebounces(t *testing.T) {
+	s, c, dir := newTestSyncer(t)
+	s.Debounce = 20 * time.Millisecond
+	done := make(chan Change, 10)
+	s.OnChange = func(ch Change) { done <- ch }
+
+	n := &chanNotifier{events: make(chan Event), errors: make(chan error)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = s.Run(ctx, n) }()
+
+	a := filepath.Join(dir, "a.md")
+	writeFile(t, a, "a")
+	for range 5 {
+		n.events <- Event{Path: a, Op: Write}
+	}
+	select {
+	case ch := <-done:
+		if ch.Action != Added {
+			t.Errorf("action = %v, want added", ch.Action)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for sync")
+	}
+	cancel()
+	if len(c.added) != 1 {
+		t.Errorf("uploads = %v, want one", c.added)
+	}
+}
@@ -0,0 +1,279 @@
+// Package youtube expands YouTube playlist and channel URLs into the
+// videos they list, by reading the public page YouTube serves to browsers.
+// No API key is needed, but only the videos in the page's initial data are
+// seen: about the first 100 of a playlist and the latest 30 or so of a
+// channel.
+package youtube
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+// Video is a video listed on a playlist or channel page.
+type Video struct {
+	ID    string
+	Title string
+	// Issue is set when the page already shows that NotebookLM will not be
+	// able to read the video, e.g. because it is private.
+	Issue pb.SourceIssue_Reason
+}
+
+// URL returns the video's watch URL.
+func (v Video) URL() string {
+	return "https://www.youtube.com/watch?v=" + v.ID
+}
+
+// IsCollection reports whether rawURL is a YouTube playlist or channel
+// rather than a single video.
+func IsCollection(rawURL string) bool {
+	_, ok := pageURL(rawURL)
+	return ok
+}
+
+// pageURL returns the page that lists the videos of a playlist or channel
+// URL: the playlist page, or the channel's videos tab.
+func pageURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", false
+	}
+	host := strings.TrimPrefix(strings.ToLower(u.Host), "www.")
+	if host != "youtube.com" && host != "m.youtube.com" && host != "music.youtube.com" {
+		return "", false
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	switch {
+	case parts[0] == "playlist" && u.Query().Get("list") != "":
+		return "https://www.youtube.com/playlist?list=" + url.QueryEscape(u.Query().Get("list")), true
+	case strings.HasPrefix(parts[0], "@") && len(parts[0]) > 1:
+		return "https://www.youtube.com/" + parts[0] + "/videos", true
+	case (parts[0] == "channel" || parts[0] == "c" || parts[0] == "user") && len(parts) > 1 && parts[1] != "":
+		return "https://www.youtube.com/" + parts[0] + "/" + parts[1] + "/videos", true
+	}
+	return "", false
+}
+
+// Lister lists the videos of playlists and channels.
+type Lister struct {
+	// Client fetches the pages; nil means http.DefaultClient.
+	Client *http.Client
+}
+
+// maxPageBytes bounds how much of a page is read.
+const maxPageBytes = 20 << 20
+
+// Videos returns the videos of the playlist or channel at rawURL, in page
+// order and without duplicates.
+func (l *Lister) Videos(ctx context.Context, rawURL string) ([]Video, error) {
+	page, ok := pageURL(rawURL)
+	if !ok {
+		return nil, fmt.Errorf("not a YouTube playlist or channel URL: %s", rawURL)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, page, nil)
+	if err != nil {
+		return nil, err
+	}
+	// Without these YouTube may serve a consent page instead of the list.
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	req.Header.Set("Cookie", "CONSENT=YES+1")
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", page, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch %s: %s", page, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageBytes))
+	if err != nil {
+		return nil, fmt.Errorf("fetch %s: %w", page, err)
+	}
+	videos, err := ParsePage(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", page, err)
+	}
+	return videos, nil
+}
+
+// ErrNoVideos is returned when a page lists no videos, for example because
+// the playlist is private or does not exist.
+var ErrNoVideos = errors.New("no videos found")
+
+var (
+	initialData = regexp.MustCompile(`(?:var ytInitialData|window\["ytInitialData"\])\s*=\s*`)
+	videoIDAttr = regexp.MustCompile(`"videoId":"([A-Za-z0-9_-]{11})"`)
+)
+
+// ParsePage returns the videos listed in a playlist or channel page. It
+// reads the ytInitialData object embedded in the page, and falls back to
+// scanning for video IDs if that object cannot be decoded.
+func ParsePage(body []byte) ([]Video, error) {
+	var videos []Video
+	if loc := initialData.FindIndex(body); loc != nil {
+		var data any
+		dec := json.NewDecoder(bytes.NewReader(body[loc[1]:]))
+		if err := dec.Decode(&data); err == nil {
+			videos = collect(data, nil, map[string]bool{})
+		}
+	}
+	if len(videos) == 0 {
+		seen := map[string]bool{}
+		for _, m := range videoIDAttr.FindAllSubmatch(body, -1) {
+			if id := string(m[1]); !seen[id] {
+				seen[id] = true
+				videos = append(videos, Video{ID: id})
+			}
+		}
+	}
+	if len(videos) == 0 {
+		return nil, ErrNoVideos
+	}
+	return videos, nil
+}
+
+// rendererKeys name the objects that describe one listed video.
+var rendererKeys = []string{"playlistVideoRenderer", "videoRenderer", "gridVideoRenderer", "richItemRenderer"}
+
+// collect walks the page data depth first and appends each video renderer
+// it finds.
+func collect(v any, videos []Video, seen map[string]bool) []Video {
+	switch v := v.(type) {
+	case map[string]any:
+		for _, key := range rendererKeys {
+			r, ok := v[key].(map[string]any)
+			if !ok {
+				continue
+			}
+			if key == "richItemRenderer" {
+				// Channel pages wrap a videoRenderer in each rich item.
+				return collect(r, videos, seen)
+			}
+			if vid, ok := videoFromRenderer(r); ok && !seen[vid.ID] {
+				seen[vid.ID] = true
+				videos = append(videos, vid)
+			}
+			return videos
+		}
+		// Visit keys in a fixed order so that the result is stable.
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			videos = collect(v[key], videos, seen)
+		}
+	case []any:
+		for _, child := range v {
+			videos = collect(child, videos, seen)
+		}
+	}
+	return videos
+}
+
+func videoFromRenderer(r map[string]any) (Video, bool) {
+	id, _ := r["videoId"].(string)
+	if id == "" {
+		return Video{}, false
+	}
+	v := Video{ID: id, Title: text(r["title"])}
+	switch v.Title {
+	case "[Private video]", "[Deleted video]":
+		v.Issue = pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE
+	}
+	if playable, ok := r["isPlayable"].(bool); ok && !playable && v.Issue == 0 {
+		v.Issue = pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC
+	}
+	for _, b := range badges(r) {
+		switch {
+		case strings.Contains(b, "MEMBERS_ONLY") || strings.EqualFold(b, "Members only"):
+			v.Issue = pb.SourceIssue_REASON_YOUTUBE_ERROR_MEMBERS_ONLY
+		case strings.EqualFold(b, "Unlisted"):
+			v.Issue = pb.SourceIssue_REASON_YOUTUBE_ERROR_UNLISTED
+		}
+	}
+	return v, true
+}
+
+// text returns the text of a YouTube text object, which has either a
+// simpleText or a list of runs.
+func text(v any) string {
+	m, ok := v.(map[string]any)
+	if !ok {
+		return ""
+	}
+	if s, ok := m["simpleText"].(string); ok {
+		return s
+	}
+	var b strings.Builder
+	runs, _ := m["runs"].([]any)
+	for _, r := range runs {
+		if rm, ok := r.(map[string]any); ok {
+			s, _ := rm["text"].(string)
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+// badges returns the styles and labels of a renderer's badges.
+func badges(r map[string]any) []string {
+	var out []string
+	list, _ := r["badges"].([]any)
+	for _, b := range list {
+		bm, _ := b.(map[string]any)
+		m, _ := bm["metadataBadgeRenderer"].(map[string]any)
+		for _, key := range []string{"style", "label"} {
+			if s, ok := m[key].(string); ok {
+				out = append(out, s)
+			}
+		}
+	}
+	return out
+}
+
+// ReasonText describes a YouTube source issue in a few words.
+func ReasonText(r pb.SourceIssue_Reason) string {
+	switch r {
+	case pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE:
+		return "private"
+	case pb.SourceIssue_REASON_YOUTUBE_ERROR_UNLISTED:
+		return "unlisted"
+	case pb.SourceIssue_REASON_YOUTUBE_ERROR_MEMBERS_ONLY:
+		return "members-only"
+	case pb.SourceIssue_REASON_YOUTUBE_ERROR_LOGIN_REQUIRED:
+		return "login required"
+	case pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC:
+		return "unavailable"
+	}
+	return strings.ToLower(strings.TrimPrefix(r.String(), "REASON_"))
+}
+
+// ReasonFromError guesses the issue behind an error adding a video, from
+// its message. It returns REASON_YOUTUBE_ERROR_GENERIC if nothing matches.
+func ReasonFromError(err error) pb.SourceIssue_Reason {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "members"):
+		return pb.SourceIssue_REASON_YOUTUBE_ERROR_MEMBERS_ONLY
+	case strings.Contains(msg, "unlisted"):
+		return pb.SourceIssue_REASON_YOUTUBE_ERROR_UNLISTED
+	case strings.Contains(msg, "private"):
+		return pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE
+	case strings.Contains(msg, "login") || strings.Contains(msg, "sign in") || strings.Contains(msg, "age-restricted"):
+		return pb.SourceIssue_REASON_YOUTUBE_ERROR_LOGIN_REQUIRED
+	}
+	return pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC
+}
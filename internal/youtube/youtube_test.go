@@ -0,0 +1,150 @@
+package youtube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+
+	pb "github.com/tmc/nlm/gen/notebooklm/v1alpha1"
+)
+
+const playlistPage = `<html><script>var ytInitialData = {"contents":{"playlistVideoListRenderer":{"contents":[
+	{"playlistVideoRenderer":{"videoId":"aaaaaaaaaaa","title":{"runs":[{"text":"First"}]},"isPlayable":true}},
+	{"playlistVideoRenderer":{"videoId":"bbbbbbbbbbb","title":{"simpleText":"[Private video]"},"isPlayable":false}},
+	{"playlistVideoRenderer":{"videoId":"ccccccccccc","title":{"runs":[{"text":"For "},{"text":"members"}]},
+		"badges":[{"metadataBadgeRenderer":{"style":"BADGE_STYLE_TYPE_MEMBERS_ONLY","label":"Members only"}}]}},
+	{"playlistVideoRenderer":{"videoId":"ddddddddddd","title":{"runs":[{"text":"Hidden"}]},
+		"badges":[{"metadataBadgeRenderer":{"label":"Unlisted"}}]}},
+	{"playlistVideoRenderer":{"videoId":"aaaaaaaaaaa","title":{"runs":[{"text":"First again"}]}}}
+]}}};</script></html>`
+
+const channelPage = `<html><script>var ytInitialData = {"tabs":[{"richGridRenderer":{"contents":[
+	{"richItemRenderer":{"content":{"videoRenderer":{"videoId":"eeeeeeeeeee","title":{"runs":[{"text":"Latest"}]}}}}},
+	{"richItemRenderer":{"content":{"videoRenderer":{"videoId":"fffffffffff","title":{"runs":[{"text":"Older"}]}}}}}
+]}}]};</script></html>`
+
+func TestIsCollection(t *testing.T) {
+	tests := []struct {
+		url  string
+		page string
+	}{
+		{"https://www.youtube.com/playlist?list=PL123", "https://www.youtube.com/playlist?list=PL123"},
+		{"https://m.youtube.com/playlist?list=PL123&si=x", "https://www.youtube.com/playlist?list=PL123"},
+		{"https://www.youtube.com/@golang", "https://www.youtube.com/@golang/videos"},
+		{"https://youtube.com/@golang/featured", "https://www.youtube.com/@golang/videos"},
+		{"https://www.youtube.com/channel/UC123", "https://www.youtube.com/channel/UC123/videos"},
+		{"https://www.youtube.com/c/golang", "https://www.youtube.com/c/golang/videos"},
+		{"https://www.youtube.com/watch?v=aaaaaaaaaaa&list=PL123", ""},
+		{"https://youtu.be/aaaaaaaaaaa", ""},
+		{"https://www.youtube.com/playlist", ""},
+		{"https://example.com/@golang", ""},
+	}
+	for _, tt := range tests {
+		page, ok := pageURL(tt.url)
+		if page != tt.page || ok != (tt.page != "") {
+			t.Errorf("pageURL(%q) = %q, %v; want %q", tt.url, page, ok, tt.page)
+		}
+		if IsCollection(tt.url) != ok {
+			t.Errorf("IsCollection(%q) = %v, want %v", tt.url, !ok, ok)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	got, err := ParsePage([]byte(playlistPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Video{
+		{ID: "aaaaaaaaaaa", Title: "First"},
+		{ID: "bbbbbbbbbbb", Title: "[Private video]", Issue: pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE},
+		{ID: "ccccccccccc", Title: "For members", Issue: pb.SourceIssue_REASON_YOUTUBE_ERROR_MEMBERS_ONLY},
+		{ID: "ddddddddddd", Title: "Hidden", Issue: pb.SourceIssue_REASON_YOUTUBE_ERROR_UNLISTED},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePage(playlist) = %+v\nwant %+v", got, want)
+	}
+
+	got, err = ParsePage([]byte(channelPage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Video{{ID: "eeeeeeeeeee", Title: "Latest"}, {ID: "fffffffffff", Title: "Older"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("ParsePage(channel) = %+v, want %+v", got, want)
+	}
+
+	// Without decodable initial data, video IDs are still found.
+	got, err = ParsePage([]byte(`<a data='{"videoId":"ggggggggggg"}'></a>{"videoId":"ggggggggggg"}`))
+	if err != nil || len(got) != 1 || got[0].ID != "ggggggggggg" {
+		t.Errorf("ParsePage(fallback) = %+v, %v", got, err)
+	}
+
+	if _, err := ParsePage([]byte(`<html>This playlist does not exist.</html>`)); !errors.Is(err, ErrNoVideos) {
+		t.Errorf("ParsePage(empty) error = %v, want ErrNoVideos", err)
+	}
+}
+
+// redirect sends every request to srv, keeping the path and query.
+type redirect struct{ srv *httptest.Server }
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, _ := url.Parse(r.srv.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestListerVideos(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/playlist", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list") != "PL123" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, playlistPage)
+	})
+	mux.HandleFunc("/@golang/videos", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, channelPage)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	l := &Lister{Client: &http.Client{Transport: redirect{srv}}}
+
+	videos, err := l.Videos(context.Background(), "https://www.youtube.com/playlist?list=PL123")
+	if err != nil || len(videos) != 4 {
+		t.Errorf("Videos(playlist) = %d videos, %v; want 4", len(videos), err)
+	}
+	videos, err = l.Videos(context.Background(), "https://www.youtube.com/@golang")
+	if err != nil || len(videos) != 2 {
+		t.Errorf("Videos(channel) = %d videos, %v; want 2", len(videos), err)
+	}
+	if _, err := l.Videos(context.Background(), "https://www.youtube.com/playlist?list=missing"); err == nil {
+		t.Error("Videos(missing playlist) succeeded")
+	}
+	if _, err := l.Videos(context.Background(), "https://youtu.be/aaaaaaaaaaa"); err == nil {
+		t.Error("Videos(single video) succeeded")
+	}
+}
+
+func TestReasonFromError(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want pb.SourceIssue_Reason
+	}{
+		{"add YouTube source: video is private", pb.SourceIssue_REASON_YOUTUBE_ERROR_PRIVATE},
+		{"This video is available to members only", pb.SourceIssue_REASON_YOUTUBE_ERROR_MEMBERS_ONLY},
+		{"Sign in to confirm your age", pb.SourceIssue_REASON_YOUTUBE_ERROR_LOGIN_REQUIRED},
+		{"unlisted video", pb.SourceIssue_REASON_YOUTUBE_ERROR_UNLISTED},
+		{"bad response page", pb.SourceIssue_REASON_YOUTUBE_ERROR_GENERIC},
+	}
+	for _, tt := range tests {
+		if got := ReasonFromError(errors.New(tt.msg)); got != tt.want {
+			t.Errorf("ReasonFromError(%q) = %v, want %v", tt.msg, got, tt.want)
+		}
+	}
+}